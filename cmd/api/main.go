@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/GRACENOBLE/tundra/internal/server"
+)
+
+// main starts the REST API. internal/grpcserver.NewGRPCServer exists but is intentionally not
+// wired up here yet: it has no services registered on it, since proto/tundra/v1 has no generated
+// Go code in this checkout (`make proto` has never been run). Starting a listener and logging
+// "gRPC server listening" for a server that answers every call with "unimplemented" would be
+// worse than not advertising the endpoint at all - once `make proto` is wired into the build and
+// the generated ordersv1/productsv1 packages exist to register against NewGRPCServer, start it
+// here the same way httpServer is started below.
+func main() {
+	_, httpServer := server.NewServer()
+
+	fmt.Printf("HTTP server listening on %s\n", httpServer.Addr)
+	if err := httpServer.ListenAndServe(); err != nil {
+		log.Fatalf("HTTP server stopped: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/GRACENOBLE/tundra/internal/database"
+)
+
+func main() {
+	var action string
+	var file string
+	var include string
+	var exclude string
+	var batchSize int
+	var gzip bool
+	var truncate bool
+	flag.StringVar(&action, "action", "backup", "Action: backup, restore")
+	flag.StringVar(&file, "file", "", "Path to read/write the dump (defaults to stdout/stdin)")
+	flag.StringVar(&include, "include", "", "Comma-separated table names to include (backup only)")
+	flag.StringVar(&exclude, "exclude", "", "Comma-separated table names to exclude (backup only)")
+	flag.IntVar(&batchSize, "batch-size", 0, "FindInBatches page size (backup only, defaults to 500)")
+	flag.BoolVar(&gzip, "gzip", false, "Wrap the dump in gzip")
+	flag.BoolVar(&truncate, "truncate", false, "Truncate every registered table before restoring (restore only)")
+	flag.Parse()
+
+	db := database.New()
+	defer db.Close()
+
+	switch action {
+	case "backup":
+		w := os.Stdout
+		if file != "" {
+			f, err := os.Create(file)
+			if err != nil {
+				log.Fatalf("Failed to create %s: %v", file, err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		opts := database.BackupOptions{
+			Include:   splitCSV(include),
+			Exclude:   splitCSV(exclude),
+			BatchSize: batchSize,
+			Gzip:      gzip,
+		}
+		if err := db.Backup(context.Background(), w, opts); err != nil {
+			log.Fatalf("Failed to back up database: %v", err)
+		}
+		log.Println("✓ Backup completed successfully!")
+
+	case "restore":
+		r := os.Stdin
+		if file != "" {
+			f, err := os.Open(file)
+			if err != nil {
+				log.Fatalf("Failed to open %s: %v", file, err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		opts := database.RestoreOptions{
+			Truncate: truncate,
+			Gzip:     gzip,
+		}
+		if err := db.Restore(context.Background(), r, opts); err != nil {
+			log.Fatalf("Failed to restore database: %v", err)
+		}
+		log.Println("✓ Restore completed successfully!")
+
+	default:
+		log.Fatalf("Unknown action: %s\nAvailable actions: backup, restore", action)
+	}
+}
+
+func splitCSV(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
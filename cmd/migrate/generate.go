@@ -3,57 +3,219 @@ package main
 import (
 	"fmt"
 	"os"
-	"tundra/internal/database/models"
+	"reflect"
+	"strings"
+	"time"
 
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
 )
 
-// GenerateMigrationSQL generates SQL migration from GORM models
-func GenerateMigrationSQL() error {
-	// Create a temporary in-memory database to generate SQL
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		os.Getenv("BLUEPRINT_DB_HOST"),
-		os.Getenv("BLUEPRINT_DB_USERNAME"),
-		os.Getenv("BLUEPRINT_DB_PASSWORD"),
-		os.Getenv("BLUEPRINT_DB_DATABASE"),
-		os.Getenv("BLUEPRINT_DB_PORT"),
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		DryRun: true,
-	})
+// generatorModels is every GORM model the generator diffs against the live schema; add a
+// new model here and the next -action=generate picks up its table/columns/indexes too.
+var generatorModels = []interface{}{
+	&models.User{},
+	&models.Product{},
+	&models.Order{},
+	&models.OrderProduct{},
+	&models.Category{},
+}
+
+// GenerateMigration diffs generatorModels against the live database (via db.Migrator()'s
+// HasTable/ColumnTypes/HasIndex) and writes a new migrations/<timestamp>_<name>.up.sql /
+// .down.sql pair covering whatever has drifted: CREATE TABLE for a model with no table
+// yet, ALTER TABLE ADD/DROP COLUMN for fields that have been added or removed, and
+// CREATE/DROP INDEX for indexes the model declares but the live schema is missing. It
+// never touches the database itself - the emitted SQL is meant to be reviewed in git and
+// applied with -action=up, the same as a hand-written migration.
+func GenerateMigration(db *gorm.DB, name string) error {
+	var upStatements, downStatements []string
+
+	for _, model := range generatorModels {
+		up, down, err := diffModel(db, model)
+		if err != nil {
+			return fmt.Errorf("failed to diff %T: %w", model, err)
+		}
+		upStatements = append(upStatements, up...)
+		downStatements = append(downStatements, down...)
+	}
+
+	if len(upStatements) == 0 {
+		fmt.Println("No schema drift detected against generatorModels; nothing to generate.")
+		return nil
+	}
+
+	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", migrationsDir, err)
+	}
+
+	timestamp := getCurrentTimestamp()
+	upFile := fmt.Sprintf("%s/%d_%s.up.sql", migrationsDir, timestamp, name)
+	downFile := fmt.Sprintf("%s/%d_%s.down.sql", migrationsDir, timestamp, name)
+
+	upContent := fmt.Sprintf("-- Migration: %s\n-- Auto-generated by `tundra migrate -action=generate` from GORM model drift\n\n%s\n",
+		name, strings.Join(upStatements, "\n"))
+	if err := os.WriteFile(upFile, []byte(upContent), 0644); err != nil {
+		return fmt.Errorf("failed to write up migration: %w", err)
+	}
+
+	// Down statements are collected in the same order the up statements that created them
+	// ran, so reverse them to undo last-created-first (a column must be dropped before the
+	// table it belongs to, etc).
+	reverseStrings(downStatements)
+	downContent := fmt.Sprintf("-- Rollback: %s\n-- Auto-generated by `tundra migrate -action=generate` from GORM model drift\n\n%s\n",
+		name, strings.Join(downStatements, "\n"))
+	if err := os.WriteFile(downFile, []byte(downContent), 0644); err != nil {
+		return fmt.Errorf("failed to write down migration: %w", err)
+	}
+
+	fmt.Printf("Generated migration from model drift:\n  - %s\n  - %s\n", upFile, downFile)
+	return nil
+}
+
+// diffModel compares model's current GORM schema against the live table (if any, via
+// db.Migrator()), returning the up/down SQL statements needed to reconcile them.
+func diffModel(db *gorm.DB, model interface{}) (up, down []string, err error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, err
+	}
+	table := stmt.Schema.Table
+	migrator := db.Migrator()
+
+	if !migrator.HasTable(model) {
+		up = append(up, createTableSQL(stmt.Schema))
+		down = append(down, fmt.Sprintf("DROP TABLE IF EXISTS %s;", table))
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			up = append(up, createIndexSQL(table, idx))
+		}
+		return up, down, nil
+	}
+
+	existingColumns, err := migrator.ColumnTypes(model)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, nil, fmt.Errorf("failed to introspect columns for %s: %w", table, err)
+	}
+	existing := make(map[string]bool, len(existingColumns))
+	for _, col := range existingColumns {
+		existing[col.Name()] = true
 	}
 
-	// List of all models to generate migrations for
-	allModels := []interface{}{
-		&models.User{},
-		&models.Product{},
-		&models.Order{},
+	declared := make(map[string]bool, len(stmt.Schema.Fields))
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		declared[field.DBName] = true
+
+		if existing[field.DBName] {
+			continue
+		}
+		up = append(up, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s;", table, field.DBName, columnType(field)))
+		down = append(down, fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", table, field.DBName))
 	}
 
-	fmt.Println("-- Auto-generated migration SQL from GORM models")
-	fmt.Println("-- Generated at:", getCurrentTimestamp())
-	fmt.Println()
+	for name := range existing {
+		if declared[name] {
+			continue
+		}
+		// The live column is no longer declared on the model; its SQL type isn't
+		// recoverable here, so the down side can only flag it for a human to restore.
+		up = append(up, fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", table, name))
+		down = append(down, fmt.Sprintf("-- %s.%s was dropped by this migration; add it back manually to roll back.", table, name))
+	}
 
-	// Generate CREATE TABLE statements
-	for _, model := range allModels {
-		stmt := db.Migrator().CreateTable(model)
-		if stmt != nil {
-			fmt.Println("-- Note: Use GORM's AutoMigrate or manually create the table")
-			fmt.Printf("-- Model: %T\n", model)
-			fmt.Println()
+	for _, idx := range stmt.Schema.ParseIndexes() {
+		if migrator.HasIndex(model, idx.Name) {
+			continue
 		}
+		up = append(up, createIndexSQL(table, idx))
+		down = append(down, fmt.Sprintf("DROP INDEX IF EXISTS %s;", idx.Name))
 	}
 
-	return nil
+	return up, down, nil
+}
+
+// createTableSQL renders a CREATE TABLE statement from sch's fields, in declaration order.
+func createTableSQL(sch *schema.Schema) string {
+	var columns []string
+	var primaryKeys []string
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		columns = append(columns, fmt.Sprintf("%s %s", field.DBName, columnType(field)))
+		if field.PrimaryKey {
+			primaryKeys = append(primaryKeys, field.DBName)
+		}
+	}
+	if len(primaryKeys) > 0 {
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(primaryKeys, ", ")))
+	}
+
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n    %s\n);", sch.Table, strings.Join(columns, ",\n    "))
+}
+
+// createIndexSQL renders a CREATE INDEX (or CREATE UNIQUE INDEX) statement for idx.
+func createIndexSQL(table string, idx *schema.Index) string {
+	columns := make([]string, len(idx.Fields))
+	for i, f := range idx.Fields {
+		columns[i] = f.DBName
+	}
+
+	unique := ""
+	if idx.Class == "UNIQUE" {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s);", unique, idx.Name, table, strings.Join(columns, ", "))
+}
+
+// columnType renders field's SQL column type plus NOT NULL/DEFAULT, preferring the
+// explicit `gorm:"type:..."` tag most models in this repo already set and falling back to
+// a plain Go-kind-to-Postgres-type mapping otherwise.
+func columnType(field *schema.Field) string {
+	parts := []string{postgresType(field)}
+
+	if field.NotNull {
+		parts = append(parts, "NOT NULL")
+	}
+	if def, ok := field.TagSettings["DEFAULT"]; ok {
+		parts = append(parts, "DEFAULT "+def)
+	}
+
+	return strings.Join(parts, " ")
 }
 
-// GetModelSchema returns the SQL schema for a given model
-func GetModelSchema(db *gorm.DB, model interface{}) (string, error) {
+func postgresType(field *schema.Field) string {
+	if explicit, ok := field.TagSettings["TYPE"]; ok {
+		return explicit
+	}
+
+	if field.FieldType == reflect.TypeOf(time.Time{}) {
+		return "TIMESTAMPTZ"
+	}
 
-	return "", nil
+	switch field.FieldType.Kind() {
+	case reflect.String:
+		return "TEXT"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "INTEGER"
+	case reflect.Int64, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32, reflect.Float64:
+		return "DOUBLE PRECISION"
+	case reflect.Bool:
+		return "BOOLEAN"
+	default:
+		return "TEXT"
+	}
+}
+
+func reverseStrings(s []string) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// Migration is implemented by Go-based migrations - for changes plain SQL can't express,
+// like backfilling data with application logic. Register an instance from an init() in
+// its own file in this package (cmd/migrate/); runGoMigrations then applies every
+// migration whose Version() is newer than the last one recorded, in order.
+type Migration interface {
+	Version() int
+	Name() string
+	Up(*gorm.DB) error
+	Down(*gorm.DB) error
+}
+
+var goMigrations []Migration
+
+// RegisterMigration is called from an init() function in each Go-based migration file.
+func RegisterMigration(m Migration) {
+	goMigrations = append(goMigrations, m)
+}
+
+func sortedGoMigrations() []Migration {
+	sorted := make([]Migration, len(goMigrations))
+	copy(sorted, goMigrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version() < sorted[j].Version() })
+	return sorted
+}
+
+func ensureGoMigrationsTable(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS go_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`).Error
+}
+
+// runGoMigrations applies every registered Go migration newer than the last one recorded
+// in go_migrations, in version order. Called after the SQL migrations in `-action=up`.
+func runGoMigrations(db *gorm.DB) error {
+	if err := ensureGoMigrationsTable(db); err != nil {
+		return fmt.Errorf("failed to create go_migrations table: %w", err)
+	}
+
+	var lastVersion int64
+	if err := db.Raw(`SELECT COALESCE(MAX(version), 0) FROM go_migrations`).Scan(&lastVersion).Error; err != nil {
+		return fmt.Errorf("failed to read go_migrations: %w", err)
+	}
+
+	for _, m := range sortedGoMigrations() {
+		if int64(m.Version()) <= lastVersion {
+			continue
+		}
+		if err := m.Up(db); err != nil {
+			return fmt.Errorf("go migration %d_%s failed: %w", m.Version(), m.Name(), err)
+		}
+		if err := db.Exec(`INSERT INTO go_migrations (version, name) VALUES (?, ?)`, m.Version(), m.Name()).Error; err != nil {
+			return fmt.Errorf("failed to record go migration %d_%s: %w", m.Version(), m.Name(), err)
+		}
+		fmt.Printf("✓ Applied Go migration %d_%s\n", m.Version(), m.Name())
+	}
+
+	return nil
+}
+
+// goMigrationTemplate is rendered into cmd/migrate/<timestamp>_<name>.go by
+// `-action=create -go`. The generated file registers itself via init(), so it only needs
+// to be dropped into the package - no wiring required.
+const goMigrationTemplate = `package main
+
+import "gorm.io/gorm"
+
+func init() {
+	RegisterMigration(&migration%s{})
+}
+
+type migration%s struct{}
+
+func (migration%s) Version() int { return %s }
+func (migration%s) Name() string { return %q }
+
+func (migration%s) Up(db *gorm.DB) error {
+	// TODO: implement the migration
+	return nil
+}
+
+func (migration%s) Down(db *gorm.DB) error {
+	// TODO: implement the rollback
+	return nil
+}
+`
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrationLockKey is an arbitrary, stable key for the Postgres advisory lock that
+// serializes migration runs. Without it, two pods starting at once could both see the
+// same pending version and race to apply it, corrupting schema_migrations.
+const migrationLockKey = 847362910
+
+// acquireMigrationLock blocks until it holds a session-level Postgres advisory lock,
+// then returns a function that releases it. Call the returned function before the
+// connection that acquired the lock is closed.
+func acquireMigrationLock(db *sql.DB) (func() error, error) {
+	if _, err := db.Exec("SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	return func() error {
+		_, err := db.Exec("SELECT pg_advisory_unlock($1)", migrationLockKey)
+		return err
+	}, nil
+}
@@ -1,24 +1,35 @@
 package main
 
 import (
+	"database/sql"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"time"
+	"unicode"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/lib/pq"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 
 	"github.com/GRACENOBLE/tundra/internal/database"
 )
 
+const migrationsDir = "migrations"
+const seedsDir = migrationsDir + "/seeds"
+const goMigrationsDir = "cmd/migrate"
+
 func main() {
 	var action string
 	var steps int
-	flag.StringVar(&action, "action", "up", "Migration action: up, down, status, force, version")
+	var goMigration bool
+	flag.StringVar(&action, "action", "up", "Migration action: up, down, goto, status, force, plan, squash, seed, create, generate")
 	flag.IntVar(&steps, "steps", 0, "Number of steps to migrate (use with up/down)")
+	flag.BoolVar(&goMigration, "go", false, "With -action=create, scaffold a Go-based migration instead of SQL")
 	flag.Parse()
 
 	// Get database connection string
@@ -41,7 +52,7 @@ func main() {
 
 	// Initialize migrate instance
 	m, err := migrate.New(
-		"file://migrations",
+		"file://"+migrationsDir,
 		databaseURL,
 	)
 	if err != nil {
@@ -56,13 +67,29 @@ func main() {
 			log.Fatal("Please provide a migration name: -action=create <migration_name>")
 		}
 		migrationName := flag.Arg(0)
-		if err := createMigration(migrationName); err != nil {
+		if goMigration {
+			if err := createGoMigration(migrationName); err != nil {
+				log.Fatalf("Failed to create Go migration: %v", err)
+			}
+		} else if err := createMigration(migrationName); err != nil {
 			log.Fatalf("Failed to create migration: %v", err)
 		}
 		log.Printf("✓ Migration files created successfully!")
 		return
 
 	case "up":
+		rawDB, err := sql.Open("postgres", databaseURL)
+		if err != nil {
+			log.Fatalf("Failed to open database connection: %v", err)
+		}
+		defer rawDB.Close()
+
+		release, err := acquireMigrationLock(rawDB)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer release()
+
 		if steps > 0 {
 			log.Printf("Migrating up %d steps...\n", steps)
 			if err := m.Steps(steps); err != nil && err != migrate.ErrNoChange {
@@ -76,6 +103,28 @@ func main() {
 		}
 		log.Println("✓ Migrations completed successfully!")
 
+		gormDB, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("Failed to open gorm connection for Go migrations: %v", err)
+		}
+		if err := runGoMigrations(gormDB); err != nil {
+			log.Fatalf("Failed to run Go migrations: %v", err)
+		}
+
+	case "goto":
+		if len(flag.Args()) == 0 {
+			log.Fatal("Please specify a version to migrate to: -action=goto <version>")
+		}
+		var version uint
+		if _, err := fmt.Sscanf(flag.Arg(0), "%d", &version); err != nil {
+			log.Fatalf("Invalid version number: %v", err)
+		}
+		log.Printf("Migrating to version %d...\n", version)
+		if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
+			log.Fatalf("Failed to migrate to version %d: %v", version, err)
+		}
+		log.Println("✓ Migrated to target version successfully!")
+
 	case "down":
 		if steps > 0 {
 			log.Printf("Rolling back %d steps...\n", steps)
@@ -119,13 +168,77 @@ func main() {
 		}
 		log.Println("✓ Version forced successfully!")
 
+	case "plan":
+		rawDB, err := sql.Open("postgres", databaseURL)
+		if err != nil {
+			log.Fatalf("Failed to open database connection: %v", err)
+		}
+		defer rawDB.Close()
+
+		version, dirty, err := m.Version()
+		if err != nil && err != migrate.ErrNilVersion {
+			log.Fatalf("Failed to get version: %v", err)
+		}
+		if dirty {
+			log.Println("⚠️  database is in a dirty state, run -action=force first")
+		}
+		if err := planMigrations(rawDB, migrationsDir, int(version), err != migrate.ErrNilVersion); err != nil {
+			log.Fatalf("Failed to plan migrations: %v", err)
+		}
+
+	case "squash":
+		if len(flag.Args()) < 2 {
+			log.Fatal("Please specify a version range: -action=squash <from> <to>")
+		}
+		var from, to int
+		if _, err := fmt.Sscanf(flag.Arg(0), "%d", &from); err != nil {
+			log.Fatalf("Invalid 'from' version: %v", err)
+		}
+		if _, err := fmt.Sscanf(flag.Arg(1), "%d", &to); err != nil {
+			log.Fatalf("Invalid 'to' version: %v", err)
+		}
+		if err := squashMigrations(migrationsDir, from, to); err != nil {
+			log.Fatalf("Failed to squash migrations: %v", err)
+		}
+
+	case "seed":
+		rawDB, err := sql.Open("postgres", databaseURL)
+		if err != nil {
+			log.Fatalf("Failed to open database connection: %v", err)
+		}
+		defer rawDB.Close()
+
+		release, err := acquireMigrationLock(rawDB)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer release()
+
+		if err := runSeeds(rawDB, seedsDir); err != nil {
+			log.Fatalf("Failed to run seeds: %v", err)
+		}
+		log.Println("✓ Seeds completed successfully!")
+
+	case "generate":
+		if len(flag.Args()) == 0 {
+			log.Fatal("Please provide a migration name: -action=generate <migration_name>")
+		}
+		gormDB, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("Failed to open gorm connection for model diffing: %v", err)
+		}
+		if err := GenerateMigration(gormDB, flag.Arg(0)); err != nil {
+			log.Fatalf("Failed to generate migration: %v", err)
+		}
+		return
+
 	case "drop":
 		log.Println("⚠️  WARNING: This will drop all tables!")
 		log.Println("Use -action=down to rollback migrations instead.")
 		os.Exit(1)
 
 	default:
-		log.Fatalf("Unknown action: %s\nAvailable actions: up, down, status, version, force", action)
+		log.Fatalf("Unknown action: %s\nAvailable actions: up, down, goto, status, version, force, plan, squash, seed, create, generate", action)
 	}
 
 	os.Exit(0)
@@ -174,6 +287,47 @@ func createMigration(name string) error {
 	return nil
 }
 
+// createGoMigration scaffolds a Go-based migration into this package (cmd/migrate/),
+// implementing the Migration interface and self-registering via init().
+func createGoMigration(name string) error {
+	if err := os.MkdirAll(goMigrationsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", goMigrationsDir, err)
+	}
+
+	timestamp := getCurrentTimestamp()
+	structName := goMigrationStructName(timestamp, name)
+	path := fmt.Sprintf("%s/%d_%s.go", goMigrationsDir, timestamp, name)
+
+	content := fmt.Sprintf(goMigrationTemplate,
+		structName, structName, structName, fmt.Sprintf("%d", timestamp), structName, name, structName, structName)
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to create Go migration: %w", err)
+	}
+
+	log.Printf("Created Go migration file:\n")
+	log.Printf("  - %s\n", path)
+
+	return nil
+}
+
+func goMigrationStructName(timestamp int64, name string) string {
+	sanitized := make([]rune, 0, len(name))
+	capitalizeNext := true
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			capitalizeNext = true
+		case capitalizeNext:
+			sanitized = append(sanitized, unicode.ToUpper(r))
+			capitalizeNext = false
+		default:
+			sanitized = append(sanitized, r)
+		}
+	}
+	return fmt.Sprintf("%d%s", timestamp, string(sanitized))
+}
+
 func getCurrentTimestamp() int64 {
 	// Using format: YYYYMMDDHHMMSS (e.g., 20241111123045)
 	// This matches the golang-migrate timestamp format
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// migrationFile describes one version's up/down pair on disk, plus the sha256 checksum
+// of its up file used to detect tampering after it has been applied.
+type migrationFile struct {
+	Version  int
+	Name     string
+	UpPath   string
+	DownPath string
+	Checksum string
+}
+
+func loadMigrationFiles(dir string) ([]migrationFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int]*migrationFile)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := migrationFileRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+
+		mf, ok := byVersion[version]
+		if !ok {
+			mf = &migrationFile{Version: version, Name: matches[2]}
+			byVersion[version] = mf
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if matches[3] == "up" {
+			mf.UpPath = path
+		} else {
+			mf.DownPath = path
+		}
+	}
+
+	files := make([]migrationFile, 0, len(byVersion))
+	for _, mf := range byVersion {
+		if mf.UpPath != "" {
+			content, err := os.ReadFile(mf.UpPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", mf.UpPath, err)
+			}
+			mf.Checksum = checksumSQL(content)
+		}
+		files = append(files, *mf)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Version < files[j].Version })
+	return files, nil
+}
+
+func checksumSQL(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ensureChecksumsTable creates the bookkeeping table used to detect migrations edited
+// after being applied. golang-migrate's own schema_migrations only tracks the latest
+// version number, not a checksum per file, so we keep our own ledger alongside it.
+func ensureChecksumsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations_checksums (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations_checksums table: %w", err)
+	}
+	return nil
+}
+
+func recordChecksum(db *sql.DB, mf migrationFile) error {
+	_, err := db.Exec(`
+		INSERT INTO schema_migrations_checksums (version, name, checksum)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (version) DO UPDATE SET checksum = EXCLUDED.checksum, applied_at = now()
+	`, mf.Version, mf.Name, mf.Checksum)
+	return err
+}
+
+func recordedChecksums(db *sql.DB) (map[int]string, error) {
+	rows, err := db.Query(`SELECT version, checksum FROM schema_migrations_checksums`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations_checksums: %w", err)
+	}
+	defer rows.Close()
+
+	recorded := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, err
+		}
+		recorded[version] = checksum
+	}
+	return recorded, rows.Err()
+}
+
+// planMigrations prints a checksum diff for every already-applied migration (flagging
+// any file edited since it ran) and the full SQL of every migration still pending.
+func planMigrations(db *sql.DB, dir string, currentVersion int, hasVersion bool) error {
+	if err := ensureChecksumsTable(db); err != nil {
+		return err
+	}
+
+	files, err := loadMigrationFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	recorded, err := recordedChecksums(db)
+	if err != nil {
+		return err
+	}
+
+	var pending []migrationFile
+	for _, mf := range files {
+		if !hasVersion || mf.Version > currentVersion {
+			pending = append(pending, mf)
+			continue
+		}
+
+		if recordedSum, ok := recorded[mf.Version]; ok {
+			if recordedSum != mf.Checksum {
+				log.Printf("⚠️  migration %d_%s was modified after being applied (recorded=%s, current=%s)",
+					mf.Version, mf.Name, recordedSum[:12], mf.Checksum[:12])
+			}
+		} else if err := recordChecksum(db, mf); err != nil {
+			return err
+		}
+	}
+
+	if len(pending) == 0 {
+		log.Println("No pending migrations")
+		return nil
+	}
+
+	log.Printf("%d pending migration(s):", len(pending))
+	for _, mf := range pending {
+		content, err := os.ReadFile(mf.UpPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("-- %d_%s.up.sql (checksum %s)\n", mf.Version, mf.Name, mf.Checksum[:12])
+		fmt.Println(strings.TrimSpace(string(content)))
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// runSeeds executes every *.sql file in seedsDir, in filename order. Seed files are
+// expected to be idempotent (INSERT ... ON CONFLICT DO NOTHING, etc.) since they are
+// re-run on every `-action=seed` invocation rather than tracked like migrations.
+func runSeeds(db *sql.DB, seedsDir string) error {
+	entries, err := os.ReadDir(seedsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Printf("No seeds directory at %s, skipping", seedsDir)
+			return nil
+		}
+		return fmt.Errorf("failed to read seeds directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".sql" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(seedsDir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read seed %s: %w", path, err)
+		}
+		if _, err := db.Exec(string(content)); err != nil {
+			return fmt.Errorf("failed to run seed %s: %w", path, err)
+		}
+		log.Printf("✓ Ran seed %s", name)
+	}
+
+	return nil
+}
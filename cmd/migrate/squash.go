@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// squashMigrations concatenates every migration in [from, to] into a single new
+// migration pair named after the range, then deletes the originals so that a fresh
+// `migrate up` replays one file instead of the whole history.
+func squashMigrations(dir string, from, to int) error {
+	files, err := loadMigrationFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	var inRange []migrationFile
+	for _, mf := range files {
+		if mf.Version >= from && mf.Version <= to {
+			inRange = append(inRange, mf)
+		}
+	}
+	if len(inRange) == 0 {
+		return fmt.Errorf("no migrations found in range [%d, %d]", from, to)
+	}
+
+	name := fmt.Sprintf("squash_%d_%d", from, to)
+	timestamp := fmt.Sprintf("%d", getCurrentTimestamp())
+	upPath := filepath.Join(dir, fmt.Sprintf("%s_%s.up.sql", timestamp, name))
+	downPath := filepath.Join(dir, fmt.Sprintf("%s_%s.down.sql", timestamp, name))
+
+	var up strings.Builder
+	up.WriteString(fmt.Sprintf("-- Squashed migration: versions %d through %d\n\n", from, to))
+	for _, mf := range inRange {
+		content, err := os.ReadFile(mf.UpPath)
+		if err != nil {
+			return err
+		}
+		up.WriteString(fmt.Sprintf("-- From %d_%s.up.sql\n", mf.Version, mf.Name))
+		up.Write(content)
+		up.WriteString("\n\n")
+	}
+
+	var down strings.Builder
+	down.WriteString(fmt.Sprintf("-- Rollback for squashed migration: versions %d through %d\n\n", from, to))
+	for i := len(inRange) - 1; i >= 0; i-- {
+		mf := inRange[i]
+		if mf.DownPath == "" {
+			return fmt.Errorf("migration %d_%s has no down file, refusing to squash", mf.Version, mf.Name)
+		}
+		content, err := os.ReadFile(mf.DownPath)
+		if err != nil {
+			return err
+		}
+		down.WriteString(fmt.Sprintf("-- From %d_%s.down.sql\n", mf.Version, mf.Name))
+		down.Write(content)
+		down.WriteString("\n\n")
+	}
+
+	if err := os.WriteFile(upPath, []byte(up.String()), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(downPath, []byte(down.String()), 0644); err != nil {
+		return err
+	}
+
+	for _, mf := range inRange {
+		if err := os.Remove(mf.UpPath); err != nil {
+			return err
+		}
+		if mf.DownPath != "" {
+			if err := os.Remove(mf.DownPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("Squashed %d migrations into:\n  - %s\n  - %s\n", len(inRange), upPath, downPath)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/GRACENOBLE/tundra/internal/auth"
 	"github.com/GRACENOBLE/tundra/internal/database"
 	"github.com/GRACENOBLE/tundra/internal/database/models"
 	_ "github.com/joho/godotenv/autoload"
@@ -67,6 +68,114 @@ func main() {
 		log.Fatalf("Failed to get admin user: %v", err)
 	}
 
+	// Seed a refresh token for the admin so local dev clients can exercise
+	// POST /auth/refresh without logging in first
+	refreshTokens := auth.NewRefreshTokenStore(db)
+	if seededRefreshToken, _, err := refreshTokens.Issue(adminForProducts.ID, "seed-script", "127.0.0.1"); err != nil {
+		log.Printf("Warning: failed to seed admin refresh token: %v", err)
+	} else {
+		fmt.Printf("✓ Seeded admin refresh token: %s\n", seededRefreshToken)
+	}
+
+	// Seed a linked Dex account for the admin so local dev clients can exercise the
+	// GET /auth/dex/login -> /auth/dex/callback flow against a local Dex instance
+	// (https://github.com/dexidp/dex) without a real Google/GitHub app registration.
+	var existingLink models.LinkedAccount
+	result = db.Where("provider = ? AND provider_user_id = ?", "dex", "dev-admin").First(&existingLink)
+	if result.Error == nil {
+		fmt.Println("⚠️  Dev Dex linked account already exists, skipping creation")
+	} else {
+		devLink := models.LinkedAccount{
+			UserID:         adminForProducts.ID,
+			Provider:       "dex",
+			ProviderUserID: "dev-admin",
+		}
+		if err := db.Create(&devLink).Error; err != nil {
+			log.Printf("Warning: failed to seed dev Dex linked account: %v", err)
+		} else {
+			fmt.Println("✓ Seeded dev Dex linked account for admin user")
+		}
+	}
+
+	// Seed RBAC roles, permissions, and grants so a fresh dev database has a working
+	// admin/user split from the start (productsAdmin routes require "products:write").
+	fmt.Println("\nSeeding RBAC roles and permissions...")
+	rolePermissions := map[string][]string{
+		"admin":    {"products:read", "products:write", "orders:read", "orders:write"},
+		"user":     {"products:read", "orders:read", "orders:write"},
+		"readonly": {"products:read", "orders:read"},
+	}
+	permissionDescriptions := map[string]string{
+		"products:read":  "View product catalog",
+		"products:write": "Create, update, and delete products",
+		"orders:read":    "View orders",
+		"orders:write":   "Create orders",
+	}
+
+	permissionsByKey := make(map[string]models.Permission)
+	for key, description := range permissionDescriptions {
+		var permission models.Permission
+		if err := db.Where("key = ?", key).Attrs(models.Permission{Description: description}).FirstOrCreate(&permission).Error; err != nil {
+			log.Fatalf("Failed to seed permission %s: %v", key, err)
+		}
+		permissionsByKey[key] = permission
+	}
+
+	rolesByName := make(map[string]models.Role)
+	for roleName := range rolePermissions {
+		var role models.Role
+		if err := db.Where("name = ?", roleName).FirstOrCreate(&role, models.Role{Name: roleName}).Error; err != nil {
+			log.Fatalf("Failed to seed role %s: %v", roleName, err)
+		}
+		rolesByName[roleName] = role
+
+		for _, key := range rolePermissions[roleName] {
+			permission := permissionsByKey[key]
+			var grant models.RolePermission
+			err := db.Where("role_id = ? AND permission_id = ?", role.ID, permission.ID).
+				FirstOrCreate(&grant, models.RolePermission{RoleID: role.ID, PermissionID: permission.ID}).Error
+			if err != nil {
+				log.Printf("Warning: failed to grant %s to role %s: %v", key, roleName, err)
+			}
+		}
+	}
+	fmt.Println("✓ Seeded roles: admin, user, readonly")
+
+	rbac := auth.NewRBACStore(db)
+	if err := rbac.GrantRole(adminForProducts.ID, "admin"); err != nil {
+		log.Printf("Warning: failed to grant admin role to admin user: %v", err)
+	}
+	var regularForRoles models.User
+	if err := db.Where("email = ?", regularUser.Email).First(&regularForRoles).Error; err == nil {
+		if err := rbac.GrantRole(regularForRoles.ID, "user"); err != nil {
+			log.Printf("Warning: failed to grant user role to regular user: %v", err)
+		}
+	}
+	fmt.Println("✓ Granted admin/user roles to seeded users")
+
+	// Seed a default domain so local dev clients have something to pass as :domainID on
+	// the /domains/:domainID/products routes, with the admin as its "admin" member and
+	// the regular user as a plain "member" so both roles can be exercised locally.
+	fmt.Println("\nSeeding default domain...")
+	var defaultDomain models.Domain
+	if err := db.Where("name = ?", "Default Store").FirstOrCreate(&defaultDomain, models.Domain{
+		Name:        "Default Store",
+		OwnerUserID: adminForProducts.ID,
+	}).Error; err != nil {
+		log.Fatalf("Failed to seed default domain: %v", err)
+	}
+	fmt.Printf("✓ Seeded default domain: %s (%s)\n", defaultDomain.Name, defaultDomain.ID)
+
+	if err := db.Where("domain_id = ? AND user_id = ?", defaultDomain.ID, adminForProducts.ID).
+		FirstOrCreate(&models.DomainMember{}, models.DomainMember{DomainID: defaultDomain.ID, UserID: adminForProducts.ID, Role: "admin"}).Error; err != nil {
+		log.Printf("Warning: failed to seed admin domain membership: %v", err)
+	}
+	if err := db.Where("domain_id = ? AND user_id = ?", defaultDomain.ID, regularForRoles.ID).
+		FirstOrCreate(&models.DomainMember{}, models.DomainMember{DomainID: defaultDomain.ID, UserID: regularForRoles.ID, Role: "member"}).Error; err != nil {
+		log.Printf("Warning: failed to seed regular user domain membership: %v", err)
+	}
+	fmt.Println("✓ Granted admin/member domain roles to seeded users")
+
 	// Seed products
 	fmt.Println("\nSeeding products...")
 	products := []models.Product{
@@ -77,6 +186,7 @@ func main() {
 			Stock:       25,
 			Category:    "Electronics",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "Wireless Mouse",
@@ -85,6 +195,7 @@ func main() {
 			Stock:       150,
 			Category:    "Accessories",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "Mechanical Keyboard",
@@ -93,6 +204,7 @@ func main() {
 			Stock:       75,
 			Category:    "Accessories",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "4K Monitor",
@@ -101,6 +213,7 @@ func main() {
 			Stock:       40,
 			Category:    "Electronics",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "USB-C Hub",
@@ -109,6 +222,7 @@ func main() {
 			Stock:       200,
 			Category:    "Accessories",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "Wireless Headphones",
@@ -117,6 +231,7 @@ func main() {
 			Stock:       60,
 			Category:    "Audio",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "Webcam HD",
@@ -125,6 +240,7 @@ func main() {
 			Stock:       90,
 			Category:    "Electronics",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "Laptop Stand",
@@ -133,6 +249,7 @@ func main() {
 			Stock:       120,
 			Category:    "Accessories",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "External SSD 1TB",
@@ -141,6 +258,7 @@ func main() {
 			Stock:       80,
 			Category:    "Storage",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 		{
 			Name:        "Cable Management Kit",
@@ -149,6 +267,7 @@ func main() {
 			Stock:       180,
 			Category:    "Accessories",
 			UserID:      adminForProducts.ID,
+			DomainID:    defaultDomain.ID,
 		},
 	}
 
@@ -0,0 +1,78 @@
+// Package apierr is this API's one shape for an error response: a stable machine-readable
+// Code plus a human Message, mapped to the HTTP status a handler would otherwise have typed
+// out by hand. A handler constructs one with a helper like BadRequest or Conflict and hands
+// it to gin via c.Error; Middleware serializes whatever c.Error collected into the response
+// body, so every endpoint's errors look the same on the wire without each handler writing
+// its own gin.H{"error": ...}.
+package apierr
+
+import "net/http"
+
+// APIError is a single error response, serialized by Middleware as
+// {"error": {"code", "message", "details", "request_id"}}.
+type APIError struct {
+	// Code is a short, stable, machine-readable identifier (e.g. "validation_failed",
+	// "email_taken") a client can switch on without parsing Message.
+	Code string
+	// Message is a human-readable description, safe to show a user or log verbatim.
+	Message string
+	// Status is the HTTP status code Middleware writes the response with.
+	Status int
+	// Details carries optional structured context (e.g. {"field": "email"}); omitted
+	// from the response entirely when nil.
+	Details map[string]any
+}
+
+// Error satisfies the error interface so an *APIError can be passed to c.Error.
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of e with Details set, for chaining onto a constructor:
+// apierr.BadRequest("validation_failed", "...").WithDetails(gin.H{"field": "email"}).
+func (e *APIError) WithDetails(details map[string]any) *APIError {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+func newError(status int, code, message string) *APIError {
+	return &APIError{Code: code, Message: message, Status: status}
+}
+
+// BadRequest builds a 400 APIError - the request body or parameters are malformed.
+func BadRequest(code, message string) *APIError {
+	return newError(http.StatusBadRequest, code, message)
+}
+
+// Unauthorized builds a 401 APIError - the caller isn't authenticated, or their
+// credentials/token are invalid.
+func Unauthorized(code, message string) *APIError {
+	return newError(http.StatusUnauthorized, code, message)
+}
+
+// Forbidden builds a 403 APIError - the caller is authenticated but not allowed to perform
+// this action.
+func Forbidden(code, message string) *APIError {
+	return newError(http.StatusForbidden, code, message)
+}
+
+// NotFound builds a 404 APIError - the resource the request names doesn't exist, or not
+// within the caller's visibility (cross-domain/cross-user IDs should report NotFound rather
+// than Forbidden, matching this repo's existing handlers).
+func NotFound(code, message string) *APIError {
+	return newError(http.StatusNotFound, code, message)
+}
+
+// Conflict builds a 409 APIError - the request is well-formed, but the current state of the
+// resource it targets makes it unsatisfiable (e.g. insufficient stock, an invalid order
+// status transition, a taken email).
+func Conflict(code, message string) *APIError {
+	return newError(http.StatusConflict, code, message)
+}
+
+// Internal builds a 500 APIError - something on this server's side went wrong that the
+// caller can't fix by changing their request.
+func Internal(code, message string) *APIError {
+	return newError(http.StatusInternalServerError, code, message)
+}
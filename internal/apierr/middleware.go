@@ -0,0 +1,45 @@
+package apierr
+
+import (
+	"github.com/GRACENOBLE/tundra/internal/middleware/accesslog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware serializes the last error a handler attached with c.Error(apierr.X) into
+// {"error": {"code", "message", "details", "request_id"}}, reusing the X-Request-ID
+// accesslog.New already stashed in the request's context so a client and the access log line
+// for the same request can be correlated. A non-*APIError (e.g. one gin's own binding wired
+// up via c.Error) is reported as an opaque 500, since only an *APIError carries a Status and
+// Code this middleware can trust.
+//
+// Register it after accesslog.New, so RequestIDFromContext has already run, and it must run
+// after every handler, so it belongs last among r.Use calls in RegisterRoutes.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			apiErr = Internal("internal_error", "An unexpected error occurred")
+		}
+
+		requestID, _ := accesslog.RequestIDFromContext(c.Request.Context())
+
+		body := gin.H{
+			"code":       apiErr.Code,
+			"message":    apiErr.Message,
+			"request_id": requestID,
+		}
+		if apiErr.Details != nil {
+			body["details"] = apiErr.Details
+		}
+
+		c.JSON(apiErr.Status, gin.H{"error": body})
+	}
+}
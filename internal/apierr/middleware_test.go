@@ -0,0 +1,81 @@
+package apierr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/test", handler)
+	return r
+}
+
+func TestMiddlewareSerializesAPIError(t *testing.T) {
+	r := newTestRouter(func(c *gin.Context) {
+		c.Error(Conflict("email_taken", "Email is already registered"))
+	})
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d", http.StatusConflict, resp.Code)
+	}
+
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if body.Error.Code != "email_taken" {
+		t.Errorf("expected code %q, got %q", "email_taken", body.Error.Code)
+	}
+	if body.Error.Message != "Email is already registered" {
+		t.Errorf("expected message %q, got %q", "Email is already registered", body.Error.Message)
+	}
+}
+
+func TestMiddlewareFallsBackToInternalForUnknownError(t *testing.T) {
+	r := newTestRouter(func(c *gin.Context) {
+		c.Error(errNotAnAPIError{})
+	})
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, resp.Code)
+	}
+}
+
+func TestMiddlewareSkipsAlreadyWrittenResponse(t *testing.T) {
+	r := newTestRouter(func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+		c.Error(Conflict("ignored", "should not surface"))
+	})
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+}
+
+type errNotAnAPIError struct{}
+
+func (errNotAnAPIError) Error() string { return "not an APIError" }
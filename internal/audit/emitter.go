@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/GRACENOBLE/tundra/internal/middleware/accesslog"
+)
+
+// Emitter records an Event. Implementations decide where it ends up (database, file,
+// nowhere); Emit should not block the request longer than necessary and should prefer
+// logging its own failure over returning an error that would fail the calling handler.
+type Emitter interface {
+	Emit(ctx context.Context, event Event) error
+}
+
+// emitter is the process-wide Emitter every auth handler calls through. It defaults to a
+// NoOpEmitter so the server runs without audit logging configured; call SetEmitter (or
+// SetEmitters for more than one sink) during startup to wire GORMEmitter, JSONLEmitter, or both.
+var emitter Emitter = NewNoOpEmitter()
+
+// SetEmitter replaces the process-wide Emitter.
+func SetEmitter(e Emitter) {
+	if e == nil {
+		e = NewNoOpEmitter()
+	}
+	emitter = e
+}
+
+// SetEmitters wires multiple sinks at once via MultiEmitter.
+func SetEmitters(emitters ...Emitter) {
+	SetEmitter(NewMultiEmitter(emitters...))
+}
+
+// Emit timestamps event, fills in RequestID from ctx when the caller left it blank, and
+// forwards it to the process-wide Emitter.
+func Emit(ctx context.Context, event Event) error {
+	event.Timestamp = time.Now()
+	if event.RequestID == "" {
+		if id, ok := accesslog.RequestIDFromContext(ctx); ok {
+			event.RequestID = id
+		}
+	}
+	return emitter.Emit(ctx, event)
+}
+
+// NoOpEmitter discards every event. It's the default so audit logging is opt-in.
+type NoOpEmitter struct{}
+
+// NewNoOpEmitter creates a NoOpEmitter.
+func NewNoOpEmitter() *NoOpEmitter {
+	return &NoOpEmitter{}
+}
+
+func (e *NoOpEmitter) Emit(ctx context.Context, event Event) error {
+	return nil
+}
+
+// MultiEmitter fans an event out to every wrapped Emitter, collecting (but not stopping on)
+// individual sink failures so one broken sink can't silence the others.
+type MultiEmitter struct {
+	emitters []Emitter
+}
+
+// NewMultiEmitter creates a MultiEmitter wrapping emitters.
+func NewMultiEmitter(emitters ...Emitter) *MultiEmitter {
+	return &MultiEmitter{emitters: emitters}
+}
+
+func (m *MultiEmitter) Emit(ctx context.Context, event Event) error {
+	var firstErr error
+	for _, e := range m.emitters {
+		if err := e.Emit(ctx, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,40 @@
+// Package audit decouples "something security-relevant happened" from "where that record
+// ends up", the same split Teleport draws between emitting a session event and recording
+// it: handlers call Emit with an Event and never know (or care) which sinks are wired up.
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies the kind of auth event being recorded.
+type EventType string
+
+const (
+	EventLoginSucceeded  EventType = "login_succeeded"
+	EventLoginFailed     EventType = "login_failed"
+	EventSignUp          EventType = "sign_up"
+	EventPasswordChanged EventType = "password_changed"
+	EventTokenRevoked    EventType = "token_revoked"
+	EventAdminAction     EventType = "admin_action"
+	EventRateLimitWarned EventType = "rate_limit_warned"
+)
+
+// Event is one audit record. UserID is nil when the actor couldn't be identified (e.g. a
+// failed login against an unknown email).
+type Event struct {
+	Type      EventType
+	UserID    *uuid.UUID
+	IP        string
+	UserAgent string
+	Timestamp time.Time
+	// RequestID correlates this event with the access log line and any other event
+	// emitted for the same HTTP request. Emit fills it in from ctx (as stashed by
+	// accesslog.New) when left blank.
+	RequestID string
+	// Metadata holds event-specific detail (e.g. {"reason": "wrong_password"} for
+	// EventLoginFailed, {"action": "grant_role", "role": "admin"} for EventAdminAction).
+	Metadata map[string]any
+}
@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+)
+
+// GORMEmitter persists events to the audit_events table, so they can be queried alongside
+// the rest of the application's data (e.g. GET /admin/audit).
+type GORMEmitter struct {
+	db *gorm.DB
+}
+
+// NewGORMEmitter creates a GORMEmitter backed by db.
+func NewGORMEmitter(db *gorm.DB) *GORMEmitter {
+	return &GORMEmitter{db: db}
+}
+
+func (e *GORMEmitter) Emit(ctx context.Context, event Event) error {
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return err
+	}
+
+	record := models.AuditEvent{
+		Type:      string(event.Type),
+		UserID:    event.UserID,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Metadata:  string(metadata),
+		RequestID: event.RequestID,
+	}
+
+	return e.db.WithContext(ctx).Create(&record).Error
+}
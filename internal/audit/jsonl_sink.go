@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// jsonlRecord is the on-disk shape written by JSONLEmitter, one per line.
+type jsonlRecord struct {
+	Type      EventType      `json:"type"`
+	UserID    *string        `json:"user_id,omitempty"`
+	IP        string         `json:"ip"`
+	UserAgent string         `json:"user_agent"`
+	Timestamp string         `json:"timestamp"`
+	RequestID string         `json:"request_id,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// JSONLEmitter appends one JSON object per line to a file, suitable for tailing into a SIEM.
+type JSONLEmitter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJSONLEmitter opens (creating if necessary) path for appending and returns an Emitter
+// that writes one JSON-lines record per event.
+func NewJSONLEmitter(path string) (*JSONLEmitter, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLEmitter{file: file}, nil
+}
+
+func (e *JSONLEmitter) Emit(ctx context.Context, event Event) error {
+	var userID *string
+	if event.UserID != nil {
+		s := event.UserID.String()
+		userID = &s
+	}
+
+	line, err := json.Marshal(jsonlRecord{
+		Type:      event.Type,
+		UserID:    userID,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Timestamp: event.Timestamp.Format("2006-01-02T15:04:05.000Z07:00"),
+		RequestID: event.RequestID,
+		Metadata:  event.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.file.Write(line)
+	return err
+}
+
+// Close closes the underlying file.
+func (e *JSONLEmitter) Close() error {
+	return e.file.Close()
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+)
+
+// SubTypeAppRole is the Claims.SubType value carried by a token minted from a successful
+// AppRole login - see Claims.SubType for how it's meant to be used.
+const SubTypeAppRole = "approle"
+
+// AppRoleTokenTTL is the access token lifetime used when an AppRole's own configured TTL
+// is zero (e.g. a row created before TTL existed, or left unset on purpose).
+const AppRoleTokenTTL = 15 * time.Minute
+
+// AppRoleSecretOverlap is how long a rotated-out SecretID keeps working alongside its
+// replacement when RotateSecret is called with overlap <= 0, giving an already-deployed
+// caller (a CI job mid-run, a service that hasn't picked up its new secret yet) a window
+// to switch over before the old one stops working.
+const AppRoleSecretOverlap = 5 * time.Minute
+
+// AppRoleStore issues and verifies AppRole machine-to-machine credentials, backed by the
+// app_roles table.
+type AppRoleStore struct {
+	db *gorm.DB
+}
+
+// NewAppRoleStore constructs an AppRoleStore backed by db.
+func NewAppRoleStore(db *gorm.DB) *AppRoleStore {
+	return &AppRoleStore{db: db}
+}
+
+// Create mints a brand new AppRole named name with the given scopes and access token
+// TTL, returning the plaintext RoleID/SecretID pair. The SecretID is shown exactly once
+// here and is never recoverable afterward - only rotatable, via RotateSecret.
+func (s *AppRoleStore) Create(name string, scopes []string, ttl time.Duration) (roleID uuid.UUID, secretID string, err error) {
+	secretID, err = newOpaqueToken()
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	role := models.AppRole{
+		RoleID:       uuid.New(),
+		Name:         name,
+		SecretIDHash: hashToken(secretID),
+		Scopes:       strings.Join(scopes, " "),
+		TTL:          ttl,
+	}
+	if err := s.db.Create(&role).Error; err != nil {
+		return uuid.UUID{}, "", err
+	}
+	return role.RoleID, secretID, nil
+}
+
+// Login verifies secretID against roleID's current SecretID hash - or, within its
+// overlap window, the previous one RotateSecret left behind - and returns the matched
+// AppRole on success.
+func (s *AppRoleStore) Login(roleID uuid.UUID, secretID string) (*models.AppRole, error) {
+	var role models.AppRole
+	if err := s.db.Where("role_id = ?", roleID).First(&role).Error; err != nil {
+		return nil, errors.New("unknown role_id")
+	}
+
+	hash := hashToken(secretID)
+	if hash == role.SecretIDHash {
+		return &role, nil
+	}
+
+	if role.PreviousSecretIDHash != "" && hash == role.PreviousSecretIDHash &&
+		role.PreviousSecretIDExpiresAt != nil && time.Now().Before(*role.PreviousSecretIDExpiresAt) {
+		return &role, nil
+	}
+
+	return nil, errors.New("secret_id does not match")
+}
+
+// RotateSecret replaces roleID's SecretID with a newly generated one, keeping the old
+// hash valid for overlap (AppRoleSecretOverlap if overlap <= 0) so already-deployed
+// callers have time to pick up the replacement before it stops working entirely.
+func (s *AppRoleStore) RotateSecret(roleID uuid.UUID, overlap time.Duration) (secretID string, err error) {
+	if overlap <= 0 {
+		overlap = AppRoleSecretOverlap
+	}
+
+	var role models.AppRole
+	if err := s.db.Where("role_id = ?", roleID).First(&role).Error; err != nil {
+		return "", errors.New("unknown role_id")
+	}
+
+	secretID, err = newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(overlap)
+	role.PreviousSecretIDHash = role.SecretIDHash
+	role.PreviousSecretIDExpiresAt = &expiresAt
+	role.SecretIDHash = hashToken(secretID)
+
+	if err := s.db.Save(&role).Error; err != nil {
+		return "", err
+	}
+	return secretID, nil
+}
+
+// GenerateAppRoleJWT mints a short-lived access token for a successful AppRole login. It
+// carries SubTypeAppRole and the role's configured Scopes rather than a Role/RBAC grant,
+// so RequireScope - not RequirePermission/RequireAnyRole - is what gates its access.
+func GenerateAppRoleJWT(role *models.AppRole) (string, error) {
+	claims := NewClaims()
+	claims.SetUserID(role.RoleID.String())
+	claims.SetUsername(role.Name)
+	claims.SetSubType(SubTypeAppRole)
+	claims.SetScope(role.Scopes)
+	claims.SetTwoFactorVerified(true)
+
+	ttl := role.TTL
+	if ttl <= 0 {
+		ttl = AppRoleTokenTTL
+	}
+	return signClaims(claims, ttl)
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// breachCacheTTL is how long a "not found in this range" result is cached, so a burst of
+// signups hashing to the same k-anonymity prefix doesn't refetch it from the API on every
+// request.
+const breachCacheTTL = 24 * time.Hour
+
+// BreachChecker looks up whether a candidate password has appeared in a known breach
+// corpus, so tests can stub it out instead of hitting the real Have I Been Pwned API.
+// Returns the number of times the password was seen in breaches (0 if not breached).
+type BreachChecker interface {
+	Check(password string) (breached bool, count int, err error)
+}
+
+// breachChecker is the BreachChecker ValidatePassword consults, the same package-level-var
+// plus setter pattern as SetDenylistClient/SetTOTPReplayClient: a real implementation by
+// default, swappable in tests via SetBreachChecker.
+var breachChecker BreachChecker = newHIBPBreachChecker()
+
+// SetBreachChecker replaces the BreachChecker ValidatePassword consults. Pass nil to
+// restore the default Have I Been Pwned-backed checker.
+func SetBreachChecker(checker BreachChecker) {
+	if checker == nil {
+		checker = newHIBPBreachChecker()
+	}
+	breachChecker = checker
+}
+
+// hibpBreachChecker implements BreachChecker against the Have I Been Pwned k-anonymity
+// range API: only the first 5 hex chars of the password's SHA-1 digest are ever sent, so
+// the API never sees the full hash, let alone the password itself.
+type hibpBreachChecker struct {
+	client  *http.Client
+	baseURL string
+
+	mu    sync.Mutex
+	cache map[string]time.Time // full SHA-1 hex digest -> cache entry expiry
+}
+
+func newHIBPBreachChecker() *hibpBreachChecker {
+	return &hibpBreachChecker{
+		client:  &http.Client{Timeout: 3 * time.Second},
+		baseURL: "https://api.pwnedpasswords.com",
+		cache:   make(map[string]time.Time),
+	}
+}
+
+// Check implements BreachChecker. A request error (timeout, DNS failure, API outage) fails
+// open - returning breached=false rather than rejecting every signup because a third party
+// is unreachable - the same tradeoff NewRateLimiterWithStore makes for a Redis hiccup.
+func (c *hibpBreachChecker) Check(password string) (bool, int, error) {
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	if c.cachedNegative(digest) {
+		return false, 0, nil
+	}
+
+	resp, err := c.client.Get(fmt.Sprintf("%s/range/%s", c.baseURL, prefix))
+	if err != nil {
+		return false, 0, fmt.Errorf("breach check request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, 0, fmt.Errorf("breach check returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if parts[0] == suffix {
+			count, _ := strconv.Atoi(parts[1])
+			return true, count, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, 0, fmt.Errorf("breach check response read failed: %w", err)
+	}
+
+	c.cacheNegative(digest)
+	return false, 0, nil
+}
+
+func (c *hibpBreachChecker) cachedNegative(digest string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiresAt, ok := c.cache[digest]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expiresAt) {
+		delete(c.cache, digest)
+		return false
+	}
+	return true
+}
+
+func (c *hibpBreachChecker) cacheNegative(digest string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache[digest] = time.Now().Add(breachCacheTTL)
+}
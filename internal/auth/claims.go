@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Data keys for the well-known fields Claims exposes as typed accessors, plus the
+// optional ones callers can set via SetScope/SetDeviceID/SetTenant.
+const (
+	claimKeyUserID            = "user_id"
+	claimKeyUsername          = "username"
+	claimKeyEmail             = "email"
+	claimKeyRole              = "role"
+	claimKeyProvider          = "provider"
+	claimKeyPurpose           = "purpose"
+	claimKeyTwoFactorVerified = "two_factor_verified"
+	claimKeyScope             = "scope"
+	claimKeyDeviceID          = "device_id"
+	claimKeyTenant            = "tenant_id"
+	claimKeySubType           = "sub_type"
+	claimKeySessionID         = "sid"
+)
+
+// registeredClaimKeys are the standard JWT fields handled by the embedded
+// jwt.RegisteredClaims; UnmarshalJSON strips them out of Data so they aren't duplicated.
+var registeredClaimKeys = map[string]bool{
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
+}
+
+// Claims is tundra's JWT payload. The standard fields (exp/iat/jti/...) live on the
+// embedded jwt.RegisteredClaims as usual; everything application-specific - including
+// the UserID/Username/Email/Role fields every tundra token carries - lives in Data, an
+// open map, so callers can attach fields like device_id/scope/tenant_id without the
+// auth package needing to know about them up front. Use NewClaims to build one and the
+// typed accessors below to read and write it; Data itself should be treated as private.
+type Claims struct {
+	Data map[string]any `json:"-"`
+	jwt.RegisteredClaims
+}
+
+// NewClaims returns an empty Claims ready for its typed setters.
+func NewClaims() *Claims {
+	return &Claims{Data: make(map[string]any)}
+}
+
+func (c *Claims) getString(key string) string {
+	v, _ := c.Data[key].(string)
+	return v
+}
+
+func (c *Claims) getBool(key string) bool {
+	v, _ := c.Data[key].(bool)
+	return v
+}
+
+func (c *Claims) set(key string, value any) {
+	if c.Data == nil {
+		c.Data = make(map[string]any)
+	}
+	c.Data[key] = value
+}
+
+// UserID, Username, Email, and Role are first-class typed accessors over Data; every
+// access token tundra issues carries them.
+func (c *Claims) UserID() string   { return c.getString(claimKeyUserID) }
+func (c *Claims) Username() string { return c.getString(claimKeyUsername) }
+func (c *Claims) Email() string    { return c.getString(claimKeyEmail) }
+func (c *Claims) Role() string     { return c.getString(claimKeyRole) }
+
+func (c *Claims) SetUserID(id string)     { c.set(claimKeyUserID, id) }
+func (c *Claims) SetUsername(name string) { c.set(claimKeyUsername, name) }
+func (c *Claims) SetEmail(email string)   { c.set(claimKeyEmail, email) }
+func (c *Claims) SetRole(role string)     { c.set(claimKeyRole, role) }
+
+// Provider is the auth Provider that authenticated this session ("password", "google",
+// "github", ...), carried through so downstream code can audit or branch on how the
+// user signed in without a DB round trip.
+func (c *Claims) Provider() string     { return c.getString(claimKeyProvider) }
+func (c *Claims) SetProvider(p string) { c.set(claimKeyProvider, p) }
+
+// Purpose is empty for ordinary access tokens and PurposeMFAPending for the short-lived
+// token handed back by loginHandler when the account has 2FA enabled.
+func (c *Claims) Purpose() string     { return c.getString(claimKeyPurpose) }
+func (c *Claims) SetPurpose(p string) { c.set(claimKeyPurpose, p) }
+
+// TwoFactorVerified is true once the holder has completed a step-up TOTP/recovery
+// challenge (or never needed one), and is checked by RequireMFA.
+func (c *Claims) TwoFactorVerified() bool     { return c.getBool(claimKeyTwoFactorVerified) }
+func (c *Claims) SetTwoFactorVerified(v bool) { c.set(claimKeyTwoFactorVerified, v) }
+
+// Scope, DeviceID, and Tenant are optional application-specific fields with no behavior
+// of their own in the auth package; pair them (or any other Data key) with RegisterClaim
+// to have ValidateJWT enforce something about their shape.
+func (c *Claims) Scope() string           { return c.getString(claimKeyScope) }
+func (c *Claims) SetScope(scope string)   { c.set(claimKeyScope, scope) }
+func (c *Claims) DeviceID() string        { return c.getString(claimKeyDeviceID) }
+func (c *Claims) SetDeviceID(id string)   { c.set(claimKeyDeviceID, id) }
+func (c *Claims) Tenant() string          { return c.getString(claimKeyTenant) }
+func (c *Claims) SetTenant(tenant string) { c.set(claimKeyTenant, tenant) }
+
+// SubType distinguishes a machine-to-machine AppRole login (SubTypeAppRole) from an
+// ordinary user session token (empty string). AuthMiddleware authenticates either kind
+// identically; callers that must not extend human-admin privileges to a service account
+// (RBAC-aware handlers, audit consumers) can branch on it instead.
+func (c *Claims) SubType() string     { return c.getString(claimKeySubType) }
+func (c *Claims) SetSubType(t string) { c.set(claimKeySubType, t) }
+
+// SessionID is the id of the RefreshStore session (see RefreshStore.Issue/Rotate) this
+// access token was minted alongside, empty for tokens that predate the sessions API or
+// don't carry one (e.g. GenerateMFAPendingToken). ValidateJWT rejects a token whose
+// SessionID names a session revoked via DELETE /auth/sessions/:id, even before the
+// token's own jti is individually denylisted.
+func (c *Claims) SessionID() string      { return c.getString(claimKeySessionID) }
+func (c *Claims) SetSessionID(id string) { c.set(claimKeySessionID, id) }
+
+// MarshalJSON flattens Data alongside the registered claims so a Claims value still
+// serializes as a single-level JWT payload (e.g. {"user_id":"...","exp":...}) instead of
+// nesting application fields under a "data" key.
+func (c Claims) MarshalJSON() ([]byte, error) {
+	registered, err := json.Marshal(c.RegisteredClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]any)
+	if err := json.Unmarshal(registered, &merged); err != nil {
+		return nil, err
+	}
+	for k, v := range c.Data {
+		merged[k] = v
+	}
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON is the inverse of MarshalJSON: standard fields populate RegisteredClaims
+// as usual, and everything else lands in Data.
+func (c *Claims) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &c.RegisteredClaims); err != nil {
+		return err
+	}
+
+	raw := make(map[string]any)
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	for key := range registeredClaimKeys {
+		delete(raw, key)
+	}
+	c.Data = raw
+	return nil
+}
+
+// ClaimValidator checks that a custom Data claim is well-formed. It receives nil if the
+// claim was never set on the token.
+type ClaimValidator func(value any) error
+
+var (
+	claimValidatorsMu sync.Mutex
+	claimValidators   = map[string]ClaimValidator{}
+)
+
+// RegisterClaim plugs a validator for the Data claim named name into ValidateJWT: every
+// successfully parsed token runs validator against claims.Data[name] (nil if the token
+// never set it), and a non-nil return fails validation. Call it during package/server
+// setup, not per request - it replaces any validator previously registered for name.
+func RegisterClaim(name string, validator ClaimValidator) {
+	claimValidatorsMu.Lock()
+	defer claimValidatorsMu.Unlock()
+	claimValidators[name] = validator
+}
+
+// runClaimValidators applies every validator registered via RegisterClaim to claims.
+func runClaimValidators(claims *Claims) error {
+	claimValidatorsMu.Lock()
+	validators := make(map[string]ClaimValidator, len(claimValidators))
+	for name, v := range claimValidators {
+		validators[name] = v
+	}
+	claimValidatorsMu.Unlock()
+
+	for name, validator := range validators {
+		if err := validator(claims.Data[name]); err != nil {
+			return fmt.Errorf("claim %q: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestClaimsTypedAccessorsRoundTrip(t *testing.T) {
+	claims := NewClaims()
+	claims.SetUserID("user-123")
+	claims.SetUsername("alice")
+	claims.SetEmail("alice@example.com")
+	claims.SetRole("admin")
+	claims.SetProvider("google")
+	claims.SetPurpose(PurposeMFAPending)
+	claims.SetTwoFactorVerified(true)
+	claims.SetScope("read:orders")
+	claims.SetDeviceID("device-abc")
+	claims.SetTenant("tenant-xyz")
+
+	if claims.UserID() != "user-123" {
+		t.Errorf("UserID() = %q, want %q", claims.UserID(), "user-123")
+	}
+	if claims.Username() != "alice" {
+		t.Errorf("Username() = %q, want %q", claims.Username(), "alice")
+	}
+	if claims.Email() != "alice@example.com" {
+		t.Errorf("Email() = %q, want %q", claims.Email(), "alice@example.com")
+	}
+	if claims.Role() != "admin" {
+		t.Errorf("Role() = %q, want %q", claims.Role(), "admin")
+	}
+	if claims.Provider() != "google" {
+		t.Errorf("Provider() = %q, want %q", claims.Provider(), "google")
+	}
+	if claims.Purpose() != PurposeMFAPending {
+		t.Errorf("Purpose() = %q, want %q", claims.Purpose(), PurposeMFAPending)
+	}
+	if !claims.TwoFactorVerified() {
+		t.Error("TwoFactorVerified() = false, want true")
+	}
+	if claims.Scope() != "read:orders" {
+		t.Errorf("Scope() = %q, want %q", claims.Scope(), "read:orders")
+	}
+	if claims.DeviceID() != "device-abc" {
+		t.Errorf("DeviceID() = %q, want %q", claims.DeviceID(), "device-abc")
+	}
+	if claims.Tenant() != "tenant-xyz" {
+		t.Errorf("Tenant() = %q, want %q", claims.Tenant(), "tenant-xyz")
+	}
+}
+
+func TestClaimsCustomFieldRoundTripsThroughJWT(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+	defer os.Unsetenv("JWT_SECRET")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
+
+	claims := NewClaims()
+	claims.SetUserID(uuid.New().String())
+	claims.SetUsername("bob")
+	claims.SetScope("read:orders write:orders")
+	claims.SetDeviceID("device-xyz")
+	claims.SetTenant("acme-corp")
+
+	token, err := signClaims(claims, AccessTokenTTL)
+	if err != nil {
+		t.Fatalf("signClaims() error = %v", err)
+	}
+
+	parsed, err := ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+
+	if parsed.Scope() != "read:orders write:orders" {
+		t.Errorf("Scope() = %q, want %q", parsed.Scope(), "read:orders write:orders")
+	}
+	if parsed.DeviceID() != "device-xyz" {
+		t.Errorf("DeviceID() = %q, want %q", parsed.DeviceID(), "device-xyz")
+	}
+	if parsed.Tenant() != "acme-corp" {
+		t.Errorf("Tenant() = %q, want %q", parsed.Tenant(), "acme-corp")
+	}
+
+	// Registered fields must not leak back into the application Data map.
+	if _, ok := parsed.Data["exp"]; ok {
+		t.Error("Data should not contain the registered \"exp\" claim")
+	}
+	if _, ok := parsed.Data["jti"]; ok {
+		t.Error("Data should not contain the registered \"jti\" claim")
+	}
+}
+
+func TestRegisterClaimRejectsInvalidValue(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+	defer os.Unsetenv("JWT_SECRET")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
+
+	RegisterClaim("tenant_id", func(value any) error {
+		if value == nil {
+			return errors.New("tenant_id is required")
+		}
+		return nil
+	})
+	defer func() {
+		claimValidatorsMu.Lock()
+		delete(claimValidators, "tenant_id")
+		claimValidatorsMu.Unlock()
+	}()
+
+	claims := NewClaims()
+	claims.SetUserID(uuid.New().String())
+	token, err := signClaims(claims, AccessTokenTTL)
+	if err != nil {
+		t.Fatalf("signClaims() error = %v", err)
+	}
+
+	if _, err := ValidateJWT(token); err == nil {
+		t.Error("expected ValidateJWT() to reject a token missing tenant_id")
+	}
+
+	claims.SetTenant("acme-corp")
+	token, err = signClaims(claims, AccessTokenTTL)
+	if err != nil {
+		t.Fatalf("signClaims() error = %v", err)
+	}
+
+	if _, err := ValidateJWT(token); err != nil {
+		t.Errorf("expected ValidateJWT() to accept a token with tenant_id set, got %v", err)
+	}
+}
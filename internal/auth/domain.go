@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+)
+
+// domainResolver is the subset of DomainStore's behavior DomainMiddleware depends on, so
+// tests can substitute a stub without a live database.
+type domainResolver interface {
+	MemberRole(domainID, userID uuid.UUID) (string, error)
+}
+
+// domainStore is the process-wide resolver used by DomainMiddleware, wired up once at
+// server startup via SetDomainStore - the same package-level-singleton pattern used for
+// the RBAC store.
+var domainStore domainResolver
+
+// SetDomainStore wires DomainMiddleware to store. Pass nil to disable domain-scoped
+// routes entirely (every DomainMiddleware call then fails closed).
+func SetDomainStore(store domainResolver) {
+	domainStore = store
+}
+
+// DomainStore resolves a user's role within a tenant Domain from the domain_members
+// table - the domain-scoped counterpart to RBACStore, except a domain role is a bare
+// string ("owner", "admin", "member") rather than a bundle of permissions.
+type DomainStore struct {
+	db *gorm.DB
+}
+
+// NewDomainStore constructs a DomainStore backed by db.
+func NewDomainStore(db *gorm.DB) *DomainStore {
+	return &DomainStore{db: db}
+}
+
+// MemberRole returns the role userID holds within domainID, failing if they aren't a
+// member of that domain at all.
+func (s *DomainStore) MemberRole(domainID, userID uuid.UUID) (string, error) {
+	var member models.DomainMember
+	if err := s.db.Where("domain_id = ? AND user_id = ?", domainID, userID).First(&member).Error; err != nil {
+		return "", fmt.Errorf("user %s is not a member of domain %s: %w", userID, domainID, err)
+	}
+	return member.Role, nil
+}
@@ -2,61 +2,133 @@ package auth
 
 import (
 	"errors"
-	"os"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-type Claims struct {
-	UserID uint   `json:"user_id"`
-	Name   string `json:"name"`
-	Email  string `json:"email"`
-	jwt.RegisteredClaims
+// AccessTokenTTL is how long an issued access JWT remains valid.
+const AccessTokenTTL = 15 * time.Minute
+
+// MFAPendingTokenTTL is how long a "mfa_pending" token stays valid while the user
+// completes a TOTP or recovery-code challenge.
+const MFAPendingTokenTTL = 5 * time.Minute
+
+// ProviderPassword marks a JWT issued from the built-in email+password flow, as opposed
+// to an OAuth2/OIDC provider name like "google" or "github".
+const ProviderPassword = "password"
+
+// PurposeMFAPending is the claims.Purpose() value GenerateMFAPendingToken sets, marking a
+// token as only good for completing a pending 2FA challenge - see Authenticate.
+const PurposeMFAPending = "mfa_pending"
+
+// DefaultIssuedAtSkew is the clock-skew window ValidateJWTStrict allows a token's iat
+// claim to drift from the server's current time, in either direction, before rejecting it.
+const DefaultIssuedAtSkew = 60 * time.Second
+
+// Generates a signed, short-lived access JWT from userID, username, email, and role,
+// for a session authenticated via the password provider. Tokens minted here are always
+// fully-verified sessions; use GenerateMFAPendingToken for the intermediate state
+// between password login and a 2FA challenge, or GenerateJWTForProvider for OAuth logins.
+func GenerateJWT(userID uuid.UUID, username, email, role string) (string, error) {
+	return GenerateJWTForProvider(userID, username, email, role, ProviderPassword)
 }
 
-// Generates signed JWTs from userID, name, and email
-func GenerateJWT(userID uint, name, email string) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", errors.New("JWT_SECRET not set")
-	}
+// GenerateJWTForProvider is GenerateJWT with an explicit originating Provider name,
+// used by OAuth2/OIDC providers once they've resolved and linked an identity.
+func GenerateJWTForProvider(userID uuid.UUID, username, email, role, provider string) (string, error) {
+	claims := NewClaims()
+	claims.SetUserID(userID.String())
+	claims.SetUsername(username)
+	claims.SetEmail(email)
+	claims.SetRole(role)
+	claims.SetProvider(provider)
+	claims.SetTwoFactorVerified(true)
+	return signClaims(claims, AccessTokenTTL)
+}
+
+// GenerateJWTWithSession is GenerateJWT plus a sid claim naming the RefreshStore session
+// (see RefreshStore.Issue/Rotate) this access token was minted alongside, so revoking that
+// one session via DELETE /auth/sessions/:id invalidates access tokens already issued for
+// it too, not just future refreshes.
+func GenerateJWTWithSession(userID uuid.UUID, username, email, role string, sessionID uuid.UUID) (string, error) {
+	claims := NewClaims()
+	claims.SetUserID(userID.String())
+	claims.SetUsername(username)
+	claims.SetEmail(email)
+	claims.SetRole(role)
+	claims.SetProvider(ProviderPassword)
+	claims.SetTwoFactorVerified(true)
+	claims.SetSessionID(sessionID.String())
+	return signClaims(claims, AccessTokenTTL)
+}
+
+// GenerateJWTWithScopes is GenerateJWT for a token that additionally carries a
+// space-separated, OAuth2-style scope claim, for callers issuing tokens meant to be
+// checked with RequireScopes rather than (or in addition to) the RBAC middleware.
+func GenerateJWTWithScopes(userID uuid.UUID, username, email, role string, scopes []string) (string, error) {
+	claims := NewClaims()
+	claims.SetUserID(userID.String())
+	claims.SetUsername(username)
+	claims.SetEmail(email)
+	claims.SetRole(role)
+	claims.SetProvider(ProviderPassword)
+	claims.SetTwoFactorVerified(true)
+	claims.SetScope(strings.Join(scopes, " "))
+	return signClaims(claims, AccessTokenTTL)
+}
 
-	expirationTime := time.Now().Add(24 * time.Hour)
-
-	claims := &Claims{
-		UserID: userID,
-		Name:   name,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "tundra",
-		},
+// GenerateMFAPendingToken issues a short-lived token that only authorizes a call to
+// POST /auth/2fa/challenge; it must not be accepted by AuthMiddleware for anything else.
+func GenerateMFAPendingToken(userID uuid.UUID, username, email, role string) (string, error) {
+	claims := NewClaims()
+	claims.SetUserID(userID.String())
+	claims.SetUsername(username)
+	claims.SetEmail(email)
+	claims.SetRole(role)
+	claims.SetPurpose(PurposeMFAPending)
+	return signClaims(claims, MFAPendingTokenTTL)
+}
+
+func signClaims(claims *Claims, ttl time.Duration) (string, error) {
+	ks, err := activeKeyStore()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
+	kid, signingKey, err := ks.ActiveSigningKey()
 	if err != nil {
 		return "", err
 	}
 
-	return tokenString, nil
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    "tundra",
+	}
+
+	token := jwt.NewWithClaims(ks.SigningMethod(), claims)
+	token.Header["kid"] = kid
+	return token.SignedString(signingKey)
 }
 
 //Validates a JWT tokenstring and returns claims and an error if any
 func ValidateJWT(tokenString string) (*Claims, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, errors.New("JWT_SECRET not set")
+	ks, err := activeKeyStore()
+	if err != nil {
+		return nil, err
 	}
 
-	claims := &Claims{}
+	claims := NewClaims()
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
+		kid, _ := token.Header["kid"].(string)
+		return ks.VerificationKey(kid, token.Method)
 	})
 
 	if err != nil {
@@ -67,5 +139,48 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	if claims.ID != "" && isAccessRevoked(claims.ID) {
+		return nil, errors.New("token has been revoked")
+	}
+
+	if sid := claims.SessionID(); sid != "" && isSessionRevoked(sid) {
+		return nil, errors.New("session has been revoked")
+	}
+
+	if err := runClaimValidators(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// ValidateJWTStrict is ValidateJWT plus the freshness checks engine-API-style
+// machine-to-machine tokens need: it rejects claims whose iat is more than skew away
+// from the server's current time in either direction (skew <= 0 uses
+// DefaultIssuedAtSkew), and rejects a token whose nbf is still in the future. Use this
+// instead of ValidateJWT wherever long-lived bearer tokens would be undesirable.
+func ValidateJWTStrict(tokenString string, skew time.Duration) (*Claims, error) {
+	if skew <= 0 {
+		skew = DefaultIssuedAtSkew
+	}
+
+	claims, err := ValidateJWT(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.IssuedAt == nil {
+		return nil, errors.New("token is missing an iat claim")
+	}
+
+	now := time.Now()
+	if iat := claims.IssuedAt.Time; iat.Before(now.Add(-skew)) || iat.After(now.Add(skew)) {
+		return nil, fmt.Errorf("token iat %s is outside the %s clock-skew window", iat, skew)
+	}
+
+	if claims.NotBefore != nil && now.Before(claims.NotBefore.Time) {
+		return nil, errors.New("token is not valid yet")
+	}
+
 	return claims, nil
 }
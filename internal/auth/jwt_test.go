@@ -13,6 +13,8 @@ func TestGenerateJWT(t *testing.T) {
 	// Set up test environment
 	os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
 	defer os.Unsetenv("JWT_SECRET")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
 
 	userID := uuid.New()
 	username := "testuser"
@@ -42,20 +44,20 @@ func TestGenerateJWT(t *testing.T) {
 			t.Fatalf("ValidateJWT() error = %v", err)
 		}
 
-		if claims.UserID != userID.String() {
-			t.Errorf("Expected UserID %v, got %v", userID.String(), claims.UserID)
+		if claims.UserID() != userID.String() {
+			t.Errorf("Expected UserID %v, got %v", userID.String(), claims.UserID())
 		}
 
-		if claims.Username != username {
-			t.Errorf("Expected Username %v, got %v", username, claims.Username)
+		if claims.Username() != username {
+			t.Errorf("Expected Username %v, got %v", username, claims.Username())
 		}
 
-		if claims.Email != email {
-			t.Errorf("Expected Email %v, got %v", email, claims.Email)
+		if claims.Email() != email {
+			t.Errorf("Expected Email %v, got %v", email, claims.Email())
 		}
 
-		if claims.Role != role {
-			t.Errorf("Expected Role %v, got %v", role, claims.Role)
+		if claims.Role() != role {
+			t.Errorf("Expected Role %v, got %v", role, claims.Role())
 		}
 
 		if claims.Issuer != "tundra" {
@@ -87,6 +89,8 @@ func TestGenerateJWT(t *testing.T) {
 	t.Run("Fails without JWT_SECRET", func(t *testing.T) {
 		os.Unsetenv("JWT_SECRET")
 		defer os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+		SetKeyStore(nil)
+		defer SetKeyStore(nil)
 
 		_, err := GenerateJWT(userID, username, email, role)
 		if err == nil {
@@ -98,6 +102,8 @@ func TestGenerateJWT(t *testing.T) {
 func TestValidateJWT(t *testing.T) {
 	os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
 	defer os.Unsetenv("JWT_SECRET")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
 
 	userID := uuid.New()
 	username := "testuser"
@@ -132,6 +138,8 @@ func TestValidateJWT(t *testing.T) {
 		// Change the secret
 		os.Setenv("JWT_SECRET", "different-secret")
 		defer os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+		SetKeyStore(nil)
+		defer SetKeyStore(nil)
 
 		_, err := ValidateJWT(token)
 		if err == nil {
@@ -142,16 +150,15 @@ func TestValidateJWT(t *testing.T) {
 	t.Run("Reject expired token", func(t *testing.T) {
 		// Create an expired token manually
 		secret := os.Getenv("JWT_SECRET")
-		claims := &Claims{
-			UserID:   userID.String(),
-			Username: username,
-			Email:    email,
-			Role:     role,
-			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(time.Now().Add(-1 * time.Hour)),
-				IssuedAt:  jwt.NewNumericDate(time.Now().Add(-25 * time.Hour)),
-				Issuer:    "tundra",
-			},
+		claims := NewClaims()
+		claims.SetUserID(userID.String())
+		claims.SetUsername(username)
+		claims.SetEmail(email)
+		claims.SetRole(role)
+		claims.RegisteredClaims = jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-1 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-25 * time.Hour)),
+			Issuer:    "tundra",
 		}
 
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -167,6 +174,8 @@ func TestValidateJWT(t *testing.T) {
 func TestJWTDoesNotContainSensitiveInfo(t *testing.T) {
 	os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
 	defer os.Unsetenv("JWT_SECRET")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
 
 	userID := uuid.New()
 	username := "testuser"
@@ -186,18 +195,115 @@ func TestJWTDoesNotContainSensitiveInfo(t *testing.T) {
 	t.Run("JWT does not contain password", func(t *testing.T) {
 		// Claims struct should not have a Password field
 		// This is verified by the struct definition in jwt.go
-		if claims.UserID == "" {
+		if claims.UserID() == "" {
 			t.Error("UserID should be present")
 		}
-		if claims.Username == "" {
+		if claims.Username() == "" {
 			t.Error("Username should be present")
 		}
-		if claims.Email == "" {
+		if claims.Email() == "" {
 			t.Error("Email should be present")
 		}
-		if claims.Role == "" {
+		if claims.Role() == "" {
 			t.Error("Role should be present")
 		}
 		// Password field does not exist in Claims struct - this is correct
 	})
 }
+
+func TestValidateJWTStrict(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+	defer os.Unsetenv("JWT_SECRET")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
+
+	secret := os.Getenv("JWT_SECRET")
+	userID := uuid.New()
+
+	// ValidateJWT looks the verification key up by the kid header, so hand-signed tokens
+	// must carry the active key's kid.
+	ks, err := activeKeyStore()
+	if err != nil {
+		t.Fatalf("activeKeyStore() error = %v", err)
+	}
+	kid, _, err := ks.ActiveSigningKey()
+	if err != nil {
+		t.Fatalf("ActiveSigningKey() error = %v", err)
+	}
+
+	signWithIssuedAt := func(iat time.Time) string {
+		claims := NewClaims()
+		claims.SetUserID(userID.String())
+		claims.RegisteredClaims = jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(iat.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(iat),
+			Issuer:    "tundra",
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = kid
+		tokenString, _ := token.SignedString([]byte(secret))
+		return tokenString
+	}
+
+	t.Run("iat within the default skew window passes", func(t *testing.T) {
+		token := signWithIssuedAt(time.Now().Add(-30 * time.Second))
+
+		if _, err := ValidateJWTStrict(token, 0); err != nil {
+			t.Errorf("ValidateJWTStrict() error = %v", err)
+		}
+	})
+
+	t.Run("iat just outside the skew window is rejected", func(t *testing.T) {
+		token := signWithIssuedAt(time.Now().Add(-90 * time.Second))
+
+		if _, err := ValidateJWTStrict(token, 60*time.Second); err == nil {
+			t.Error("expected ValidateJWTStrict() to reject an iat just outside the skew window")
+		}
+	})
+
+	t.Run("iat in the far past is rejected", func(t *testing.T) {
+		token := signWithIssuedAt(time.Now().Add(-24 * time.Hour))
+
+		if _, err := ValidateJWTStrict(token, 60*time.Second); err == nil {
+			t.Error("expected ValidateJWTStrict() to reject an iat in the far past")
+		}
+	})
+
+	t.Run("iat in the far future is rejected", func(t *testing.T) {
+		token := signWithIssuedAt(time.Now().Add(24 * time.Hour))
+
+		if _, err := ValidateJWTStrict(token, 60*time.Second); err == nil {
+			t.Error("expected ValidateJWTStrict() to reject an iat in the far future")
+		}
+	})
+
+	t.Run("nbf in the future is rejected", func(t *testing.T) {
+		now := time.Now()
+		claims := NewClaims()
+		claims.SetUserID(userID.String())
+		claims.RegisteredClaims = jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now.Add(time.Hour)),
+			Issuer:    "tundra",
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = kid
+		tokenString, _ := token.SignedString([]byte(secret))
+
+		if _, err := ValidateJWTStrict(tokenString, 60*time.Second); err == nil {
+			t.Error("expected ValidateJWTStrict() to reject a token not yet valid per nbf")
+		}
+	})
+
+	t.Run("GenerateJWT populates iat and nbf for ValidateJWTStrict", func(t *testing.T) {
+		token, err := GenerateJWT(userID, "testuser", "test@example.com", "user")
+		if err != nil {
+			t.Fatalf("GenerateJWT() error = %v", err)
+		}
+
+		if _, err := ValidateJWTStrict(token, 0); err != nil {
+			t.Errorf("ValidateJWTStrict() error = %v", err)
+		}
+	})
+}
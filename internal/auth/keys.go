@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Supported values for the JWT_ALG env var.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// jwtKey is one signing/verification keypair tracked by a KeyStore, identified by kid.
+// HS256 keys have no public half: priv holds the shared secret and pub is nil.
+type jwtKey struct {
+	kid  string
+	alg  string
+	priv interface{} // *rsa.PrivateKey, *ecdsa.PrivateKey, or []byte secret (HS256)
+	pub  interface{} // *rsa.PublicKey, *ecdsa.PublicKey, or nil (HS256)
+}
+
+// KeyStore holds every signing key a running instance knows about, keyed by kid. New
+// tokens are always signed by the active key; ValidateJWT looks the verification key up
+// by the token's kid header, so a retired key keeps validating existing tokens until
+// RemoveKey drops it - the basis for zero-downtime rotation.
+type KeyStore struct {
+	mu        sync.RWMutex
+	alg       string
+	keys      map[string]*jwtKey
+	activeKid string
+}
+
+// NewKeyStore creates a KeyStore that signs and verifies with alg (AlgHS256, AlgRS256, or
+// AlgES256) and generates its first key.
+func NewKeyStore(alg string) (*KeyStore, error) {
+	ks := &KeyStore{alg: alg, keys: make(map[string]*jwtKey)}
+	if _, err := ks.Rotate(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Rotate generates a new active signing key and returns its kid. Every previously active
+// key stays in the store for verification until RemoveKey retires it.
+func (ks *KeyStore) Rotate() (string, error) {
+	key, err := newJWTKey(ks.alg)
+	if err != nil {
+		return "", err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[key.kid] = key
+	ks.activeKid = key.kid
+	return key.kid, nil
+}
+
+// RemoveKey retires kid: it can no longer sign new tokens or verify existing ones.
+func (ks *KeyStore) RemoveKey(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if kid == ks.activeKid {
+		ks.activeKid = ""
+	}
+	delete(ks.keys, kid)
+}
+
+func newJWTKey(alg string) (*jwtKey, error) {
+	kid := uuid.NewString()
+	switch alg {
+	case AlgHS256:
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return nil, errors.New("JWT_SECRET not set")
+		}
+		return &jwtKey{kid: kid, alg: alg, priv: []byte(secret)}, nil
+	case AlgRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		return &jwtKey{kid: kid, alg: alg, priv: priv, pub: &priv.PublicKey}, nil
+	case AlgES256:
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &jwtKey{kid: kid, alg: alg, priv: priv, pub: &priv.PublicKey}, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALG %q", alg)
+	}
+}
+
+// SigningMethod returns the jwt-go signing method for this store's algorithm.
+func (ks *KeyStore) SigningMethod() jwt.SigningMethod {
+	switch ks.alg {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgES256:
+		return jwt.SigningMethodES256
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// ActiveSigningKey returns the kid and key material signClaims should sign new tokens
+// with.
+func (ks *KeyStore) ActiveSigningKey() (kid string, key interface{}, err error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	k, ok := ks.keys[ks.activeKid]
+	if !ok {
+		return "", nil, errors.New("no active signing key")
+	}
+	return k.kid, k.priv, nil
+}
+
+// VerificationKey looks up the key that signed a token bearing kid, failing if kid is
+// unknown (never issued, or since retired by RemoveKey) or doesn't match method.
+func (ks *KeyStore) VerificationKey(kid string, method jwt.SigningMethod) (interface{}, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if k.alg != method.Alg() {
+		return nil, fmt.Errorf("key %q is not valid for alg %q", kid, method.Alg())
+	}
+	if k.pub != nil {
+		return k.pub, nil
+	}
+	return k.priv, nil // HS256: the verification key is the signing secret itself.
+}
+
+// jwk is one entry of a JSON Web Key Set response, RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+func base64URLEncodeBytes(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func base64URLEncodeBigInt(n *big.Int) string {
+	return base64URLEncodeBytes(n.Bytes())
+}
+
+// JWKS renders every asymmetric public key this store knows about as a JSON Web Key Set.
+// HS256 keys are symmetric secrets and are deliberately never published.
+func (ks *KeyStore) JWKS() jwks {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := jwks{Keys: []jwk{}}
+	for _, k := range ks.keys {
+		switch pub := k.pub.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, jwk{
+				Kty: "RSA",
+				Kid: k.kid,
+				Use: "sig",
+				Alg: k.alg,
+				N:   base64URLEncodeBigInt(pub.N),
+				E:   base64URLEncodeBigInt(big.NewInt(int64(pub.E))),
+			})
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			set.Keys = append(set.Keys, jwk{
+				Kty: "EC",
+				Kid: k.kid,
+				Use: "sig",
+				Alg: k.alg,
+				Crv: "P-256",
+				X:   base64URLEncodeBytes(pub.X.FillBytes(make([]byte, size))),
+				Y:   base64URLEncodeBytes(pub.Y.FillBytes(make([]byte, size))),
+			})
+		}
+	}
+	return set
+}
+
+var (
+	keyStoreMu sync.Mutex
+	keyStore   *KeyStore
+)
+
+// SetKeyStore overrides the package-wide KeyStore consulted by GenerateJWT, ValidateJWT,
+// and JWKSHandler. Passing nil resets it so the next call rebuilds one from JWT_ALG.
+func SetKeyStore(ks *KeyStore) {
+	keyStoreMu.Lock()
+	defer keyStoreMu.Unlock()
+	keyStore = ks
+}
+
+// activeKeyStore returns the package-wide KeyStore, building it from JWT_ALG (default
+// AlgHS256) the first time it's needed.
+func activeKeyStore() (*KeyStore, error) {
+	keyStoreMu.Lock()
+	defer keyStoreMu.Unlock()
+	if keyStore != nil {
+		return keyStore, nil
+	}
+
+	alg := os.Getenv("JWT_ALG")
+	if alg == "" {
+		alg = AlgHS256
+	}
+	ks, err := NewKeyStore(alg)
+	if err != nil {
+		return nil, err
+	}
+	keyStore = ks
+	return keyStore, nil
+}
+
+// JWKSHandler serves the active KeyStore's public keys at GET /.well-known/jwks.json so
+// that verifiers outside this process can validate RS256/ES256 tokens without the
+// signing key ever leaving the process that minted them.
+func JWKSHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ks, err := activeKeyStore()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "key store unavailable"})
+			return
+		}
+		c.JSON(http.StatusOK, ks.JWKS())
+	}
+}
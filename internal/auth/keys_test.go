@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestKeyStoreRotationKeepsOldKidValidUntilRemoved(t *testing.T) {
+	ks, err := NewKeyStore(AlgRS256)
+	if err != nil {
+		t.Fatalf("NewKeyStore() error = %v", err)
+	}
+
+	oldKid, oldKey, err := ks.ActiveSigningKey()
+	if err != nil {
+		t.Fatalf("ActiveSigningKey() error = %v", err)
+	}
+
+	newKid, err := ks.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if newKid == oldKid {
+		t.Fatal("expected Rotate() to produce a new kid")
+	}
+
+	activeKid, _, err := ks.ActiveSigningKey()
+	if err != nil {
+		t.Fatalf("ActiveSigningKey() error = %v", err)
+	}
+	if activeKid != newKid {
+		t.Errorf("expected active kid %q after rotation, got %q", newKid, activeKid)
+	}
+
+	// The old key must still verify tokens signed under it before rotation.
+	if _, err := ks.VerificationKey(oldKid, jwt.SigningMethodRS256); err != nil {
+		t.Errorf("expected the old kid to still verify after rotation, got %v", err)
+	}
+	if oldKey == nil {
+		t.Fatal("expected the old signing key material to be non-nil")
+	}
+
+	ks.RemoveKey(oldKid)
+	if _, err := ks.VerificationKey(oldKid, jwt.SigningMethodRS256); err == nil {
+		t.Error("expected the removed kid to no longer verify")
+	}
+	if _, err := ks.VerificationKey(newKid, jwt.SigningMethodRS256); err != nil {
+		t.Errorf("expected the still-active kid to keep verifying, got %v", err)
+	}
+}
+
+func TestKeyStoreSignAndVerifyRoundTrip(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgES256} {
+		t.Run(alg, func(t *testing.T) {
+			SetKeyStore(nil)
+			defer SetKeyStore(nil)
+
+			ks, err := NewKeyStore(alg)
+			if err != nil {
+				t.Fatalf("NewKeyStore(%s) error = %v", alg, err)
+			}
+			SetKeyStore(ks)
+
+			token, err := GenerateJWT(uuid.New(), "testuser", "test@example.com", "user")
+			if err != nil {
+				t.Fatalf("GenerateJWT() error = %v", err)
+			}
+
+			claims, err := ValidateJWT(token)
+			if err != nil {
+				t.Fatalf("ValidateJWT() error = %v", err)
+			}
+			if claims.Username() != "testuser" {
+				t.Errorf("expected username %q, got %q", "testuser", claims.Username())
+			}
+		})
+	}
+}
+
+func TestKeyStoreJWKSPublishesOnlyAsymmetricKeys(t *testing.T) {
+	rsaStore, err := NewKeyStore(AlgRS256)
+	if err != nil {
+		t.Fatalf("NewKeyStore(RS256) error = %v", err)
+	}
+	set := rsaStore.JWKS()
+	if len(set.Keys) != 1 {
+		t.Fatalf("expected exactly one published key, got %d", len(set.Keys))
+	}
+	if set.Keys[0].Kty != "RSA" || set.Keys[0].N == "" || set.Keys[0].E == "" {
+		t.Errorf("expected a populated RSA JWK, got %+v", set.Keys[0])
+	}
+
+	esStore, err := NewKeyStore(AlgES256)
+	if err != nil {
+		t.Fatalf("NewKeyStore(ES256) error = %v", err)
+	}
+	set = esStore.JWKS()
+	if len(set.Keys) != 1 {
+		t.Fatalf("expected exactly one published key, got %d", len(set.Keys))
+	}
+	if set.Keys[0].Kty != "EC" || set.Keys[0].Crv != "P-256" || set.Keys[0].X == "" || set.Keys[0].Y == "" {
+		t.Errorf("expected a populated EC JWK, got %+v", set.Keys[0])
+	}
+
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+	hsStore, err := NewKeyStore(AlgHS256)
+	if err != nil {
+		t.Fatalf("NewKeyStore(HS256) error = %v", err)
+	}
+	if set := hsStore.JWKS(); len(set.Keys) != 0 {
+		t.Errorf("expected HS256 secrets to never be published to the JWKS, got %+v", set.Keys)
+	}
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginRateLimitConfig configures LoginRateLimiter's thresholds. Unlike ratelimit.AuthLimiter
+// (a flat per-IP request cap shared by every auth route), these limits count only failed
+// login attempts and are scoped per email in addition to per IP, so one leaked password
+// can't be brute-forced without also slowing down everyone else behind the same IP.
+type LoginRateLimitConfig struct {
+	// Window is how long a failure counter survives before resetting.
+	Window time.Duration
+	// MaxFailuresPerEmail locks the account once exceeded.
+	MaxFailuresPerEmail int
+	// MaxFailuresPerIP throttles the source IP once exceeded, independent of which email(s)
+	// it's been tried against.
+	MaxFailuresPerIP int
+	// LockoutDuration is how long an account stays locked after MaxFailuresPerEmail is hit.
+	LockoutDuration time.Duration
+}
+
+// DefaultLoginRateLimitConfig returns the thresholds loginHandler is wired with.
+func DefaultLoginRateLimitConfig() LoginRateLimitConfig {
+	return LoginRateLimitConfig{
+		Window:              15 * time.Minute,
+		MaxFailuresPerEmail: 5,
+		MaxFailuresPerIP:    20,
+		LockoutDuration:     30 * time.Minute,
+	}
+}
+
+// LoginRateLimiter throttles POST /auth/login by IP and by the email in the request body,
+// and locks an account out after too many consecutive failures against it. It must run
+// immediately in front of the login handler, since it inspects that handler's response
+// status to decide whether the attempt counted as a failure.
+//
+// It degrades to a no-op when rdb is nil, the same "Redis unavailable" fallback used
+// elsewhere (see SetDenylistClient) - losing shared brute-force tracking across replicas is
+// preferable to failing login outright.
+func LoginRateLimiter(rdb *redis.Client, cfg LoginRateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rdb == nil {
+			c.Next()
+			return
+		}
+
+		var body struct {
+			Email string `json:"email"`
+		}
+		_ = c.ShouldBindBodyWith(&body, binding.JSON)
+
+		ctx := c.Request.Context()
+		ip := c.ClientIP()
+		ipKey := "login:ip:" + ip
+		var emailKey, lockKey string
+		if body.Email != "" {
+			emailKey = "login:email:" + body.Email
+			lockKey = "locked:" + body.Email
+		}
+
+		if lockKey != "" {
+			if ttl, err := rdb.TTL(ctx, lockKey).Result(); err == nil && ttl > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(ttl.Seconds())))
+				c.JSON(http.StatusLocked, gin.H{"error": "Account temporarily locked due to too many failed login attempts"})
+				c.Abort()
+				return
+			}
+		}
+
+		if blocked, ttl := overThreshold(ctx, rdb, ipKey, cfg.MaxFailuresPerIP); blocked {
+			c.Header("Retry-After", strconv.Itoa(int(ttl.Seconds())))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many login attempts from this IP address"})
+			c.Abort()
+			return
+		}
+
+		if emailKey != "" {
+			if blocked, ttl := overThreshold(ctx, rdb, emailKey, cfg.MaxFailuresPerEmail); blocked {
+				c.Header("Retry-After", strconv.Itoa(int(ttl.Seconds())))
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many login attempts for this account"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+
+		switch c.Writer.Status() {
+		case http.StatusUnauthorized:
+			incrWithWindow(ctx, rdb, ipKey, cfg.Window)
+			if emailKey != "" {
+				count := incrWithWindow(ctx, rdb, emailKey, cfg.Window)
+				if count >= int64(cfg.MaxFailuresPerEmail) {
+					rdb.Set(ctx, lockKey, "1", cfg.LockoutDuration)
+				}
+			}
+		case http.StatusOK:
+			if emailKey != "" {
+				rdb.Del(ctx, emailKey)
+			}
+		}
+	}
+}
+
+// overThreshold reports whether key's current count has already reached max, along with the
+// remaining TTL on the counter (used for the Retry-After header).
+func overThreshold(ctx context.Context, rdb *redis.Client, key string, max int) (bool, time.Duration) {
+	count, err := rdb.Get(ctx, key).Int()
+	if err != nil {
+		return false, 0
+	}
+	if count < max {
+		return false, 0
+	}
+	ttl, _ := rdb.TTL(ctx, key).Result()
+	return true, ttl
+}
+
+// incrWithWindow increments key and, only on the first increment, sets it to expire after
+// window - later increments extend the count without resetting the window, giving a fixed
+// rather than sliding expiry per window.
+func incrWithWindow(ctx context.Context, rdb *redis.Client, key string, window time.Duration) int64 {
+	count, err := rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0
+	}
+	if count == 1 {
+		rdb.Expire(ctx, key, window)
+	}
+	return count
+}
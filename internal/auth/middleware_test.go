@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,6 +14,8 @@ import (
 func TestAuthMiddleware(t *testing.T) {
 	os.Setenv("JWT_SECRET", "test-secret-key")
 	defer os.Unsetenv("JWT_SECRET")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
 
 	gin.SetMode(gin.TestMode)
 
@@ -81,14 +84,39 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 }
 
+// stubRBACResolver is a test double for rbacResolver, avoiding a live database in this
+// package's tests.
+type stubRBACResolver struct {
+	roles       []string
+	permissions []string
+	err         error
+}
+
+func (s *stubRBACResolver) EffectivePermissions(jti string, userID uuid.UUID) ([]string, []string, error) {
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	return s.roles, s.permissions, nil
+}
+
+func withClaims(c *gin.Context, roleHint string) {
+	claims := NewClaims()
+	claims.SetUserID(uuid.New().String())
+	claims.SetRole(roleHint)
+	c.Set(ClaimsContextKey, claims)
+}
+
 func TestAdminMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	defer SetRBACStore(nil)
 
 	t.Run("Admin role passes authorization", func(t *testing.T) {
+		SetRBACStore(&stubRBACResolver{roles: []string{"admin"}})
+
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("GET", "/", nil)
-		c.Set("role", "admin")
+		withClaims(c, "admin")
 
 		AdminMiddleware()(c)
 
@@ -98,10 +126,12 @@ func TestAdminMiddleware(t *testing.T) {
 	})
 
 	t.Run("User role returns 403", func(t *testing.T) {
+		SetRBACStore(&stubRBACResolver{roles: []string{"user"}})
+
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("GET", "/", nil)
-		c.Set("role", "user")
+		withClaims(c, "user")
 
 		AdminMiddleware()(c)
 
@@ -110,7 +140,9 @@ func TestAdminMiddleware(t *testing.T) {
 		}
 	})
 
-	t.Run("Missing role returns 401", func(t *testing.T) {
+	t.Run("Missing claims returns 401", func(t *testing.T) {
+		SetRBACStore(&stubRBACResolver{roles: []string{"admin"}})
+
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("GET", "/", nil)
@@ -122,3 +154,155 @@ func TestAdminMiddleware(t *testing.T) {
 		}
 	})
 }
+
+func TestRequireScopes(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret-key")
+	defer os.Unsetenv("JWT_SECRET")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Missing Authorization header returns 401", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		RequireScopes("orders:read")(c)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("Malformed Bearer prefix returns 401", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Token something")
+
+		RequireScopes("orders:read")(c)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("Expired token returns 401", func(t *testing.T) {
+		userID := uuid.New()
+		claims := NewClaims()
+		claims.SetUserID(userID.String())
+		claims.SetScope("orders:read")
+		token, err := signClaims(claims, -time.Minute)
+		if err != nil {
+			t.Fatalf("signClaims() error = %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+
+		RequireScopes("orders:read")(c)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("Insufficient scopes returns 403", func(t *testing.T) {
+		userID := uuid.New()
+		token, err := GenerateJWTWithScopes(userID, "testuser", "test@example.com", "user", []string{"orders:read"})
+		if err != nil {
+			t.Fatalf("GenerateJWTWithScopes() error = %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+
+		RequireScopes("orders:read", "orders:write")(c)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("Sufficient scopes passes and stashes claims", func(t *testing.T) {
+		userID := uuid.New()
+		token, err := GenerateJWTWithScopes(userID, "testuser", "test@example.com", "user", []string{"orders:read", "orders:write"})
+		if err != nil {
+			t.Fatalf("GenerateJWTWithScopes() error = %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+
+		RequireScopes("orders:read")(c)
+
+		if c.IsAborted() {
+			t.Error("Expected middleware to not abort when all scopes are granted")
+		}
+
+		claims, ok := ClaimsFromContext(c)
+		if !ok {
+			t.Fatal("Expected claims to be stashed in context")
+		}
+		if claims.UserID() != userID.String() {
+			t.Errorf("Expected UserID %s, got %s", userID.String(), claims.UserID())
+		}
+	})
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer SetRBACStore(nil)
+
+	t.Run("Holding the permission passes", func(t *testing.T) {
+		SetRBACStore(&stubRBACResolver{permissions: []string{"products:write"}})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		withClaims(c, "user")
+
+		RequirePermission("products:write")(c)
+
+		if c.IsAborted() {
+			t.Error("Expected middleware to not abort when permission is granted")
+		}
+	})
+
+	t.Run("Missing the permission returns 403", func(t *testing.T) {
+		SetRBACStore(&stubRBACResolver{permissions: []string{"products:read"}})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		withClaims(c, "user")
+
+		RequirePermission("products:write")(c)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("Unconfigured RBAC store returns 500", func(t *testing.T) {
+		SetRBACStore(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		withClaims(c, "user")
+
+		RequirePermission("products:write")(c)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+}
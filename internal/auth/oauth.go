@@ -0,0 +1,503 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+)
+
+// pkceCookieTTL bounds how long a user has to complete the redirect round trip before
+// the signed state/code_verifier cookie set by LoginHandler expires.
+const pkceCookieTTL = 10 * time.Minute
+
+// pkceCookiePrefix namespaces the per-provider cookie so two providers can be mid-flow
+// at once without clobbering each other's state.
+const pkceCookiePrefix = "oauth_pkce_"
+
+// OAuthConfig configures one OAuth2/OIDC Provider. AuthURL/TokenURL/UserInfoURL can
+// either be set directly (Google, GitHub) or left blank alongside DiscoveryURL to be
+// resolved from the issuer's /.well-known/openid-configuration document.
+type OAuthConfig struct {
+	ProviderName  string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	AuthURL       string
+	TokenURL      string
+	UserInfoURL   string
+	DiscoveryURL  string
+	Scopes        []string
+	UserInfoMapFn func(raw map[string]any) (*ProviderUser, error)
+}
+
+// OAuthProvider implements Provider for a single OAuth2/OIDC issuer using an
+// authorization-code flow with PKCE. The code_verifier and an anti-CSRF state value are
+// stashed in a short-lived HMAC-signed cookie between LoginHandler and CallbackHandler.
+type OAuthProvider struct {
+	cfg OAuthConfig
+	db  *gorm.DB
+}
+
+// NewOAuthProvider builds a Provider from cfg, resolving AuthURL/TokenURL/UserInfoURL
+// via OIDC discovery first if cfg.DiscoveryURL is set and the explicit URLs are blank.
+func NewOAuthProvider(cfg OAuthConfig, db *gorm.DB) (*OAuthProvider, error) {
+	if cfg.DiscoveryURL != "" && (cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "") {
+		discovered, err := discoverOIDCEndpoints(cfg.DiscoveryURL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: OIDC discovery failed: %w", cfg.ProviderName, err)
+		}
+		if cfg.AuthURL == "" {
+			cfg.AuthURL = discovered.AuthURL
+		}
+		if cfg.TokenURL == "" {
+			cfg.TokenURL = discovered.TokenURL
+		}
+		if cfg.UserInfoURL == "" {
+			cfg.UserInfoURL = discovered.UserInfoURL
+		}
+	}
+
+	if cfg.UserInfoMapFn == nil {
+		cfg.UserInfoMapFn = defaultUserInfoMapFn
+	}
+
+	return &OAuthProvider{cfg: cfg, db: db}, nil
+}
+
+func (p *OAuthProvider) Name() string { return p.cfg.ProviderName }
+
+// LoginHandler generates a PKCE code_verifier/challenge and anti-CSRF state, stores
+// them in a signed cookie, and redirects the browser to the provider's authorize URL.
+func (p *OAuthProvider) LoginHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state, err := randomURLSafeString(24)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+		verifier, err := randomURLSafeString(48)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+		challenge := codeChallengeS256(verifier)
+
+		signed, err := signPKCECookie(state, verifier)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+		c.SetCookie(pkceCookiePrefix+p.cfg.ProviderName, signed, int(pkceCookieTTL.Seconds()), "/", "", false, true)
+
+		query := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {p.cfg.ClientID},
+			"redirect_uri":          {p.cfg.RedirectURL},
+			"state":                 {state},
+			"code_challenge":        {challenge},
+			"code_challenge_method": {"S256"},
+		}
+		if len(p.cfg.Scopes) > 0 {
+			query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+		}
+
+		c.Redirect(http.StatusFound, p.cfg.AuthURL+"?"+query.Encode())
+	}
+}
+
+// CallbackHandler validates the returned state against the PKCE cookie, exchanges the
+// authorization code, fetches userinfo, and links or creates a local user.
+func (p *OAuthProvider) CallbackHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := c.Query("code")
+		state := c.Query("state")
+		if code == "" || state == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing code or state"})
+			return
+		}
+
+		cookie, err := c.Cookie(pkceCookiePrefix + p.cfg.ProviderName)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or expired login session"})
+			return
+		}
+		cookieState, verifier, err := verifyPKCECookie(cookie)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login session"})
+			return
+		}
+		if !hmac.Equal([]byte(cookieState), []byte(state)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "State mismatch"})
+			return
+		}
+		c.SetCookie(pkceCookiePrefix+p.cfg.ProviderName, "", -1, "/", "", false, true)
+
+		identity, err := p.Authenticate(c.Request.Context(), map[string]string{
+			"code":          code,
+			"code_verifier": verifier,
+		})
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := p.findOrCreateLinkedUser(c.Request.Context(), identity)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		token, err := GenerateJWTForProvider(user.ID, user.Username, user.Email, user.Role, p.cfg.ProviderName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate authentication token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"token": token})
+	}
+}
+
+// Authenticate exchanges creds["code"]/creds["code_verifier"] for an access token and
+// fetches the provider's userinfo endpoint with it.
+func (p *OAuthProvider) Authenticate(ctx context.Context, creds map[string]string) (*ProviderUser, error) {
+	accessToken, err := p.exchangeCode(ctx, creds["code"], creds["code_verifier"])
+	if err != nil {
+		return nil, err
+	}
+	return p.fetchUserInfo(ctx, accessToken)
+}
+
+func (p *OAuthProvider) exchangeCode(ctx context.Context, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token exchange request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token exchange failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return "", errors.New("token response did not include an access_token")
+	}
+
+	return payload.AccessToken, nil
+}
+
+func (p *OAuthProvider) fetchUserInfo(ctx context.Context, accessToken string) (*ProviderUser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.UserInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("userinfo request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read userinfo response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse userinfo response: %w", err)
+	}
+
+	return p.cfg.UserInfoMapFn(raw)
+}
+
+// findOrCreateLinkedUser links identity to an existing LinkedAccount if one is already
+// recorded for this provider; otherwise it links by verified email if a user with that
+// email exists, or creates a new user, then records the LinkedAccount either way.
+func (p *OAuthProvider) findOrCreateLinkedUser(ctx context.Context, identity *ProviderUser) (*models.User, error) {
+	db := p.db.WithContext(ctx)
+
+	var link models.LinkedAccount
+	err := db.Where("provider = ? AND provider_user_id = ?", p.cfg.ProviderName, identity.ExternalID).First(&link).Error
+	if err == nil {
+		var user models.User
+		if err := db.First(&user, "id = ?", link.UserID).Error; err != nil {
+			return nil, fmt.Errorf("linked account points at a missing user: %w", err)
+		}
+		return &user, nil
+	}
+
+	var user models.User
+	if identity.EmailVerified && identity.Email != "" {
+		if err := db.Where("email = ?", identity.Email).First(&user).Error; err != nil {
+			user = models.User{}
+		}
+	}
+
+	if user.ID == (models.User{}).ID {
+		user = models.User{
+			Username: uniqueUsernameSuggestion(identity),
+			Email:    identity.Email,
+			Role:     "user",
+		}
+		if err := db.Create(&user).Error; err != nil {
+			return nil, fmt.Errorf("failed to create user for %s login: %w", p.cfg.ProviderName, err)
+		}
+	}
+
+	link = models.LinkedAccount{
+		UserID:         user.ID,
+		Provider:       p.cfg.ProviderName,
+		ProviderUserID: identity.ExternalID,
+		AvatarURL:      identity.AvatarURL,
+	}
+	if err := db.Create(&link).Error; err != nil {
+		return nil, fmt.Errorf("failed to link %s account: %w", p.cfg.ProviderName, err)
+	}
+
+	return &user, nil
+}
+
+func uniqueUsernameSuggestion(identity *ProviderUser) string {
+	if identity.Username != "" {
+		return identity.Username
+	}
+	if at := strings.IndexByte(identity.Email, '@'); at > 0 {
+		return identity.Email[:at]
+	}
+	return identity.ExternalID
+}
+
+// defaultUserInfoMapFn understands the common OIDC userinfo shape ("sub", "email",
+// "email_verified", "name"/"preferred_username"). Google and a generic OIDC issuer both
+// fit this; GitHub gets its own mapper since it predates OIDC.
+func defaultUserInfoMapFn(raw map[string]any) (*ProviderUser, error) {
+	sub, _ := raw["sub"].(string)
+	if sub == "" {
+		return nil, errors.New("userinfo response missing 'sub'")
+	}
+
+	email, _ := raw["email"].(string)
+	emailVerified, _ := raw["email_verified"].(bool)
+
+	username, _ := raw["preferred_username"].(string)
+	if username == "" {
+		username, _ = raw["name"].(string)
+	}
+
+	avatarURL, _ := raw["picture"].(string)
+
+	return &ProviderUser{
+		Email:         email,
+		EmailVerified: emailVerified,
+		Username:      username,
+		ExternalID:    sub,
+		AvatarURL:     avatarURL,
+	}, nil
+}
+
+// githubUserInfoMapFn maps GitHub's non-OIDC /user response.
+func githubUserInfoMapFn(raw map[string]any) (*ProviderUser, error) {
+	id, ok := raw["id"].(float64)
+	if !ok {
+		return nil, errors.New("userinfo response missing 'id'")
+	}
+
+	email, _ := raw["email"].(string)
+	login, _ := raw["login"].(string)
+	avatarURL, _ := raw["avatar_url"].(string)
+
+	return &ProviderUser{
+		Email:         email,
+		EmailVerified: email != "", // GitHub only returns a public email if one is set
+		Username:      login,
+		ExternalID:    strconv.FormatInt(int64(id), 10),
+		AvatarURL:     avatarURL,
+	}, nil
+}
+
+type oidcDiscoveryDocument struct {
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+}
+
+func discoverOIDCEndpoints(discoveryURL string) (*oidcDiscoveryDocument, error) {
+	resp, err := http.Get(discoveryURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery document request failed with status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return &oidcDiscoveryDocument{
+		AuthURL:     doc.AuthorizationEndpoint,
+		TokenURL:    doc.TokenEndpoint,
+		UserInfoURL: doc.UserinfoEndpoint,
+	}, nil
+}
+
+// NewGoogleProvider configures an OAuthProvider for Google from GOOGLE_CLIENT_ID,
+// GOOGLE_CLIENT_SECRET, and GOOGLE_REDIRECT_URL.
+func NewGoogleProvider(db *gorm.DB) (*OAuthProvider, error) {
+	return NewOAuthProvider(OAuthConfig{
+		ProviderName: "google",
+		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:       []string{"openid", "email", "profile"},
+	}, db)
+}
+
+// NewGitHubProvider configures an OAuthProvider for GitHub from GITHUB_CLIENT_ID,
+// GITHUB_CLIENT_SECRET, and GITHUB_REDIRECT_URL.
+func NewGitHubProvider(db *gorm.DB) (*OAuthProvider, error) {
+	return NewOAuthProvider(OAuthConfig{
+		ProviderName:  "github",
+		ClientID:      os.Getenv("GITHUB_CLIENT_ID"),
+		ClientSecret:  os.Getenv("GITHUB_CLIENT_SECRET"),
+		RedirectURL:   os.Getenv("GITHUB_REDIRECT_URL"),
+		AuthURL:       "https://github.com/login/oauth/authorize",
+		TokenURL:      "https://github.com/login/oauth/access_token",
+		UserInfoURL:   "https://api.github.com/user",
+		Scopes:        []string{"read:user", "user:email"},
+		UserInfoMapFn: githubUserInfoMapFn,
+	}, db)
+}
+
+// NewOIDCProvider configures a generic OIDC Provider named name from
+// {NAME}_OIDC_DISCOVERY_URL, {NAME}_CLIENT_ID, {NAME}_CLIENT_SECRET, and
+// {NAME}_REDIRECT_URL, e.g. a local Dex instance for development.
+func NewOIDCProvider(name string, db *gorm.DB) (*OAuthProvider, error) {
+	envPrefix := strings.ToUpper(name) + "_"
+	return NewOAuthProvider(OAuthConfig{
+		ProviderName: name,
+		ClientID:     os.Getenv(envPrefix + "CLIENT_ID"),
+		ClientSecret: os.Getenv(envPrefix + "CLIENT_SECRET"),
+		RedirectURL:  os.Getenv(envPrefix + "REDIRECT_URL"),
+		DiscoveryURL: os.Getenv(envPrefix + "OIDC_DISCOVERY_URL"),
+		Scopes:       []string{"openid", "email", "profile"},
+	}, db)
+}
+
+func randomURLSafeString(nBytes int) (string, error) {
+	buf := make([]byte, nBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// signPKCECookie packs state and verifier into "state.verifier.signature", HMAC-signed
+// with JWT_SECRET so a tampered or forged cookie is rejected by verifyPKCECookie.
+func signPKCECookie(state, verifier string) (string, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", errors.New("JWT_SECRET not set")
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(state)) + "." + base64.RawURLEncoding.EncodeToString([]byte(verifier))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	return payload + "." + signature, nil
+}
+
+func verifyPKCECookie(cookie string) (state, verifier string, err error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", "", errors.New("JWT_SECRET not set")
+	}
+
+	parts := strings.Split(cookie, ".")
+	if len(parts) != 3 {
+		return "", "", errors.New("malformed PKCE cookie")
+	}
+
+	payload := parts[0] + "." + parts[1]
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	expectedSignature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expectedSignature), []byte(parts[2])) {
+		return "", "", errors.New("PKCE cookie signature mismatch")
+	}
+
+	stateBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", "", err
+	}
+	verifierBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(stateBytes), string(verifierBytes), nil
+}
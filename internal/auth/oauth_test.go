@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// newMockOAuthServer stands in for a provider's token + userinfo endpoints so
+// Authenticate can be exercised without a real OAuth2/OIDC issuer.
+func newMockOAuthServer(t *testing.T, wantCode, wantVerifier string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse token request form: %v", err)
+		}
+		if r.FormValue("code") != wantCode {
+			http.Error(w, "unexpected code", http.StatusBadRequest)
+			return
+		}
+		if r.FormValue("code_verifier") != wantVerifier {
+			http.Error(w, "unexpected code_verifier", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "mock-access-token"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mock-access-token" {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"sub":            "subject-123",
+			"email":          "person@example.com",
+			"email_verified": true,
+			"name":           "Person",
+			"picture":        "https://example.com/avatar.png",
+		})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestOAuthProviderAuthenticate(t *testing.T) {
+	server := newMockOAuthServer(t, "test-code", "test-verifier")
+	defer server.Close()
+
+	provider, err := NewOAuthProvider(OAuthConfig{
+		ProviderName: "mock",
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "https://example.com/auth/mock/callback",
+		TokenURL:     server.URL + "/token",
+		UserInfoURL:  server.URL + "/userinfo",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewOAuthProvider() error = %v", err)
+	}
+
+	identity, err := provider.Authenticate(context.Background(), map[string]string{
+		"code":          "test-code",
+		"code_verifier": "test-verifier",
+	})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	if identity.ExternalID != "subject-123" {
+		t.Errorf("expected ExternalID %q, got %q", "subject-123", identity.ExternalID)
+	}
+	if identity.Email != "person@example.com" {
+		t.Errorf("expected Email %q, got %q", "person@example.com", identity.Email)
+	}
+	if !identity.EmailVerified {
+		t.Error("expected EmailVerified to be true")
+	}
+	if identity.AvatarURL != "https://example.com/avatar.png" {
+		t.Errorf("expected AvatarURL %q, got %q", "https://example.com/avatar.png", identity.AvatarURL)
+	}
+
+	t.Run("Rejects the wrong code_verifier", func(t *testing.T) {
+		_, err := provider.Authenticate(context.Background(), map[string]string{
+			"code":          "test-code",
+			"code_verifier": "wrong-verifier",
+		})
+		if err == nil {
+			t.Error("expected an error for a mismatched code_verifier")
+		}
+	})
+}
+
+func TestGitHubUserInfoMapFn(t *testing.T) {
+	identity, err := githubUserInfoMapFn(map[string]any{
+		"id":         float64(42),
+		"login":      "octocat",
+		"email":      "octocat@example.com",
+		"avatar_url": "https://github.com/avatars/octocat.png",
+	})
+	if err != nil {
+		t.Fatalf("githubUserInfoMapFn() error = %v", err)
+	}
+
+	if identity.ExternalID != "42" {
+		t.Errorf("expected ExternalID %q, got %q", "42", identity.ExternalID)
+	}
+	if identity.Username != "octocat" {
+		t.Errorf("expected Username %q, got %q", "octocat", identity.Username)
+	}
+	if !identity.EmailVerified {
+		t.Error("expected EmailVerified to be true when GitHub returns a public email")
+	}
+	if identity.AvatarURL != "https://github.com/avatars/octocat.png" {
+		t.Errorf("expected AvatarURL %q, got %q", "https://github.com/avatars/octocat.png", identity.AvatarURL)
+	}
+
+	t.Run("Missing id is an error", func(t *testing.T) {
+		if _, err := githubUserInfoMapFn(map[string]any{"login": "octocat"}); err == nil {
+			t.Error("expected an error when 'id' is missing")
+		}
+	})
+}
+
+func TestPKCECookieRoundTrip(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+	defer os.Unsetenv("JWT_SECRET")
+
+	signed, err := signPKCECookie("some-state", "some-verifier")
+	if err != nil {
+		t.Fatalf("signPKCECookie() error = %v", err)
+	}
+
+	state, verifier, err := verifyPKCECookie(signed)
+	if err != nil {
+		t.Fatalf("verifyPKCECookie() error = %v", err)
+	}
+	if state != "some-state" {
+		t.Errorf("expected state %q, got %q", "some-state", state)
+	}
+	if verifier != "some-verifier" {
+		t.Errorf("expected verifier %q, got %q", "some-verifier", verifier)
+	}
+
+	t.Run("Rejects a tampered cookie", func(t *testing.T) {
+		if _, _, err := verifyPKCECookie(signed + "tampered"); err == nil {
+			t.Error("expected an error for a tampered cookie")
+		}
+	})
+}
+
+func TestCodeChallengeS256IsDeterministic(t *testing.T) {
+	verifier := "fixed-verifier"
+	if codeChallengeS256(verifier) != codeChallengeS256(verifier) {
+		t.Error("expected codeChallengeS256 to be deterministic for the same verifier")
+	}
+	if codeChallengeS256(verifier) == verifier {
+		t.Error("expected the challenge to differ from the plaintext verifier")
+	}
+}
@@ -0,0 +1,426 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenVerifier validates a raw bearer token and returns the Claims it carries. AuthMiddleware
+// consults one (see SetVerifier) instead of calling ValidateJWT directly, so tokens minted
+// outside this process - by an external OIDC provider - can be accepted alongside tundra's
+// own.
+type TokenVerifier interface {
+	Verify(ctx context.Context, rawToken string) (*Claims, error)
+}
+
+// localVerifier adapts ValidateJWT to TokenVerifier, so it can sit in a MultiVerifier chain
+// next to one or more OIDCVerifier instances.
+type localVerifier struct{}
+
+func (localVerifier) Verify(_ context.Context, rawToken string) (*Claims, error) {
+	return ValidateJWT(rawToken)
+}
+
+// MultiVerifier tries each wrapped TokenVerifier in turn and returns the first one that
+// accepts the token.
+type MultiVerifier struct {
+	verifiers []TokenVerifier
+}
+
+// NewMultiVerifier chains verifiers. The local verifier (ValidateJWT) is always tried
+// first, regardless of the order verifiers are passed in, so a token tundra minted itself
+// is never slowed down by a round trip to an external provider's JWKS.
+func NewMultiVerifier(verifiers ...TokenVerifier) *MultiVerifier {
+	return &MultiVerifier{verifiers: append([]TokenVerifier{localVerifier{}}, verifiers...)}
+}
+
+func (m *MultiVerifier) Verify(ctx context.Context, rawToken string) (*Claims, error) {
+	var lastErr error
+	for _, v := range m.verifiers {
+		claims, err := v.Verify(ctx, rawToken)
+		if err == nil {
+			return claims, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no token verifiers configured")
+	}
+	return nil, lastErr
+}
+
+var (
+	verifierMu sync.Mutex
+	verifier   TokenVerifier = localVerifier{}
+)
+
+// SetVerifier replaces the TokenVerifier AuthMiddleware consults. Pass nil to restore the
+// default, which only accepts tokens minted by ValidateJWT.
+func SetVerifier(v TokenVerifier) {
+	verifierMu.Lock()
+	defer verifierMu.Unlock()
+	if v == nil {
+		v = localVerifier{}
+	}
+	verifier = v
+}
+
+func activeVerifier() TokenVerifier {
+	verifierMu.Lock()
+	defer verifierMu.Unlock()
+	return verifier
+}
+
+// oidcDefaultRefreshInterval is how long an OIDCVerifier caches a provider's JWKS before
+// re-fetching it.
+const oidcDefaultRefreshInterval = 1 * time.Hour
+
+// oidcKey is one JWKS entry, decoded into the public key ParseWithClaims' keyfunc needs.
+type oidcKey struct {
+	alg string
+	key interface{} // *rsa.PublicKey or *ecdsa.PublicKey
+}
+
+// OIDCVerifier is a TokenVerifier for tokens minted by an external OIDC provider (Google,
+// GitHub, Dex, Keycloak, Auth0, ...): it discovers the provider's JWKS via
+// .well-known/openid-configuration, caches the keys for refreshInterval, and validates
+// iss/aud/exp/nbf before mapping sub/email (and, if configured, a nested role claim) into
+// a *Claims.
+type OIDCVerifier struct {
+	issuer          string
+	audiences       []string
+	roleClaimPath   []string
+	refreshInterval time.Duration
+	httpClient      *http.Client
+
+	mu        sync.Mutex
+	jwksURI   string
+	keys      map[string]oidcKey
+	fetchedAt time.Time
+}
+
+// OIDCVerifierOption configures an OIDCVerifier beyond its required issuer and audiences.
+type OIDCVerifierOption func(*OIDCVerifier)
+
+// WithRoleClaimPath sets the dot-separated path a role is read from within the token's
+// claims (e.g. "realm_access.roles" for Keycloak), so AdminMiddleware/RequireAnyRole keep
+// working unchanged for providers that nest roles instead of putting them at the top
+// level. Left unset, OIDCVerifier never sets Role.
+func WithRoleClaimPath(path string) OIDCVerifierOption {
+	return func(v *OIDCVerifier) {
+		if path == "" {
+			v.roleClaimPath = nil
+			return
+		}
+		v.roleClaimPath = strings.Split(path, ".")
+	}
+}
+
+// WithOIDCRefreshInterval overrides how long a fetched JWKS is cached before re-fetching
+// (default oidcDefaultRefreshInterval).
+func WithOIDCRefreshInterval(d time.Duration) OIDCVerifierOption {
+	return func(v *OIDCVerifier) { v.refreshInterval = d }
+}
+
+// NewOIDCVerifier creates an OIDCVerifier for issuer, accepting tokens whose aud claim
+// contains at least one of audiences. Discovery (the .well-known document, then its
+// jwks_uri) happens lazily on the first Verify call, not here.
+func NewOIDCVerifier(issuer string, audiences []string, opts ...OIDCVerifierOption) *OIDCVerifier {
+	v := &OIDCVerifier{
+		issuer:          strings.TrimRight(issuer, "/"),
+		audiences:       audiences,
+		refreshInterval: oidcDefaultRefreshInterval,
+		httpClient:      &http.Client{Timeout: 5 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Verify implements TokenVerifier.
+func (v *OIDCVerifier) Verify(ctx context.Context, rawToken string) (*Claims, error) {
+	if err := v.ensureKeys(ctx); err != nil {
+		return nil, fmt.Errorf("oidc key discovery failed: %w", err)
+	}
+
+	mapClaims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(rawToken, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		v.mu.Lock()
+		key, ok := v.keys[kid]
+		v.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key.key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oidc token validation failed: %w", err)
+	}
+	if !token.Valid {
+		return nil, errors.New("oidc token is invalid")
+	}
+
+	iss, _ := mapClaims["iss"].(string)
+	if iss != v.issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", iss)
+	}
+	if !v.audienceMatches(mapClaims["aud"]) {
+		return nil, errors.New("token audience does not match any configured OIDC_AUDIENCES")
+	}
+
+	sub, _ := mapClaims["sub"].(string)
+	if sub == "" {
+		return nil, errors.New("oidc token is missing a sub claim")
+	}
+
+	claims := NewClaims()
+	claims.SetUserID(sub)
+	if email, ok := mapClaims["email"].(string); ok {
+		claims.SetEmail(email)
+	}
+	if username, ok := mapClaims["preferred_username"].(string); ok {
+		claims.SetUsername(username)
+	}
+	claims.SetProvider(v.issuer)
+	claims.SetTwoFactorVerified(true)
+	if role := claimAtPath(mapClaims, v.roleClaimPath); role != "" {
+		claims.SetRole(role)
+	}
+
+	return claims, nil
+}
+
+func (v *OIDCVerifier) audienceMatches(aud any) bool {
+	if len(v.audiences) == 0 {
+		return true
+	}
+
+	var candidates []string
+	switch a := aud.(type) {
+	case string:
+		candidates = []string{a}
+	case []any:
+		for _, item := range a {
+			if s, ok := item.(string); ok {
+				candidates = append(candidates, s)
+			}
+		}
+	}
+
+	for _, want := range v.audiences {
+		for _, got := range candidates {
+			if want == got {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimAtPath walks path (e.g. ["realm_access", "roles"]) through claims and returns the
+// first string it finds at the end of it - a bare string claim, or the first element of a
+// []any of roles. Returns "" if path is empty or nothing along it resolves to a string.
+func claimAtPath(claims map[string]any, path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	var cur any = map[string]any(claims)
+	for _, segment := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return ""
+		}
+		if cur, ok = m[segment]; !ok {
+			return ""
+		}
+	}
+
+	switch resolved := cur.(type) {
+	case string:
+		return resolved
+	case []any:
+		if len(resolved) > 0 {
+			if s, ok := resolved[0].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// oidcProviderMetadata is the subset of a .well-known/openid-configuration document
+// OIDCVerifier needs.
+type oidcProviderMetadata struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+func (v *OIDCVerifier) ensureKeys(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.keys != nil && time.Since(v.fetchedAt) < v.refreshInterval {
+		return nil
+	}
+
+	if v.jwksURI == "" {
+		jwksURI, err := v.fetchJWKSURI(ctx)
+		if err != nil {
+			return err
+		}
+		v.jwksURI = jwksURI
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		if v.keys != nil {
+			// Keep serving the stale key set rather than failing every request outright
+			// just because the provider is briefly unreachable - the same fail-open
+			// posture as hibpBreachChecker.
+			return nil
+		}
+		return err
+	}
+
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	return nil
+}
+
+func (v *OIDCVerifier) fetchJWKSURI(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oidc discovery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oidc discovery returned status %d", resp.StatusCode)
+	}
+
+	var doc oidcProviderMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", fmt.Errorf("oidc discovery response decode failed: %w", err)
+	}
+	if doc.JWKSURI == "" {
+		return "", errors.New("oidc discovery document is missing jwks_uri")
+	}
+	return doc.JWKSURI, nil
+}
+
+func (v *OIDCVerifier) fetchKeys(ctx context.Context) (map[string]oidcKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwks fetch failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks fetch returned status %d", resp.StatusCode)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("jwks response decode failed: %w", err)
+	}
+
+	keys := make(map[string]oidcKey, len(set.Keys))
+	for _, k := range set.Keys {
+		pub, err := decodeJWKPublicKey(k)
+		if err != nil {
+			continue // skip key types we don't support rather than failing the whole set
+		}
+		keys[k.Kid] = oidcKey{alg: k.Alg, key: pub}
+	}
+	return keys, nil
+}
+
+func decodeJWKPublicKey(k jwk) (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(nBytes),
+			E: int(new(big.Int).SetBytes(eBytes).Int64()),
+		}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported JWK curve %q", k.Crv)
+		}
+		xBytes, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		yBytes, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %q", k.Kty)
+	}
+}
+
+// ConfigureOIDCVerifiersFromEnv wires an OIDCVerifier for every issuer in the
+// comma-separated OIDC_ISSUERS env var, all accepting the comma-separated audiences in
+// OIDC_AUDIENCES, with an optional shared OIDC_ROLE_CLAIM_PATH (see WithRoleClaimPath).
+// Leaves the default (local-only) verifier in place if OIDC_ISSUERS is unset. Call during
+// server startup, not per request.
+func ConfigureOIDCVerifiersFromEnv() {
+	issuersEnv := os.Getenv("OIDC_ISSUERS")
+	if issuersEnv == "" {
+		return
+	}
+
+	var audiences []string
+	if audiencesEnv := os.Getenv("OIDC_AUDIENCES"); audiencesEnv != "" {
+		for _, aud := range strings.Split(audiencesEnv, ",") {
+			if aud = strings.TrimSpace(aud); aud != "" {
+				audiences = append(audiences, aud)
+			}
+		}
+	}
+	rolePath := os.Getenv("OIDC_ROLE_CLAIM_PATH")
+
+	var verifiers []TokenVerifier
+	for _, issuer := range strings.Split(issuersEnv, ",") {
+		issuer = strings.TrimSpace(issuer)
+		if issuer == "" {
+			continue
+		}
+		verifiers = append(verifiers, NewOIDCVerifier(issuer, audiences, WithRoleClaimPath(rolePath)))
+	}
+
+	SetVerifier(NewMultiVerifier(verifiers...))
+}
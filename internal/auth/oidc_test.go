@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var errUnconditionalFailure = errors.New("always fails")
+
+func TestMultiVerifierTriesLocalVerifierFirst(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+	SetKeyStore(nil)
+	defer SetKeyStore(nil)
+
+	token, err := GenerateJWT(uuid.New(), "alice", "alice@example.com", "user")
+	if err != nil {
+		t.Fatalf("GenerateJWT() error = %v", err)
+	}
+
+	mv := NewMultiVerifier(alwaysFailVerifier{})
+	claims, err := mv.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if claims.Username() != "alice" {
+		t.Errorf("expected username %q, got %q", "alice", claims.Username())
+	}
+}
+
+func TestMultiVerifierFallsThroughToLaterVerifiers(t *testing.T) {
+	mv := NewMultiVerifier(alwaysFailVerifier{}, stubVerifier{claims: NewClaims()})
+	claims, err := mv.Verify(context.Background(), "not-a-local-token")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if claims == nil {
+		t.Fatal("expected non-nil claims from the stub verifier")
+	}
+}
+
+func TestMultiVerifierRejectsWhenEveryVerifierFails(t *testing.T) {
+	mv := NewMultiVerifier(alwaysFailVerifier{})
+	if _, err := mv.Verify(context.Background(), "garbage"); err == nil {
+		t.Fatal("expected an error when every verifier rejects the token")
+	}
+}
+
+type alwaysFailVerifier struct{}
+
+func (alwaysFailVerifier) Verify(context.Context, string) (*Claims, error) {
+	return nil, errUnconditionalFailure
+}
+
+type stubVerifier struct{ claims *Claims }
+
+func (s stubVerifier) Verify(context.Context, string) (*Claims, error) {
+	return s.claims, nil
+}
+
+func TestClaimAtPath(t *testing.T) {
+	claims := map[string]any{
+		"realm_access": map[string]any{
+			"roles": []any{"admin", "user"},
+		},
+		"role": "editor",
+	}
+
+	tests := []struct {
+		name string
+		path []string
+		want string
+	}{
+		{"nested roles array", []string{"realm_access", "roles"}, "admin"},
+		{"bare string claim", []string{"role"}, "editor"},
+		{"empty path", nil, ""},
+		{"missing path", []string{"nope"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := claimAtPath(claims, tt.path); got != tt.want {
+				t.Errorf("claimAtPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOIDCVerifierEndToEnd spins up a fake provider serving a discovery document and JWKS,
+// signs a token with the matching private key, and checks OIDCVerifier accepts it and maps
+// sub/email/realm_access.roles into the returned Claims.
+func TestOIDCVerifierEndToEnd(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	const kid = "test-kid"
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": issuer + "/jwks.json"})
+	})
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwks{Keys: []jwk{{
+			Kty: "RSA",
+			Kid: kid,
+			Alg: AlgRS256,
+			N:   base64.RawURLEncoding.EncodeToString(priv.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.PublicKey.E)).Bytes()),
+		}}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	claims := jwt.MapClaims{
+		"iss":   issuer,
+		"aud":   "tundra-api",
+		"sub":   "external-subject-123",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"email": "federated@example.com",
+		"realm_access": map[string]any{
+			"roles": []any{"admin"},
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	rawToken, err := token.SignedString(priv)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	verifier := NewOIDCVerifier(issuer, []string{"tundra-api"}, WithRoleClaimPath("realm_access.roles"))
+	resolved, err := verifier.Verify(context.Background(), rawToken)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if resolved.UserID() != "external-subject-123" {
+		t.Errorf("expected UserID %q, got %q", "external-subject-123", resolved.UserID())
+	}
+	if resolved.Email() != "federated@example.com" {
+		t.Errorf("expected Email %q, got %q", "federated@example.com", resolved.Email())
+	}
+	if resolved.Role() != "admin" {
+		t.Errorf("expected Role %q, got %q", "admin", resolved.Role())
+	}
+}
+
+func TestOIDCVerifierRejectsWrongAudience(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	const kid = "test-kid"
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": issuer + "/jwks.json"})
+	})
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwks{Keys: []jwk{{
+			Kty: "RSA",
+			Kid: kid,
+			Alg: AlgRS256,
+			N:   base64.RawURLEncoding.EncodeToString(priv.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.PublicKey.E)).Bytes()),
+		}}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	claims := jwt.MapClaims{
+		"iss": issuer,
+		"aud": "someone-else",
+		"sub": "external-subject-123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	rawToken, err := token.SignedString(priv)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	verifier := NewOIDCVerifier(issuer, []string{"tundra-api"})
+	if _, err := verifier.Verify(context.Background(), rawToken); err == nil {
+		t.Fatal("expected an error for a token with an unrecognized audience")
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+)
+
+// PasswordProvider is the built-in email+password Provider. It has no login/callback
+// routes of its own - those stay on POST /auth/login and /auth/signup in routes.go -
+// but it's registered so the rest of the auth package can treat "password" the same way
+// as any OAuth2/OIDC provider (e.g. for the Provider field stamped on issued JWTs).
+type PasswordProvider struct {
+	db *gorm.DB
+}
+
+// NewPasswordProvider constructs the password Provider backed by db.
+func NewPasswordProvider(db *gorm.DB) *PasswordProvider {
+	return &PasswordProvider{db: db}
+}
+
+func (p *PasswordProvider) Name() string { return ProviderPassword }
+
+// Authenticate looks up creds["email"] and verifies creds["password"] against the
+// stored bcrypt hash. On success, ExternalID carries the matched user's own ID - the
+// password provider has no separate external system, so the local user IS the identity.
+func (p *PasswordProvider) Authenticate(ctx context.Context, creds map[string]string) (*ProviderUser, error) {
+	email := creds["email"]
+	password := creds["password"]
+	if email == "" || password == "" {
+		return nil, errors.New("email and password are required")
+	}
+
+	var user models.User
+	if err := p.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+
+	return &ProviderUser{
+		Email:         user.Email,
+		EmailVerified: true,
+		Username:      user.Username,
+		ExternalID:    user.ID.String(),
+	}, nil
+}
+
+// LoginHandler is nil: the password provider is driven by POST /auth/login, which
+// handles the 2FA branching that a generic provider-agnostic redirect can't express.
+func (p *PasswordProvider) LoginHandler() gin.HandlerFunc { return nil }
+
+// CallbackHandler is nil for the same reason as LoginHandler.
+func (p *PasswordProvider) CallbackHandler() gin.HandlerFunc { return nil }
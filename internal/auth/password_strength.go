@@ -0,0 +1,190 @@
+package auth
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+//go:embed commonpasswords.txt
+var commonPasswordsFile string
+
+var commonPasswords = buildCommonPasswordSet(commonPasswordsFile)
+
+func buildCommonPasswordSet(raw string) map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(raw, "\n") {
+		word := strings.ToLower(strings.TrimSpace(line))
+		if word != "" {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+// qwertyRows holds adjacent-key runs on a standard US QWERTY keyboard, used to detect
+// "walked" passwords like "qwerty" or "asdfgh".
+var qwertyRows = []string{
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+	"1234567890",
+}
+
+// PasswordFeedback is the structured result of scoring a candidate password, modeled
+// loosely on zxcvbn's score/warning/suggestions shape.
+type PasswordFeedback struct {
+	Score       int      `json:"score"` // 0 (very weak) through 4 (very strong)
+	Warning     string   `json:"warning,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// ScorePassword estimates password strength on a 0-4 scale by penalizing dictionary
+// matches, keyboard-walk patterns, repeated/sequential characters, and date-like
+// substrings, on top of a length baseline. It does not replace ValidatePassword's
+// character-class floor - the two are complementary.
+func ScorePassword(password string) PasswordFeedback {
+	score := 4
+	var warning string
+	var suggestions []string
+
+	lower := strings.ToLower(password)
+
+	if isCommonPassword(lower) {
+		score = 0
+		warning = "This is one of the most commonly used passwords"
+		suggestions = append(suggestions, "Avoid common passwords and dictionary words")
+	}
+
+	if hasKeyboardWalk(lower) {
+		if score > 1 {
+			score = 1
+		}
+		warning = orDefault(warning, "This password contains a keyboard pattern")
+		suggestions = append(suggestions, "Avoid sequences like \"qwerty\" or \"asdfgh\"")
+	}
+
+	if hasSequentialRun(lower) {
+		if score > 2 {
+			score = 2
+		}
+		warning = orDefault(warning, "This password contains a sequential pattern")
+		suggestions = append(suggestions, "Avoid sequences like \"abcd\" or \"1234\"")
+	}
+
+	if hasRepeatedRun(lower) {
+		if score > 2 {
+			score = 2
+		}
+		warning = orDefault(warning, "This password repeats a character too many times")
+		suggestions = append(suggestions, "Avoid repeating the same character (e.g. \"aaaa\")")
+	}
+
+	if hasDatePattern(password) {
+		if score > 2 {
+			score = 2
+		}
+		warning = orDefault(warning, "This password looks like it contains a date")
+		suggestions = append(suggestions, "Avoid birthdays or years as part of your password")
+	}
+
+	if len(password) < 12 && score > 3 {
+		score = 3
+	}
+
+	if len(password) >= 16 && score == 4 {
+		suggestions = nil
+	} else if len(suggestions) == 0 && score < 4 {
+		suggestions = append(suggestions, "Use a longer, less predictable passphrase")
+	}
+
+	return PasswordFeedback{Score: score, Warning: warning, Suggestions: suggestions}
+}
+
+// isCommonPassword matches the exact password against the breached-password wordlist,
+// then retries with trailing digits/punctuation stripped so trivial variants like
+// "password123!" are still caught.
+func isCommonPassword(lower string) bool {
+	if commonPasswords[lower] {
+		return true
+	}
+	stripped := strings.TrimRight(lower, "0123456789!@#$%^&*()_+-=.,<>?/\\|~`'\";: ")
+	return stripped != lower && commonPasswords[stripped]
+}
+
+func orDefault(current, fallback string) string {
+	if current != "" {
+		return current
+	}
+	return fallback
+}
+
+func hasKeyboardWalk(lower string) bool {
+	const runLength = 4
+	for _, row := range qwertyRows {
+		for i := 0; i+runLength <= len(row); i++ {
+			if strings.Contains(lower, row[i:i+runLength]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasSequentialRun(lower string) bool {
+	const runLength = 4
+	runeSlice := []rune(lower)
+	ascending, descending := 1, 1
+	for i := 1; i < len(runeSlice); i++ {
+		if runeSlice[i] == runeSlice[i-1]+1 {
+			ascending++
+			descending = 1
+		} else if runeSlice[i] == runeSlice[i-1]-1 {
+			descending++
+			ascending = 1
+		} else {
+			ascending, descending = 1, 1
+		}
+		if ascending >= runLength || descending >= runLength {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRepeatedRun(lower string) bool {
+	const runLength = 4
+	runeSlice := []rune(lower)
+	count := 1
+	for i := 1; i < len(runeSlice); i++ {
+		if runeSlice[i] == runeSlice[i-1] {
+			count++
+		} else {
+			count = 1
+		}
+		if count >= runLength {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDatePattern flags common date-like substrings (e.g. "1990", "01/02/2003") that make
+// a password easy to guess from publicly known birthdays or anniversaries.
+func hasDatePattern(password string) bool {
+	digitsOnly := make([]rune, 0, len(password))
+	for _, r := range password {
+		if unicode.IsDigit(r) {
+			digitsOnly = append(digitsOnly, r)
+		}
+	}
+
+	for i := 0; i+4 <= len(digitsOnly); i++ {
+		year := string(digitsOnly[i : i+4])
+		if year >= "1940" && year <= "2029" {
+			return true
+		}
+	}
+
+	return false
+}
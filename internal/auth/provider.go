@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderUser is the normalized identity a Provider resolves credentials or an OAuth
+// callback down to, before routes.go links it to an existing models.User or creates one.
+type ProviderUser struct {
+	Email         string
+	EmailVerified bool
+	Username      string
+	// ExternalID is the provider's own subject/user id (e.g. Google's "sub", GitHub's
+	// numeric user id). The password provider, which has no external identity, sets it to
+	// the local user's own ID.
+	ExternalID string
+	// AvatarURL is the provider's profile picture URL, if it returned one. Empty for the
+	// password provider.
+	AvatarURL string
+}
+
+// Provider is implemented by every way a user can authenticate: the built-in
+// email+password flow and each configured OAuth2/OIDC provider. AuthMiddleware and JWT
+// issuance never depend on which Provider was used - they only see the resulting claims.
+type Provider interface {
+	// Name identifies the provider, used in routes (/auth/{name}/login) and stored on
+	// issued JWTs so the originating provider can be audited later.
+	Name() string
+
+	// Authenticate resolves credentials into the identity that was authenticated. The
+	// password provider expects creds["email"]/creds["password"]; OAuth2/OIDC providers
+	// expect creds["code"]/creds["code_verifier"] from their callback.
+	Authenticate(ctx context.Context, creds map[string]string) (*ProviderUser, error)
+
+	// LoginHandler starts the provider's flow. The password provider has no login route
+	// of its own (it's driven by POST /auth/login) and returns nil.
+	LoginHandler() gin.HandlerFunc
+
+	// CallbackHandler completes the provider's flow. The password provider returns nil.
+	CallbackHandler() gin.HandlerFunc
+}
+
+// providers holds every registered Provider by name, populated at server startup from
+// the built-in password provider plus whichever OAuth2/OIDC providers are configured.
+var providers = map[string]Provider{}
+
+// RegisterProvider makes p available at /auth/{p.Name()}/login and /callback.
+func RegisterProvider(p Provider) {
+	providers[p.Name()] = p
+}
+
+// GetProvider looks up a previously registered Provider by name.
+func GetProvider(name string) (Provider, bool) {
+	p, ok := providers[name]
+	return p, ok
+}
+
+// Providers returns every registered Provider, keyed by name.
+func Providers() map[string]Provider {
+	return providers
+}
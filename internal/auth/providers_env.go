@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// LoadProvidersFromEnv registers the password provider plus any OAuth2/OIDC provider
+// whose client ID env var is set, logging (but not failing startup on) any provider
+// that's misconfigured - auth providers follow the repo's graceful-degradation pattern:
+// a broken Google config shouldn't take down password login.
+func LoadProvidersFromEnv(db *gorm.DB) {
+	RegisterProvider(NewPasswordProvider(db))
+
+	if os.Getenv("GOOGLE_CLIENT_ID") != "" {
+		if p, err := NewGoogleProvider(db); err != nil {
+			fmt.Printf("Warning: Google auth provider disabled: %v\n", err)
+		} else {
+			RegisterProvider(p)
+		}
+	}
+
+	if os.Getenv("GITHUB_CLIENT_ID") != "" {
+		if p, err := NewGitHubProvider(db); err != nil {
+			fmt.Printf("Warning: GitHub auth provider disabled: %v\n", err)
+		} else {
+			RegisterProvider(p)
+		}
+	}
+
+	// A local dev OIDC provider (e.g. Dex, https://github.com/dexidp/dex) configured via
+	// DEX_CLIENT_ID / DEX_CLIENT_SECRET / DEX_REDIRECT_URL / DEX_OIDC_DISCOVERY_URL.
+	if os.Getenv("DEX_CLIENT_ID") != "" {
+		if p, err := NewOIDCProvider("dex", db); err != nil {
+			fmt.Printf("Warning: dex auth provider disabled: %v\n", err)
+		} else {
+			RegisterProvider(p)
+		}
+	}
+}
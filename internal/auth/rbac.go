@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+)
+
+// rbacCacheTTL bounds how long an effective permission set is trusted once computed for
+// a given JWT jti, so a role/permission grant or revoke is picked up without requiring
+// the holder to log in again - just to wait out the cache.
+const rbacCacheTTL = 5 * time.Minute
+
+type rbacCacheEntry struct {
+	roles       []string
+	permissions []string
+	expiresAt   time.Time
+}
+
+// RBACStore resolves a user's effective roles and permissions from the roles/
+// permissions/role_permissions/user_roles tables, acting as a minimal casbin-style
+// policy enforcer: policies (which roles grant which permissions) live in the database,
+// and EffectivePermissions is the "enforce" call, cached per-jti to avoid a join on
+// every request.
+type RBACStore struct {
+	db    *gorm.DB
+	mu    sync.Mutex
+	cache map[string]rbacCacheEntry
+}
+
+// NewRBACStore constructs an RBACStore backed by db.
+func NewRBACStore(db *gorm.DB) *RBACStore {
+	return &RBACStore{db: db, cache: make(map[string]rbacCacheEntry)}
+}
+
+// rbacResolver is the subset of RBACStore's behavior the RBAC middleware depends on,
+// so tests can substitute a stub without a live database.
+type rbacResolver interface {
+	EffectivePermissions(jti string, userID uuid.UUID) (roles []string, permissions []string, err error)
+}
+
+// rbacStore is the process-wide resolver used by the RequirePermission/RequireAnyRole
+// middleware factories, wired up once at server startup via SetRBACStore - the same
+// package-level-singleton pattern used for the access-token denylist client.
+var rbacStore rbacResolver
+
+// SetRBACStore wires the RBAC middleware to store. Pass nil to disable RBAC checks
+// entirely (every RequirePermission/RequireAnyRole call then fails closed).
+func SetRBACStore(store rbacResolver) {
+	rbacStore = store
+}
+
+// EffectivePermissions returns the role names and permission keys granted to userID,
+// serving a cached result for jti if one hasn't expired yet.
+func (s *RBACStore) EffectivePermissions(jti string, userID uuid.UUID) (roles []string, permissions []string, err error) {
+	if jti != "" {
+		s.mu.Lock()
+		entry, ok := s.cache[jti]
+		s.mu.Unlock()
+		if ok && time.Now().Before(entry.expiresAt) {
+			return entry.roles, entry.permissions, nil
+		}
+	}
+
+	var roleNames []string
+	if err := s.db.Table("user_roles").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("roles.name", &roleNames).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+
+	var permissionKeys []string
+	if len(roleNames) > 0 {
+		if err := s.db.Table("user_roles").
+			Joins("JOIN role_permissions ON role_permissions.role_id = user_roles.role_id").
+			Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+			Where("user_roles.user_id = ?", userID).
+			Distinct().
+			Pluck("permissions.key", &permissionKeys).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to load permissions: %w", err)
+		}
+	}
+
+	if jti != "" {
+		s.mu.Lock()
+		s.cache[jti] = rbacCacheEntry{roles: roleNames, permissions: permissionKeys, expiresAt: time.Now().Add(rbacCacheTTL)}
+		s.mu.Unlock()
+	}
+
+	return roleNames, permissionKeys, nil
+}
+
+// GrantRole adds roleName to userID, creating the grant if it doesn't already exist.
+func (s *RBACStore) GrantRole(userID uuid.UUID, roleName string) error {
+	var role models.Role
+	if err := s.db.Where("name = ?", roleName).First(&role).Error; err != nil {
+		return fmt.Errorf("unknown role %q: %w", roleName, err)
+	}
+
+	var existing models.UserRole
+	err := s.db.Where("user_id = ? AND role_id = ?", userID, role.ID).First(&existing).Error
+	if err == nil {
+		return nil
+	}
+
+	return s.db.Create(&models.UserRole{UserID: userID, RoleID: role.ID}).Error
+}
+
+// RevokeRole removes roleName from userID, if it was granted.
+func (s *RBACStore) RevokeRole(userID uuid.UUID, roleName string) error {
+	var role models.Role
+	if err := s.db.Where("name = ?", roleName).First(&role).Error; err != nil {
+		return fmt.Errorf("unknown role %q: %w", roleName, err)
+	}
+
+	return s.db.Where("user_id = ? AND role_id = ?", userID, role.ID).Delete(&models.UserRole{}).Error
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+)
+
+// RefreshTokenTTL is how long an issued refresh token remains valid before it must be rotated.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// RedisRevocationStore is a RevocationStore backed by Redis, suitable for sharing
+// revocations across every API instance. Prefer it (via SetDenylistClient) whenever Redis
+// is already part of the deployment; use PostgresRevocationStore otherwise.
+type RedisRevocationStore struct {
+	rdb *redis.Client
+}
+
+// NewRedisRevocationStore creates a RedisRevocationStore backed by rdb.
+func NewRedisRevocationStore(rdb *redis.Client) *RedisRevocationStore {
+	return &RedisRevocationStore{rdb: rdb}
+}
+
+func (s *RedisRevocationStore) Revoke(jti string, ttl time.Duration) error {
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	return s.rdb.Set(context.Background(), "jwt:denylist:"+jti, "1", ttl).Err()
+}
+
+func (s *RedisRevocationStore) IsRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+	exists, err := s.rdb.Exists(context.Background(), "jwt:denylist:"+jti).Result()
+	return err == nil && exists > 0
+}
+
+// SetDenylistClient wires a Redis-backed RevocationStore, used to track revoked access
+// token jtis across every API instance. Call it once during server startup; without it
+// (and without a later SetRevocationStore call), revocation falls back to an in-memory
+// store that only covers the current process.
+func SetDenylistClient(rdb *redis.Client) {
+	if rdb == nil {
+		SetRevocationStore(nil)
+		return
+	}
+	SetRevocationStore(NewRedisRevocationStore(rdb))
+}
+
+// DenylistAccessToken marks jti as revoked for the remainder of its natural lifetime. It's
+// the API logoutHandler calls to revoke the access token presented at logout.
+func DenylistAccessToken(jti string, ttl time.Duration) error {
+	return revocationStore.Revoke(jti, ttl)
+}
+
+func isAccessRevoked(jti string) bool {
+	return revocationStore.IsRevoked(jti)
+}
+
+// sessionRevocationPrefix namespaces session-id revocations within the same
+// RevocationStore DenylistAccessToken's jtis use, so one configured store (in-memory,
+// Postgres, or Redis via SetDenylistClient) backs both without key collisions.
+const sessionRevocationPrefix = "sid:"
+
+// RevokeSessionToken marks every access token minted for sessionID as revoked for ttl
+// (pass AccessTokenTTL - no access token for the session can outlive that anyway), so
+// AuthMiddleware rejects them immediately even though none of their individual jtis was
+// ever denylisted. It's what DELETE /auth/sessions/:id calls alongside
+// RefreshStore.RevokeSession.
+func RevokeSessionToken(sessionID uuid.UUID, ttl time.Duration) error {
+	return revocationStore.Revoke(sessionRevocationPrefix+sessionID.String(), ttl)
+}
+
+func isSessionRevoked(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	return revocationStore.IsRevoked(sessionRevocationPrefix + sessionID)
+}
+
+// Session is a point-in-time snapshot of one active refresh-token session, returned by
+// RefreshStore.ListSessions for GET /auth/sessions - enough for a user to tell their
+// devices/logins apart and decide which one to kill via DELETE /auth/sessions/:id.
+type Session struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RefreshStore issues, rotates, and revokes the opaque refresh tokens returned alongside a
+// JWT access token. RefreshTokenStore (Postgres) and RedisRefreshTokenStore implement it;
+// Server picks whichever backs the deployment's Redis availability, the same way it picks
+// between PostgresRevocationStore and RedisRevocationStore.
+type RefreshStore interface {
+	// Issue returns the new refresh token and the id of the session it belongs to, for
+	// GenerateJWTWithSession to stamp onto the access token minted alongside it.
+	Issue(userID uuid.UUID, userAgent, ip string) (token string, sessionID uuid.UUID, err error)
+	// Rotate likewise returns the replacement session's id, since rotation mints a new
+	// session row (a new sid) even though it stays within the same login family.
+	Rotate(presented, userAgent, ip string) (newToken string, sessionID uuid.UUID, userID uuid.UUID, err error)
+	Revoke(presented string) error
+	RevokeAllForUser(userID uuid.UUID) error
+	// ListSessions returns every still-active session belonging to userID, for
+	// GET /auth/sessions.
+	ListSessions(userID uuid.UUID) ([]Session, error)
+	// RevokeSession revokes a single session by id, scoped to userID so one user can't
+	// kill another's session by guessing its id. Returns an error if sessionID doesn't
+	// belong to (or isn't still active for) userID.
+	RevokeSession(userID, sessionID uuid.UUID) error
+}
+
+// RefreshTokenStore issues, rotates, and revokes opaque refresh tokens backed by Postgres.
+// Tokens are stored as SHA-256 hashes so a leaked database row cannot be replayed directly.
+// Rotation tracks a per-login family so presenting an already-rotated token (token theft) is
+// detected and revokes every token descended from that login.
+type RefreshTokenStore struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenStore creates a RefreshTokenStore backed by db.
+func NewRefreshTokenStore(db *gorm.DB) *RefreshTokenStore {
+	return &RefreshTokenStore{db: db}
+}
+
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue creates a brand new refresh token family for userID.
+func (s *RefreshTokenStore) Issue(userID uuid.UUID, userAgent, ip string) (string, uuid.UUID, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+
+	record := models.RefreshToken{
+		UserID:      userID,
+		FamilyID:    uuid.New(),
+		HashedToken: hashToken(token),
+		UserAgent:   userAgent,
+		IP:          ip,
+		ExpiresAt:   time.Now().Add(RefreshTokenTTL),
+	}
+
+	if err := s.db.Create(&record).Error; err != nil {
+		return "", uuid.Nil, err
+	}
+
+	return token, record.ID, nil
+}
+
+// Rotate validates the presented refresh token, atomically revokes it, and issues a
+// replacement in the same token family. Presenting a token that has already been rotated
+// (reuse of a revoked token) is treated as theft: the entire family is revoked and the
+// caller must log in again.
+func (s *RefreshTokenStore) Rotate(presented, userAgent, ip string) (newToken string, sessionID uuid.UUID, userID uuid.UUID, err error) {
+	hashed := hashToken(presented)
+
+	var record models.RefreshToken
+	if err := s.db.Where("hashed_token = ?", hashed).First(&record).Error; err != nil {
+		return "", uuid.Nil, uuid.Nil, errors.New("refresh token not recognized")
+	}
+
+	if record.RevokedAt != nil {
+		s.revokeFamily(record.FamilyID)
+		return "", uuid.Nil, uuid.Nil, errors.New("refresh token reuse detected; all sessions revoked")
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		return "", uuid.Nil, uuid.Nil, errors.New("refresh token expired")
+	}
+
+	newToken, err = newOpaqueToken()
+	if err != nil {
+		return "", uuid.Nil, uuid.Nil, err
+	}
+
+	replacement := models.RefreshToken{
+		UserID:      record.UserID,
+		FamilyID:    record.FamilyID,
+		HashedToken: hashToken(newToken),
+		UserAgent:   userAgent,
+		IP:          ip,
+		ExpiresAt:   time.Now().Add(RefreshTokenTTL),
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&replacement).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		record.RevokedAt = &now
+		record.ReplacedBy = &replacement.ID
+		return tx.Save(&record).Error
+	})
+	if err != nil {
+		return "", uuid.Nil, uuid.Nil, err
+	}
+
+	return newToken, replacement.ID, record.UserID, nil
+}
+
+// Revoke marks the presented refresh token as revoked without issuing a replacement (logout).
+func (s *RefreshTokenStore) Revoke(presented string) error {
+	return s.db.Model(&models.RefreshToken{}).
+		Where("hashed_token = ? AND revoked_at IS NULL", hashToken(presented)).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser revokes every still-active refresh token belonging to userID, across
+// every login family - the "log out everywhere" operation, as opposed to Revoke (a single
+// session) or revokeFamily (a single login's rotation chain).
+func (s *RefreshTokenStore) RevokeAllForUser(userID uuid.UUID) error {
+	return s.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ListSessions returns userID's still-active (unrevoked, unexpired) sessions, most
+// recently created first.
+func (s *RefreshTokenStore) ListSessions(userID uuid.UUID) ([]Session, error) {
+	var records []models.RefreshToken
+	if err := s.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, len(records))
+	for i, r := range records {
+		sessions[i] = Session{
+			ID:        r.ID,
+			UserAgent: r.UserAgent,
+			IP:        r.IP,
+			ExpiresAt: r.ExpiresAt,
+			CreatedAt: r.CreatedAt,
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes the single session sessionID, scoped to userID.
+func (s *RefreshTokenStore) RevokeSession(userID, sessionID uuid.UUID) error {
+	result := s.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+// revokeFamily revokes every still-active token descended from the same login.
+func (s *RefreshTokenStore) revokeFamily(familyID uuid.UUID) {
+	s.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now())
+}
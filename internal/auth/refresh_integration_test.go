@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"tundra/internal/testsupport/pgtest"
+)
+
+// TestRefreshTokenStoreIntegration exercises RefreshTokenStore against a real Postgres
+// (rather than mocking *gorm.DB), since rotation/family-revocation relies on actual
+// row-level locking semantics a mock can't reproduce faithfully.
+func TestRefreshTokenStoreIntegration(t *testing.T) {
+	inst := pgtest.StartPostgres(t, pgtest.WithMigrations(os.DirFS("../../migrations")))
+	inst.Reset(t)
+
+	store := NewRefreshTokenStore(inst.DB)
+	userID := uuid.New()
+
+	t.Run("Issue then Rotate returns a new token for the same user", func(t *testing.T) {
+		issued, _, err := store.Issue(userID, "test-agent", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Issue() error = %v", err)
+		}
+
+		rotated, _, rotatedUser, err := store.Rotate(issued, "test-agent", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+		if rotatedUser != userID {
+			t.Fatalf("expected rotated token to belong to %s, got %s", userID, rotatedUser)
+		}
+		if rotated == issued {
+			t.Fatal("expected Rotate() to return a different token")
+		}
+
+		if _, _, _, err := store.Rotate(issued, "test-agent", "127.0.0.1"); err == nil {
+			t.Fatal("expected reusing an already-rotated token to be rejected")
+		}
+	})
+
+	t.Run("Revoke prevents further rotation", func(t *testing.T) {
+		issued, _, err := store.Issue(userID, "test-agent", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Issue() error = %v", err)
+		}
+		if err := store.Revoke(issued); err != nil {
+			t.Fatalf("Revoke() error = %v", err)
+		}
+		if _, _, _, err := store.Rotate(issued, "test-agent", "127.0.0.1"); err == nil {
+			t.Fatal("expected rotating a revoked token to be rejected")
+		}
+	})
+
+	t.Run("RevokeAllForUser revokes every family", func(t *testing.T) {
+		first, _, err := store.Issue(userID, "test-agent", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Issue() error = %v", err)
+		}
+		second, _, err := store.Issue(userID, "other-agent", "127.0.0.2")
+		if err != nil {
+			t.Fatalf("Issue() error = %v", err)
+		}
+
+		if err := store.RevokeAllForUser(userID); err != nil {
+			t.Fatalf("RevokeAllForUser() error = %v", err)
+		}
+
+		if _, _, _, err := store.Rotate(first, "test-agent", "127.0.0.1"); err == nil {
+			t.Fatal("expected first token's family to be revoked")
+		}
+		if _, _, _, err := store.Rotate(second, "other-agent", "127.0.0.2"); err == nil {
+			t.Fatal("expected second token's family to be revoked")
+		}
+	})
+}
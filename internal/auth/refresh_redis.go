@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRefreshTokenStore is a RefreshStore backed by Redis, suitable for sharing refresh
+// sessions across every API instance with no database round trip. Each token is stored as
+// "refresh:<sha256(token)>" -> JSON{id, user_id, family_id, user_agent, ip, expires_at}
+// with a TTL matching RefreshTokenTTL. "refresh:id:<session id>" -> tokenHash lets a
+// session be found by id (for ListSessions/RevokeSession) without scanning every token,
+// and "refresh:user:<user id>" is a set of that user's live session ids, refreshed on
+// every Issue/Rotate so it never outlives its longest-lived member by more than one TTL.
+// Unlike RefreshTokenStore, it cannot detect reuse of an already-rotated token once its
+// key has expired or been deleted - prefer RefreshTokenStore when that stronger guarantee
+// matters more than avoiding the Postgres round trip.
+type RedisRefreshTokenStore struct {
+	rdb *redis.Client
+}
+
+// NewRedisRefreshTokenStore creates a RedisRefreshTokenStore backed by rdb.
+func NewRedisRefreshTokenStore(rdb *redis.Client) *RedisRefreshTokenStore {
+	return &RedisRefreshTokenStore{rdb: rdb}
+}
+
+type redisRefreshRecord struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	FamilyID  uuid.UUID `json:"family_id"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (s *RedisRefreshTokenStore) put(token string, record redisRefreshRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	tokenHash := hashToken(token)
+	if err := s.rdb.Set(ctx, "refresh:"+tokenHash, payload, RefreshTokenTTL).Err(); err != nil {
+		return err
+	}
+	if err := s.rdb.Set(ctx, "refresh:id:"+record.ID.String(), tokenHash, RefreshTokenTTL).Err(); err != nil {
+		return err
+	}
+
+	userSetKey := "refresh:user:" + record.UserID.String()
+	if err := s.rdb.SAdd(ctx, userSetKey, record.ID.String()).Err(); err != nil {
+		return err
+	}
+	return s.rdb.Expire(ctx, userSetKey, RefreshTokenTTL).Err()
+}
+
+func (s *RedisRefreshTokenStore) get(token string) (redisRefreshRecord, error) {
+	var record redisRefreshRecord
+	raw, err := s.rdb.Get(context.Background(), "refresh:"+hashToken(token)).Bytes()
+	if err != nil {
+		return record, errors.New("refresh token not recognized")
+	}
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+// deleteSession removes sessionID's "refresh:<hash>" record (via its "refresh:id:"
+// reverse-lookup key) and the reverse-lookup key itself. It does not touch the user's
+// session set; callers remove the id from that separately since Rotate/RevokeAllForUser
+// need different membership semantics.
+func (s *RedisRefreshTokenStore) deleteSession(ctx context.Context, sessionID string) {
+	idKey := "refresh:id:" + sessionID
+	if tokenHash, err := s.rdb.Get(ctx, idKey).Result(); err == nil {
+		s.rdb.Del(ctx, "refresh:"+tokenHash)
+	}
+	s.rdb.Del(ctx, idKey)
+}
+
+// Issue creates a brand new refresh token family for userID.
+func (s *RedisRefreshTokenStore) Issue(userID uuid.UUID, userAgent, ip string) (string, uuid.UUID, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+
+	record := redisRefreshRecord{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  uuid.New(),
+		UserAgent: userAgent,
+		IP:        ip,
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	if err := s.put(token, record); err != nil {
+		return "", uuid.Nil, err
+	}
+
+	return token, record.ID, nil
+}
+
+// Rotate validates the presented refresh token, deletes it, and issues a replacement in the
+// same token family.
+func (s *RedisRefreshTokenStore) Rotate(presented, userAgent, ip string) (newToken string, sessionID uuid.UUID, userID uuid.UUID, err error) {
+	record, err := s.get(presented)
+	if err != nil {
+		return "", uuid.Nil, uuid.Nil, err
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return "", uuid.Nil, uuid.Nil, errors.New("refresh token expired")
+	}
+
+	newToken, err = newOpaqueToken()
+	if err != nil {
+		return "", uuid.Nil, uuid.Nil, err
+	}
+
+	replacement := redisRefreshRecord{
+		ID:        uuid.New(),
+		UserID:    record.UserID,
+		FamilyID:  record.FamilyID,
+		UserAgent: userAgent,
+		IP:        ip,
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	if err := s.put(newToken, replacement); err != nil {
+		return "", uuid.Nil, uuid.Nil, err
+	}
+
+	ctx := context.Background()
+	s.deleteSession(ctx, record.ID.String())
+	s.rdb.SRem(ctx, "refresh:user:"+record.UserID.String(), record.ID.String())
+
+	return newToken, replacement.ID, record.UserID, nil
+}
+
+// Revoke deletes the presented refresh token's records without issuing a replacement
+// (logout). A token that's already gone (expired, already revoked) is a no-op.
+func (s *RedisRefreshTokenStore) Revoke(presented string) error {
+	record, err := s.get(presented)
+	if err != nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	s.deleteSession(ctx, record.ID.String())
+	return s.rdb.SRem(ctx, "refresh:user:"+record.UserID.String(), record.ID.String()).Err()
+}
+
+// RevokeAllForUser deletes every session belonging to userID, then the user's session set
+// itself - the "log out everywhere" operation. Members whose own key has already expired
+// are simply no-ops to delete.
+func (s *RedisRefreshTokenStore) RevokeAllForUser(userID uuid.UUID) error {
+	ctx := context.Background()
+	userSetKey := "refresh:user:" + userID.String()
+
+	ids, err := s.rdb.SMembers(ctx, userSetKey).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		s.deleteSession(ctx, id)
+	}
+	return s.rdb.Del(ctx, userSetKey).Err()
+}
+
+// ListSessions returns userID's still-live sessions. A member of the user's session set
+// whose underlying key has already expired is silently skipped rather than reported.
+func (s *RedisRefreshTokenStore) ListSessions(userID uuid.UUID) ([]Session, error) {
+	ctx := context.Background()
+	ids, err := s.rdb.SMembers(ctx, "refresh:user:"+userID.String()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(ids))
+	for _, id := range ids {
+		tokenHash, err := s.rdb.Get(ctx, "refresh:id:"+id).Result()
+		if err != nil {
+			continue
+		}
+		raw, err := s.rdb.Get(ctx, "refresh:"+tokenHash).Bytes()
+		if err != nil {
+			continue
+		}
+		var record redisRefreshRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			continue
+		}
+		sessions = append(sessions, Session{
+			ID:        record.ID,
+			UserAgent: record.UserAgent,
+			IP:        record.IP,
+			ExpiresAt: record.ExpiresAt,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes the single session sessionID, scoped to userID.
+func (s *RedisRefreshTokenStore) RevokeSession(userID, sessionID uuid.UUID) error {
+	ctx := context.Background()
+	userSetKey := "refresh:user:" + userID.String()
+
+	isMember, err := s.rdb.SIsMember(ctx, userSetKey, sessionID.String()).Result()
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return errors.New("session not found")
+	}
+
+	s.deleteSession(ctx, sessionID.String())
+	return s.rdb.SRem(ctx, userSetKey, sessionID.String()).Err()
+}
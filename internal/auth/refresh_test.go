@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDenylistAccessToken(t *testing.T) {
+	t.Run("Falls back to an in-memory store without a configured Redis client", func(t *testing.T) {
+		SetDenylistClient(nil)
+		t.Cleanup(func() { SetRevocationStore(nil) })
+
+		if isAccessRevoked("fallback-jti") {
+			t.Fatal("expected isAccessRevoked to be false before revoking")
+		}
+		if err := DenylistAccessToken("fallback-jti", time.Minute); err != nil {
+			t.Errorf("expected no error revoking without a Redis client, got %v", err)
+		}
+		if !isAccessRevoked("fallback-jti") {
+			t.Error("expected isAccessRevoked to be true after revoking via the in-memory fallback")
+		}
+	})
+
+	t.Run("Empty jti or non-positive TTL is a no-op", func(t *testing.T) {
+		if err := DenylistAccessToken("", time.Minute); err != nil {
+			t.Errorf("expected no error for empty jti, got %v", err)
+		}
+		if err := DenylistAccessToken("some-jti", 0); err != nil {
+			t.Errorf("expected no error for zero TTL, got %v", err)
+		}
+	})
+}
+
+func TestNewOpaqueTokenIsUniqueAndNonEmpty(t *testing.T) {
+	first, err := newOpaqueToken()
+	if err != nil {
+		t.Fatalf("newOpaqueToken() error = %v", err)
+	}
+	second, err := newOpaqueToken()
+	if err != nil {
+		t.Fatalf("newOpaqueToken() error = %v", err)
+	}
+
+	if first == "" || second == "" {
+		t.Fatal("expected non-empty opaque tokens")
+	}
+	if first == second {
+		t.Error("expected two generated tokens to differ")
+	}
+}
+
+func TestHashTokenIsDeterministicAndDoesNotLeakPlaintext(t *testing.T) {
+	token := "some-refresh-token"
+
+	hashed := hashToken(token)
+	if hashed == token {
+		t.Error("hashToken() must not return the plaintext token")
+	}
+	if hashed != hashToken(token) {
+		t.Error("hashToken() must be deterministic for the same input")
+	}
+	if hashToken("a-different-token") == hashed {
+		t.Error("hashToken() should not collide for distinct inputs")
+	}
+}
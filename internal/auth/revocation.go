@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+)
+
+// RevocationStore tracks revoked JWT jtis so ValidateJWT can reject a token before its
+// natural expiry (logout, reuse detection, admin-forced sign-out). Implementations only
+// need to remember a jti until ttl has elapsed; anything older can be forgotten.
+type RevocationStore interface {
+	Revoke(jti string, ttl time.Duration) error
+	IsRevoked(jti string) bool
+}
+
+// revocationStore is the process-wide store consulted by ValidateJWT. It defaults to an
+// InMemoryRevocationStore so revocation works out of the box on a single instance; call
+// SetRevocationStore with a PostgresRevocationStore (or a Redis-backed one, see
+// SetDenylistClient) to share revocations across replicas.
+var revocationStore RevocationStore = NewInMemoryRevocationStore()
+
+// SetRevocationStore replaces the store consulted by ValidateJWT and DenylistAccessToken.
+func SetRevocationStore(store RevocationStore) {
+	if store == nil {
+		store = NewInMemoryRevocationStore()
+	}
+	revocationStore = store
+}
+
+// InMemoryRevocationStore is a process-local RevocationStore. It's the default so a single
+// instance behaves correctly with no external dependencies, but it does not share state
+// across replicas and forgets everything on restart.
+type InMemoryRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewInMemoryRevocationStore creates an empty InMemoryRevocationStore.
+func NewInMemoryRevocationStore() *InMemoryRevocationStore {
+	return &InMemoryRevocationStore{revoked: make(map[string]time.Time)}
+}
+
+func (s *InMemoryRevocationStore) Revoke(jti string, ttl time.Duration) error {
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[jti] = time.Now().Add(ttl)
+	return nil
+}
+
+func (s *InMemoryRevocationStore) IsRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt, ok := s.revoked[jti]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expiresAt) {
+		delete(s.revoked, jti)
+		return false
+	}
+	return true
+}
+
+// PostgresRevocationStore persists revoked jtis so they survive restarts and are visible
+// to every instance sharing the database, at the cost of a round trip per ValidateJWT call.
+type PostgresRevocationStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresRevocationStore creates a PostgresRevocationStore backed by db.
+func NewPostgresRevocationStore(db *gorm.DB) *PostgresRevocationStore {
+	return &PostgresRevocationStore{db: db}
+}
+
+func (s *PostgresRevocationStore) Revoke(jti string, ttl time.Duration) error {
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	record := models.RevokedToken{JTI: jti, ExpiresAt: time.Now().Add(ttl)}
+	return s.db.Save(&record).Error
+}
+
+func (s *PostgresRevocationStore) IsRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+	var record models.RevokedToken
+	err := s.db.WithContext(context.Background()).
+		Where("jti = ? AND expires_at > ?", jti, time.Now()).
+		First(&record).Error
+	return err == nil
+}
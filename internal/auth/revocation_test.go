@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInMemoryRevocationStoreRevokeAndExpire(t *testing.T) {
+	store := NewInMemoryRevocationStore()
+
+	if store.IsRevoked("jti-1") {
+		t.Fatal("expected an unrevoked jti to report false")
+	}
+
+	if err := store.Revoke("jti-1", time.Minute); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if !store.IsRevoked("jti-1") {
+		t.Error("expected jti-1 to be revoked")
+	}
+
+	if err := store.Revoke("jti-2", -time.Second); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if store.IsRevoked("jti-2") {
+		t.Error("expected a non-positive TTL revoke to be a no-op")
+	}
+}
+
+func TestInMemoryRevocationStoreForgetsPastExpiry(t *testing.T) {
+	store := NewInMemoryRevocationStore()
+
+	if err := store.Revoke("jti-3", time.Millisecond); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if store.IsRevoked("jti-3") {
+		t.Error("expected a revocation past its TTL to no longer be reported as revoked")
+	}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// GenerateSecureToken returns a random 32-byte token (base64url-encoded, suitable for
+// emailing or returning in a URL) and the hex-encoded SHA-256 hash that should be persisted
+// instead of the plaintext - used by email verification and password reset, which both need
+// a single-use, emailable token without storing it in a form a leaked database row could
+// replay directly.
+func GenerateSecureToken() (plaintext string, hash string, err error) {
+	plaintext, err = newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	return plaintext, HashSecureToken(plaintext), nil
+}
+
+// HashSecureToken hashes a token produced by GenerateSecureToken so it can be compared
+// against the persisted hash.
+func HashSecureToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RecoveryCodeCount is how many single-use recovery codes are issued on 2FA enrollment.
+const RecoveryCodeCount = 10
+
+// GenerateTOTPSecret creates a new per-user TOTP secret and its otpauth:// enrollment URI.
+func GenerateTOTPSecret(accountEmail string) (secret string, otpauthURI string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      "tundra",
+		AccountName: accountEmail,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	return key.Secret(), key.URL(), nil
+}
+
+// GenerateQRCodePNG renders an otpauth:// URI as a PNG QR code suitable for returning
+// as a base64 string for an authenticator app to scan.
+func GenerateQRCodePNG(otpauthURI string) ([]byte, error) {
+	return qrcode.Encode(otpauthURI, qrcode.Medium, 256)
+}
+
+// ValidateTOTPCode checks a 6-digit code against secret, allowing the default ±1 step window.
+func ValidateTOTPCode(secret, code string) bool {
+	return totp.Validate(code, secret)
+}
+
+// EncryptTOTPSecret encrypts plaintext (a base32 TOTP secret) with AES-GCM using the key
+// from the TOTP_ENCRYPTION_KEY env var (32 raw bytes, base64-encoded) so it is never stored
+// in the clear.
+func EncryptTOTPSecret(plaintext string) (string, error) {
+	block, err := totpCipherBlock()
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptTOTPSecret reverses EncryptTOTPSecret.
+func DecryptTOTPSecret(encoded string) (string, error) {
+	block, err := totpCipherBlock()
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(raw) < nonceSize {
+		return "", errors.New("encrypted TOTP secret is too short")
+	}
+
+	nonce, ciphertext := raw[:nonceSize], raw[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+func totpCipherBlock() (cipher.Block, error) {
+	rawKey := os.Getenv("TOTP_ENCRYPTION_KEY")
+	if rawKey == "" {
+		return nil, errors.New("TOTP_ENCRYPTION_KEY not set")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(rawKey)
+	if err != nil {
+		return nil, fmt.Errorf("TOTP_ENCRYPTION_KEY must be base64-encoded: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, errors.New("TOTP_ENCRYPTION_KEY must decode to 32 bytes for AES-256")
+	}
+
+	return aes.NewCipher(key)
+}
+
+// GenerateRecoveryCodes returns RecoveryCodeCount single-use plaintext codes (shown to the
+// user exactly once) and their bcrypt hashes (what gets persisted).
+func GenerateRecoveryCodes() (plaintext []string, hashed []string, err error) {
+	plaintext = make([]string, 0, RecoveryCodeCount)
+	hashed = make([]string, 0, RecoveryCodeCount)
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := randomRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		plaintext = append(plaintext, code)
+		hashed = append(hashed, string(hash))
+	}
+
+	return plaintext, hashed, nil
+}
+
+// CheckRecoveryCode reports whether candidate matches the given bcrypt hash.
+func CheckRecoveryCode(hash, candidate string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(candidate)) == nil
+}
+
+const recoveryCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// randomRecoveryCode generates an 10-character code split as XXXXX-XXXXX for readability.
+func randomRecoveryCode() (string, error) {
+	var buf bytes.Buffer
+	raw := make([]byte, 10)
+	if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+		return "", err
+	}
+
+	for i, b := range raw {
+		if i == 5 {
+			buf.WriteByte('-')
+		}
+		buf.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TOTPReplayWindow is how long a consumed TOTP code is remembered, which must be at least
+// the ±1 step window ValidateTOTPCode accepts (90s) so a captured code can't be replayed
+// for the rest of its validity period.
+const TOTPReplayWindow = 90 * time.Second
+
+// TOTPReplayGuard remembers which TOTP codes a user has already consumed so the same
+// 6-digit code can't be replayed within its validity window. Implementations only need to
+// remember a (userID, code) pair until TOTPReplayWindow has elapsed.
+type TOTPReplayGuard interface {
+	MarkUsed(userID, code string) error
+	WasUsed(userID, code string) bool
+}
+
+// totpReplayGuard is the process-wide guard consulted during the 2FA challenge. It defaults
+// to an InMemoryTOTPReplayGuard so replay protection works out of the box on a single
+// instance; call SetTOTPReplayGuard with a Redis-backed guard to share it across replicas.
+var totpReplayGuard TOTPReplayGuard = NewInMemoryTOTPReplayGuard()
+
+// SetTOTPReplayGuard replaces the guard consulted by CheckTOTPReplay.
+func SetTOTPReplayGuard(guard TOTPReplayGuard) {
+	if guard == nil {
+		guard = NewInMemoryTOTPReplayGuard()
+	}
+	totpReplayGuard = guard
+}
+
+// SetTOTPReplayClient wires a Redis-backed TOTPReplayGuard, used to track consumed TOTP
+// codes across every API instance. Call it once during server startup; without it, replay
+// protection falls back to an in-memory guard that only covers the current process.
+func SetTOTPReplayClient(rdb *redis.Client) {
+	if rdb == nil {
+		SetTOTPReplayGuard(nil)
+		return
+	}
+	SetTOTPReplayGuard(NewRedisTOTPReplayGuard(rdb))
+}
+
+// CheckTOTPReplay reports whether code has already been consumed by userID within
+// TOTPReplayWindow and, if not, marks it as used. It returns true when the code is fresh
+// (i.e. the caller may proceed to accept it); it returns false if the code was already used.
+func CheckTOTPReplay(userID, code string) bool {
+	if totpReplayGuard.WasUsed(userID, code) {
+		return false
+	}
+	totpReplayGuard.MarkUsed(userID, code)
+	return true
+}
+
+// InMemoryTOTPReplayGuard is a process-local TOTPReplayGuard. It's the default so a single
+// instance behaves correctly with no external dependencies, but it does not share state
+// across replicas and forgets everything on restart.
+type InMemoryTOTPReplayGuard struct {
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+// NewInMemoryTOTPReplayGuard creates an empty InMemoryTOTPReplayGuard.
+func NewInMemoryTOTPReplayGuard() *InMemoryTOTPReplayGuard {
+	return &InMemoryTOTPReplayGuard{used: make(map[string]time.Time)}
+}
+
+func (g *InMemoryTOTPReplayGuard) MarkUsed(userID, code string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.used[userID+":"+code] = time.Now().Add(TOTPReplayWindow)
+	return nil
+}
+
+func (g *InMemoryTOTPReplayGuard) WasUsed(userID, code string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	key := userID + ":" + code
+	expiresAt, ok := g.used[key]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expiresAt) {
+		delete(g.used, key)
+		return false
+	}
+	return true
+}
+
+// RedisTOTPReplayGuard is a TOTPReplayGuard backed by Redis, suitable for sharing consumed
+// TOTP codes across every API instance.
+type RedisTOTPReplayGuard struct {
+	rdb *redis.Client
+}
+
+// NewRedisTOTPReplayGuard creates a RedisTOTPReplayGuard backed by rdb.
+func NewRedisTOTPReplayGuard(rdb *redis.Client) *RedisTOTPReplayGuard {
+	return &RedisTOTPReplayGuard{rdb: rdb}
+}
+
+func (g *RedisTOTPReplayGuard) MarkUsed(userID, code string) error {
+	return g.rdb.Set(context.Background(), "totp:used:"+userID+":"+code, "1", TOTPReplayWindow).Err()
+}
+
+func (g *RedisTOTPReplayGuard) WasUsed(userID, code string) bool {
+	exists, err := g.rdb.Exists(context.Background(), "totp:used:"+userID+":"+code).Result()
+	return err == nil && exists > 0
+}
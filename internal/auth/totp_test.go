@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, otpauthURL, err := GenerateTOTPSecret("test@example.com")
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+
+	if secret == "" {
+		t.Error("expected a non-empty secret")
+	}
+	if otpauthURL == "" {
+		t.Error("expected a non-empty otpauth:// URI")
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	secret, _, err := GenerateTOTPSecret("test@example.com")
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+
+	t.Run("Rejects an obviously invalid code", func(t *testing.T) {
+		if ValidateTOTPCode(secret, "000000") {
+			t.Error("expected a fixed bogus code to fail validation")
+		}
+	})
+
+	t.Run("Rejects a malformed code", func(t *testing.T) {
+		if ValidateTOTPCode(secret, "not-a-code") {
+			t.Error("expected a malformed code to fail validation")
+		}
+	})
+}
+
+func TestEncryptDecryptTOTPSecret(t *testing.T) {
+	os.Setenv("TOTP_ENCRYPTION_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=") // 32 raw bytes, base64
+	defer os.Unsetenv("TOTP_ENCRYPTION_KEY")
+
+	secret := "JBSWY3DPEHPK3PXP"
+
+	encrypted, err := EncryptTOTPSecret(secret)
+	if err != nil {
+		t.Fatalf("EncryptTOTPSecret() error = %v", err)
+	}
+	if encrypted == secret {
+		t.Error("expected the encrypted secret to differ from the plaintext")
+	}
+
+	decrypted, err := DecryptTOTPSecret(encrypted)
+	if err != nil {
+		t.Fatalf("DecryptTOTPSecret() error = %v", err)
+	}
+	if decrypted != secret {
+		t.Errorf("expected decrypted secret %q, got %q", secret, decrypted)
+	}
+
+	t.Run("Fails without a configured key", func(t *testing.T) {
+		os.Unsetenv("TOTP_ENCRYPTION_KEY")
+		defer os.Setenv("TOTP_ENCRYPTION_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+
+		if _, err := EncryptTOTPSecret(secret); err == nil {
+			t.Error("expected an error when TOTP_ENCRYPTION_KEY is not set")
+		}
+	})
+}
+
+func TestCheckTOTPReplay(t *testing.T) {
+	defer SetTOTPReplayGuard(nil)
+	SetTOTPReplayGuard(NewInMemoryTOTPReplayGuard())
+
+	if !CheckTOTPReplay("user-1", "123456") {
+		t.Error("expected the first use of a code to be accepted")
+	}
+	if CheckTOTPReplay("user-1", "123456") {
+		t.Error("expected a replayed code to be rejected")
+	}
+
+	t.Run("Same code is independent per user", func(t *testing.T) {
+		if !CheckTOTPReplay("user-2", "123456") {
+			t.Error("expected a code already used by another user to still be accepted")
+		}
+	})
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	plaintext, hashed, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+
+	if len(plaintext) != RecoveryCodeCount || len(hashed) != RecoveryCodeCount {
+		t.Fatalf("expected %d recovery codes, got %d plaintext and %d hashed", RecoveryCodeCount, len(plaintext), len(hashed))
+	}
+
+	seen := make(map[string]bool)
+	for i, code := range plaintext {
+		if seen[code] {
+			t.Errorf("expected recovery codes to be unique, found duplicate %q", code)
+		}
+		seen[code] = true
+
+		if !CheckRecoveryCode(hashed[i], code) {
+			t.Errorf("expected CheckRecoveryCode to accept the matching plaintext for code %d", i)
+		}
+		if CheckRecoveryCode(hashed[i], "wrong-code") {
+			t.Errorf("expected CheckRecoveryCode to reject a non-matching code for code %d", i)
+		}
+	}
+}
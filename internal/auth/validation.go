@@ -2,47 +2,77 @@ package auth
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"regexp"
 	"unicode"
 )
 
-// ValidatePassword checks if password meets all requirements
+// longPassphraseLength is the length at which a password is treated as a passphrase and
+// exempted from the character-class floor below, matching how ScorePassword already
+// rewards long passwords over short ones with every character class crammed in.
+const longPassphraseLength = 16
+
+// DefaultMinPasswordScore is the minimum ScorePassword result ValidatePassword accepts.
+const DefaultMinPasswordScore = 3
+
+// ValidatePassword checks if password meets all requirements: a character-class floor
+// for anything shorter than a passphrase, and a strength score floor for everything,
+// catching common/guessable passwords that technically satisfy the character classes.
 func ValidatePassword(password string) error {
 	if len(password) < 8 {
 		return errors.New("password must be at least 8 characters long")
 	}
 
-	var (
-		hasUpper   = false
-		hasLower   = false
-		hasNumber  = false
-		hasSpecial = false
-	)
-
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsNumber(char):
-			hasNumber = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
+	if len(password) < longPassphraseLength {
+		var (
+			hasUpper   = false
+			hasLower   = false
+			hasNumber  = false
+			hasSpecial = false
+		)
+
+		for _, char := range password {
+			switch {
+			case unicode.IsUpper(char):
+				hasUpper = true
+			case unicode.IsLower(char):
+				hasLower = true
+			case unicode.IsNumber(char):
+				hasNumber = true
+			case unicode.IsPunct(char) || unicode.IsSymbol(char):
+				hasSpecial = true
+			}
 		}
-	}
 
-	if !hasUpper {
-		return errors.New("password must include at least one uppercase letter (A-Z)")
-	}
-	if !hasLower {
-		return errors.New("password must include at least one lowercase letter (a-z)")
+		if !hasUpper {
+			return errors.New("password must include at least one uppercase letter (A-Z)")
+		}
+		if !hasLower {
+			return errors.New("password must include at least one lowercase letter (a-z)")
+		}
+		if !hasNumber {
+			return errors.New("password must include at least one number (0-9)")
+		}
+		if !hasSpecial {
+			return errors.New("password must include at least one special character (e.g., !@#$%^&*)")
+		}
 	}
-	if !hasNumber {
-		return errors.New("password must include at least one number (0-9)")
+
+	if feedback := ScorePassword(password); feedback.Score < DefaultMinPasswordScore {
+		if feedback.Warning != "" {
+			return errors.New("password is too weak: " + feedback.Warning)
+		}
+		return errors.New("password is too weak: choose something less predictable")
 	}
-	if !hasSpecial {
-		return errors.New("password must include at least one special character (e.g., !@#$%^&*)")
+
+	// Set SKIP_BREACH_CHECK=true for offline dev/CI where the Have I Been Pwned API isn't
+	// reachable. A request error (API down, timeout) fails open rather than blocking every
+	// signup on a third party's availability - see hibpBreachChecker.Check.
+	if os.Getenv("SKIP_BREACH_CHECK") != "true" {
+		if breached, count, err := breachChecker.Check(password); err == nil && breached {
+			return fmt.Errorf("password appears in known breach corpora (seen %d times)", count)
+		}
 	}
 
 	return nil
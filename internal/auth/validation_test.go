@@ -4,7 +4,23 @@ import (
 	"testing"
 )
 
+// fakeBreachChecker lets tests control which passwords ValidatePassword treats as breached
+// without hitting the real Have I Been Pwned API.
+type fakeBreachChecker struct {
+	breachedCounts map[string]int
+}
+
+func (f *fakeBreachChecker) Check(password string) (bool, int, error) {
+	count, ok := f.breachedCounts[password]
+	return ok, count, nil
+}
+
 func TestValidatePassword(t *testing.T) {
+	SetBreachChecker(&fakeBreachChecker{breachedCounts: map[string]int{
+		"Br3ached#Corpus99": 42,
+	}})
+	defer SetBreachChecker(nil)
+
 	tests := []struct {
 		name     string
 		password string
@@ -12,9 +28,14 @@ func TestValidatePassword(t *testing.T) {
 	}{
 		{
 			name:     "Valid password",
-			password: "Password123!",
+			password: "Gl1mmer#Foxtrot9",
 			wantErr:  false,
 		},
+		{
+			name:     "Found in breach corpus",
+			password: "Br3ached#Corpus99",
+			wantErr:  true,
+		},
 		{
 			name:     "Too short",
 			password: "Pass1!",
@@ -40,6 +61,21 @@ func TestValidatePassword(t *testing.T) {
 			password: "Password123",
 			wantErr:  true,
 		},
+		{
+			name:     "Common password with digits and symbol appended",
+			password: "Password123!",
+			wantErr:  true,
+		},
+		{
+			name:     "Dictionary word only",
+			password: "Superman1!",
+			wantErr:  true,
+		},
+		{
+			name:     "Long passphrase without special characters",
+			password: "correct horse battery staple zebra",
+			wantErr:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -52,6 +88,19 @@ func TestValidatePassword(t *testing.T) {
 	}
 }
 
+func TestValidatePassword_SkipBreachCheck(t *testing.T) {
+	SetBreachChecker(&fakeBreachChecker{breachedCounts: map[string]int{
+		"Br3ached#Corpus99": 42,
+	}})
+	defer SetBreachChecker(nil)
+
+	t.Setenv("SKIP_BREACH_CHECK", "true")
+
+	if err := ValidatePassword("Br3ached#Corpus99"); err != nil {
+		t.Errorf("ValidatePassword() with SKIP_BREACH_CHECK=true should not consult the breach checker, got error = %v", err)
+	}
+}
+
 func TestValidateUsername(t *testing.T) {
 	tests := []struct {
 		name     string
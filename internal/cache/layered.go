@@ -0,0 +1,163 @@
+// Package cache provides a two-tier cache fronting Redis with an in-process Ristretto
+// cache, so hot keys are served without a network round trip or JSON deserialization.
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/redis/go-redis/v9"
+)
+
+// invalidateChannel is the Redis pub/sub channel Invalidate publishes to, so every replica's
+// L1 evicts a key that this instance invalidated - L2 (Redis) is already shared, but each
+// replica's in-process L1 is not.
+const invalidateChannel = "cache:invalidate"
+
+// l1EntryTTL caps how long an entry may live in L1, independent of its L2 ttl, so a
+// replica that misses an invalidation can't serve a stale value indefinitely.
+const l1EntryTTL = 5 * time.Minute
+
+// Tier identifies which layer served a Get, for callers that want to track hit rates.
+type Tier int
+
+const (
+	TierMiss Tier = iota
+	TierL1
+	TierL2
+)
+
+// Layered is a two-tier cache: a fixed-size in-process Ristretto L1 in front of a shared
+// Redis L2. Get checks L1 first, falling back to L2 and repopulating L1 on an L2 hit.
+// Invalidate clears both tiers for this instance and broadcasts the key on
+// invalidateChannel so other replicas evict it from their own L1.
+//
+// A nil Redis client degrades Layered to an L1-only cache, the same "Redis unavailable"
+// fallback used elsewhere in this repo (see auth.SetDenylistClient) - entries simply
+// won't be shared across replicas or survive a restart.
+type Layered struct {
+	l1     *ristretto.Cache
+	rdb    *redis.Client
+	cancel context.CancelFunc
+}
+
+// NewLayered creates a Layered cache whose L1 is sized to roughly maxCostBytes (Ristretto's
+// "cost" unit; this package uses byte length as cost), backed by rdb. If rdb is non-nil it
+// also starts a background subscriber on invalidateChannel; call Close to stop it.
+func NewLayered(rdb *redis.Client, maxCostBytes int64) (*Layered, error) {
+	l1, err := ristretto.NewCache(&ristretto.Config{
+		// Ristretto recommends ~10x NumCounters to MaxCost for good hit-ratio estimation.
+		NumCounters: maxCostBytes / 100 * 10,
+		MaxCost:     maxCostBytes,
+		BufferItems: 64,
+		Metrics:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Layered{l1: l1, rdb: rdb}
+
+	if rdb != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		l.cancel = cancel
+		go l.subscribeInvalidations(ctx)
+	}
+
+	return l, nil
+}
+
+func (l *Layered) subscribeInvalidations(ctx context.Context) {
+	sub := l.rdb.Subscribe(ctx, invalidateChannel)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			l.l1.Del(msg.Payload)
+		}
+	}
+}
+
+// Get checks L1, then L2 (populating L1 on an L2 hit). ok is false when key was found in
+// neither tier.
+func (l *Layered) Get(ctx context.Context, key string) (val []byte, tier Tier, ok bool) {
+	if cached, found := l.l1.Get(key); found {
+		return cached.([]byte), TierL1, true
+	}
+
+	if l.rdb == nil {
+		return nil, TierMiss, false
+	}
+
+	data, err := l.rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, TierMiss, false
+	}
+
+	l.l1.SetWithTTL(key, data, int64(len(data)), l1EntryTTL)
+	return data, TierL2, true
+}
+
+// Set writes val to both tiers. The L1 copy's ttl is capped at l1EntryTTL so it can never
+// meaningfully outlive L2's copy even if ttl is much longer.
+func (l *Layered) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	l1TTL := ttl
+	if l1TTL > l1EntryTTL || l1TTL <= 0 {
+		l1TTL = l1EntryTTL
+	}
+	l.l1.SetWithTTL(key, val, int64(len(val)), l1TTL)
+
+	if l.rdb == nil {
+		return nil
+	}
+	return l.rdb.Set(ctx, key, val, ttl).Err()
+}
+
+// Invalidate evicts key from both tiers and publishes it on invalidateChannel so every other
+// replica's L1 evicts it too.
+func (l *Layered) Invalidate(ctx context.Context, key string) error {
+	l.l1.Del(key)
+
+	if l.rdb == nil {
+		return nil
+	}
+
+	if err := l.rdb.Del(ctx, key).Err(); err != nil {
+		return err
+	}
+	return l.rdb.Publish(ctx, invalidateChannel, key).Err()
+}
+
+// InvalidatePrefix invalidates every L2 key matching prefix+"*", the same pattern-based
+// invalidation the product cache used before Layered existed. It's O(n) in matching keys
+// since each one is invalidated (and broadcast) individually - Ristretto has no way to
+// enumerate or wildcard-evict L1 entries.
+func (l *Layered) InvalidatePrefix(ctx context.Context, prefix string) error {
+	if l.rdb == nil {
+		return nil
+	}
+
+	iter := l.rdb.Scan(ctx, 0, prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		if err := l.Invalidate(ctx, iter.Val()); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// Close stops the background invalidation subscriber and releases the L1 cache.
+func (l *Layered) Close() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.l1.Close()
+}
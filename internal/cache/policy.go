@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy describes how a single route's listing cache should behave: how long an entry
+// stays fresh, whether caching is on at all, the Redis key namespace it lives in, which
+// extra query parameters should fan a single logical request out into distinct cache
+// entries, and how long a soft-expired entry may still be served while it's refreshed in
+// the background.
+type Policy struct {
+	TTL                  time.Duration
+	Enabled              bool
+	KeyPrefix            string
+	VaryBy               []string
+	StaleWhileRevalidate time.Duration
+}
+
+// PolicyRegistry maps a route name (e.g. "products:list") to the Policy governing it.
+// Policies can be swapped at runtime - RegisterRoutes' handlers look one up on every
+// request rather than caching it, so toggling Enabled takes effect on the next request
+// with no restart.
+type PolicyRegistry struct {
+	mu       sync.RWMutex
+	policies map[string]Policy
+}
+
+// NewPolicyRegistry creates an empty PolicyRegistry.
+func NewPolicyRegistry() *PolicyRegistry {
+	return &PolicyRegistry{policies: make(map[string]Policy)}
+}
+
+// Set registers (or replaces) the policy for route.
+func (r *PolicyRegistry) Set(route string, policy Policy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policies[route] = policy
+}
+
+// Get returns the policy registered for route, if any.
+func (r *PolicyRegistry) Get(route string) (Policy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	policy, ok := r.policies[route]
+	return policy, ok
+}
+
+// DefaultProductsListPolicy is the fallback used when nothing has overridden the
+// "products:list" policy via environment variables.
+func DefaultProductsListPolicy() Policy {
+	return Policy{
+		TTL:                  5 * time.Minute,
+		Enabled:              true,
+		KeyPrefix:            "products:",
+		VaryBy:               []string{"sort"},
+		StaleWhileRevalidate: 30 * time.Second,
+	}
+}
+
+// LoadPolicyRegistryFromEnv builds a PolicyRegistry seeded with this repo's default cache
+// policies, each overridable via CACHE_<ROUTE>_<FIELD> env vars following the same
+// env-configuration convention used for REDIS_ADDR, AUDIT_LOG_PATH, etc.
+func LoadPolicyRegistryFromEnv() *PolicyRegistry {
+	registry := NewPolicyRegistry()
+	registry.Set("products:list", loadProductsListPolicyFromEnv())
+	return registry
+}
+
+func loadProductsListPolicyFromEnv() Policy {
+	policy := DefaultProductsListPolicy()
+
+	if v := os.Getenv("CACHE_PRODUCTS_LIST_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			policy.Enabled = enabled
+		}
+	}
+	if v := os.Getenv("CACHE_PRODUCTS_LIST_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil {
+			policy.TTL = ttl
+		}
+	}
+	if v := os.Getenv("CACHE_PRODUCTS_LIST_SWR"); v != "" {
+		if swr, err := time.ParseDuration(v); err == nil {
+			policy.StaleWhileRevalidate = swr
+		}
+	}
+	if v := os.Getenv("CACHE_PRODUCTS_LIST_VARY_BY"); v != "" {
+		policy.VaryBy = strings.Split(v, ",")
+	}
+
+	return policy
+}
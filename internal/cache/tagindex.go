@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// invalidateTagScript deletes every key tagged with a tag plus the tag's own membership set,
+// atomically. Running this as a single script (rather than SMEMBERS then DEL from Go) closes
+// the race where a concurrent Tag call could SAdd a key to the set in between - by the time
+// the script runs, whatever it reads from SMEMBERS is exactly what it deletes.
+const invalidateTagScript = `
+local members = redis.call('SMEMBERS', KEYS[1])
+for _, key in ipairs(members) do
+    redis.call('DEL', key)
+end
+redis.call('DEL', KEYS[1])
+return #members
+`
+
+// TagIndex tracks which cache keys hold data derived from a given tag (a product id, a
+// category name, a search term, ...), so a write can invalidate precisely the entries it
+// affects - e.g. "product:<id>" or "category:<name>" - instead of wiping the whole
+// products:* namespace on every update.
+//
+// A nil Redis client degrades TagIndex to a no-op, the same fallback used throughout this
+// package when Redis is unavailable: targeted invalidation is simply skipped, since without
+// L2 there's nothing cross-replica left to invalidate.
+type TagIndex struct {
+	rdb    *redis.Client
+	script *redis.Script
+}
+
+// NewTagIndex creates a TagIndex backed by rdb.
+func NewTagIndex(rdb *redis.Client) *TagIndex {
+	return &TagIndex{rdb: rdb, script: redis.NewScript(invalidateTagScript)}
+}
+
+// tagSetKey is the Redis key of the set tracking cache keys tagged with tag.
+func tagSetKey(tag string) string {
+	return "tag:" + tag
+}
+
+// Tag records that cacheKey holds data derived from each of tags, in one pipelined round
+// trip. Call this alongside whatever Layered.Set produced cacheKey.
+func (idx *TagIndex) Tag(ctx context.Context, cacheKey string, tags ...string) error {
+	if idx.rdb == nil || len(tags) == 0 {
+		return nil
+	}
+
+	pipe := idx.rdb.Pipeline()
+	for _, tag := range tags {
+		pipe.SAdd(ctx, tagSetKey(tag), cacheKey)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// InvalidateTag deletes every cache key tagged with tag, plus the tag's own membership set.
+func (idx *TagIndex) InvalidateTag(ctx context.Context, tag string) error {
+	if idx.rdb == nil {
+		return nil
+	}
+	return idx.script.Run(ctx, idx.rdb, []string{tagSetKey(tag)}).Err()
+}
@@ -8,15 +8,37 @@ import (
 	"mime/multipart"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer names the spans UploadImage and DeleteImage start, kept distinct from
+// internal/tracing's own tracer so a span's instrumentation scope always identifies which
+// package emitted it.
+var tracer = otel.Tracer("github.com/GRACENOBLE/tundra/internal/cloudinary")
+
+// allowedImageExtensions are the file types accepted by both UploadImage and InitUpload.
+var allowedImageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+}
+
 // Client wraps the Cloudinary client
 type Client struct {
 	cld *cloudinary.Cloudinary
 	ctx context.Context
+
+	uploadSessions UploadSessionStore
 }
 
 // NewClient creates a new Cloudinary client
@@ -32,25 +54,30 @@ func NewClient() (*Client, error) {
 	cld.Config.URL.Secure = true
 
 	return &Client{
-		cld: cld,
-		ctx: context.Background(),
+		cld:            cld,
+		ctx:            context.Background(),
+		uploadSessions: NewInMemoryUploadSessionStore(),
 	}, nil
 }
 
+// SetUploadSessionStore replaces the store backing InitUpload/UploadChunk/FinalizeUpload.
+// Defaults to an InMemoryUploadSessionStore; pass a RedisUploadSessionStore so a resumable
+// upload can continue against any replica, not just the one that started it.
+func (c *Client) SetUploadSessionStore(store UploadSessionStore) {
+	if store == nil {
+		store = NewInMemoryUploadSessionStore()
+	}
+	c.uploadSessions = store
+}
+
 // UploadImage uploads an image file to Cloudinary
-// Returns the secure URL of the uploaded image
-func (c *Client) UploadImage(file multipart.File, filename string, folder string) (string, error) {
+// Returns the secure URL of the uploaded image. ctx should come from the incoming request
+// (e.g. c.Request.Context()) rather than context.Background(), so the upload span attaches
+// to that request's trace.
+func (c *Client) UploadImage(ctx context.Context, file multipart.File, filename string, folder string) (string, error) {
 	// Validate file type
 	ext := strings.ToLower(filepath.Ext(filename))
-	allowedExtensions := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".gif":  true,
-		".webp": true,
-	}
-
-	if !allowedExtensions[ext] {
+	if !allowedImageExtensions[ext] {
 		return "", fmt.Errorf("invalid file type: %s. Allowed types: jpg, jpeg, png, gif, webp", ext)
 	}
 
@@ -61,10 +88,14 @@ func (c *Client) UploadImage(file multipart.File, filename string, folder string
 	}
 
 	// Generate a public ID from the filename (without extension)
-	publicID := strings.TrimSuffix(filename, ext)
-	if folder != "" {
-		publicID = folder + "/" + publicID
-	}
+	publicID := publicIDFor(filename, ext, folder)
+
+	ctx, span := tracer.Start(ctx, "cloudinary.UploadImage", trace.WithAttributes(
+		attribute.String("cloudinary.public_id", publicID),
+		attribute.String("cloudinary.folder", folder),
+		attribute.Int("file.size_bytes", len(fileBytes)),
+	))
+	defer span.End()
 
 	// Helper variables for boolean pointers
 	uniqueFilename := true
@@ -74,7 +105,7 @@ func (c *Client) UploadImage(file multipart.File, filename string, folder string
 	reader := bytes.NewReader(fileBytes)
 
 	// Upload to Cloudinary
-	uploadResult, err := c.cld.Upload.Upload(c.ctx, reader, uploader.UploadParams{
+	uploadResult, err := c.cld.Upload.Upload(ctx, reader, uploader.UploadParams{
 		PublicID:       publicID,
 		Folder:         folder,
 		ResourceType:   "image",
@@ -83,20 +114,30 @@ func (c *Client) UploadImage(file multipart.File, filename string, folder string
 	})
 
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return "", fmt.Errorf("failed to upload to Cloudinary: %w", err)
 	}
 
 	return uploadResult.SecureURL, nil
 }
 
-// DeleteImage deletes an image from Cloudinary by public ID
-func (c *Client) DeleteImage(publicID string) error {
-	_, err := c.cld.Upload.Destroy(c.ctx, uploader.DestroyParams{
+// DeleteImage deletes an image from Cloudinary by public ID. ctx should come from the
+// incoming request, for the same reason as UploadImage.
+func (c *Client) DeleteImage(ctx context.Context, publicID string) error {
+	ctx, span := tracer.Start(ctx, "cloudinary.DeleteImage", trace.WithAttributes(
+		attribute.String("cloudinary.public_id", publicID),
+	))
+	defer span.End()
+
+	_, err := c.cld.Upload.Destroy(ctx, uploader.DestroyParams{
 		PublicID:     publicID,
 		ResourceType: "image",
 	})
 
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("failed to delete from Cloudinary: %w", err)
 	}
 
@@ -132,3 +173,93 @@ func ExtractPublicID(url string) string {
 
 	return publicID
 }
+
+// publicIDFor derives the Cloudinary public ID UploadImage and FinalizeUpload use: the
+// filename without its extension, namespaced under folder if one was given.
+func publicIDFor(filename, ext, folder string) string {
+	publicID := strings.TrimSuffix(filename, ext)
+	if folder != "" {
+		publicID = folder + "/" + publicID
+	}
+	return publicID
+}
+
+// InitUpload begins a resumable, chunked upload: it validates filename's extension and
+// returns an uploadID that UploadChunk and FinalizeUpload use to identify this session.
+// Mirrors the distribution blob-upload pattern - a session is created up front, then bytes
+// stream in over however many PATCH requests the client needs, and a final PUT commits.
+func (c *Client) InitUpload(filename, folder string, totalSize int64) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !allowedImageExtensions[ext] {
+		return "", fmt.Errorf("invalid file type: %s. Allowed types: jpg, jpeg, png, gif, webp", ext)
+	}
+	if totalSize <= 0 {
+		return "", fmt.Errorf("totalSize must be positive")
+	}
+
+	id := uuid.NewString()
+	now := time.Now()
+	session := UploadSession{
+		ID:        id,
+		Filename:  filename,
+		Folder:    folder,
+		TotalSize: totalSize,
+		Offset:    0,
+		CreatedAt: now,
+		ExpiresAt: now.Add(uploadSessionIdleTimeout),
+	}
+
+	if err := c.uploadSessions.Create(c.ctx, session); err != nil {
+		return "", fmt.Errorf("failed to create upload session: %w", err)
+	}
+	return id, nil
+}
+
+// UploadChunk appends a sequential byte range to an in-progress upload. offset must equal
+// the number of bytes already received - a mismatch means the client retried or reordered a
+// range, which the caller should surface as an HTTP 416. Returns the upload's new offset.
+func (c *Client) UploadChunk(uploadID string, offset int64, chunk io.Reader) (int64, error) {
+	data, err := io.ReadAll(chunk)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read chunk: %w", err)
+	}
+
+	session, err := c.uploadSessions.Append(c.ctx, uploadID, offset, data)
+	if err != nil {
+		return 0, err
+	}
+	return session.Offset, nil
+}
+
+// FinalizeUpload commits a fully-received chunked upload to Cloudinary and returns its
+// secure URL, the same way UploadImage does for a single-request upload. It fails if fewer
+// bytes have arrived than the session's declared totalSize.
+func (c *Client) FinalizeUpload(uploadID string) (string, error) {
+	session, body, err := c.uploadSessions.Load(c.ctx, uploadID)
+	if err != nil {
+		return "", err
+	}
+	if session.Offset != session.TotalSize {
+		return "", fmt.Errorf("upload incomplete: received %d of %d bytes", session.Offset, session.TotalSize)
+	}
+
+	ext := strings.ToLower(filepath.Ext(session.Filename))
+	publicID := publicIDFor(session.Filename, ext, session.Folder)
+
+	uniqueFilename := true
+	overwrite := false
+
+	uploadResult, err := c.cld.Upload.Upload(c.ctx, bytes.NewReader(body), uploader.UploadParams{
+		PublicID:       publicID,
+		Folder:         session.Folder,
+		ResourceType:   "image",
+		UniqueFilename: &uniqueFilename,
+		Overwrite:      &overwrite,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to Cloudinary: %w", err)
+	}
+
+	_ = c.uploadSessions.Delete(c.ctx, uploadID)
+	return uploadResult.SecureURL, nil
+}
@@ -0,0 +1,214 @@
+package cloudinary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// uploadSessionIdleTimeout is how long a chunked upload session may sit without receiving a
+// chunk before it's considered abandoned and eligible for cleanup.
+const uploadSessionIdleTimeout = 30 * time.Minute
+
+// ErrUploadSessionNotFound is returned when a chunk or finalize request names an upload ID
+// the store doesn't know about (never created, already finalized, or expired).
+var ErrUploadSessionNotFound = errors.New("upload session not found")
+
+// ErrOutOfOrderChunk is returned when a chunk's offset doesn't match the session's current
+// offset - the caller should translate this into an HTTP 416 Range Not Satisfiable.
+var ErrOutOfOrderChunk = errors.New("chunk offset does not match the upload's current offset")
+
+// UploadSession is the per-upload state InitUpload creates and UploadChunk/FinalizeUpload
+// advance: how many bytes have been received so far, and enough about the target file to
+// finish the Cloudinary upload once the last chunk arrives.
+type UploadSession struct {
+	ID        string
+	Filename  string
+	Folder    string
+	TotalSize int64
+	Offset    int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// UploadSessionStore persists chunked-upload session state (metadata plus the bytes
+// received so far) so a resumable upload can survive multiple requests - possibly landing
+// on a different API replica each time, if the store is shared (see
+// NewRedisUploadSessionStore).
+type UploadSessionStore interface {
+	// Create registers a brand-new session at offset 0.
+	Create(ctx context.Context, session UploadSession) error
+	// Append validates that offset matches the session's current offset, appends chunk, and
+	// returns the session's new state. Returns ErrOutOfOrderChunk on a mismatched offset and
+	// ErrUploadSessionNotFound if id is unknown or expired.
+	Append(ctx context.Context, id string, offset int64, chunk []byte) (UploadSession, error)
+	// Load returns the session's current metadata and the full byte buffer received so far.
+	Load(ctx context.Context, id string) (UploadSession, []byte, error)
+	// Delete discards a session's state, once it's been finalized or abandoned.
+	Delete(ctx context.Context, id string) error
+}
+
+// InMemoryUploadSessionStore is a process-local UploadSessionStore. It's the default so
+// chunked uploads work out of the box on a single instance, but an in-flight upload won't
+// survive a restart or resume on a different replica.
+type InMemoryUploadSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*inMemoryUpload
+}
+
+type inMemoryUpload struct {
+	session UploadSession
+	buf     bytes.Buffer
+}
+
+// NewInMemoryUploadSessionStore creates an empty InMemoryUploadSessionStore.
+func NewInMemoryUploadSessionStore() *InMemoryUploadSessionStore {
+	return &InMemoryUploadSessionStore{sessions: make(map[string]*inMemoryUpload)}
+}
+
+func (s *InMemoryUploadSessionStore) Create(ctx context.Context, session UploadSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = &inMemoryUpload{session: session}
+	return nil
+}
+
+func (s *InMemoryUploadSessionStore) Append(ctx context.Context, id string, offset int64, chunk []byte) (UploadSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.sessions[id]
+	if !ok || s.expired(upload) {
+		delete(s.sessions, id)
+		return UploadSession{}, ErrUploadSessionNotFound
+	}
+
+	if offset != upload.session.Offset {
+		return UploadSession{}, ErrOutOfOrderChunk
+	}
+
+	upload.buf.Write(chunk)
+	upload.session.Offset += int64(len(chunk))
+	upload.session.ExpiresAt = time.Now().Add(uploadSessionIdleTimeout)
+	return upload.session, nil
+}
+
+func (s *InMemoryUploadSessionStore) Load(ctx context.Context, id string) (UploadSession, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.sessions[id]
+	if !ok || s.expired(upload) {
+		delete(s.sessions, id)
+		return UploadSession{}, nil, ErrUploadSessionNotFound
+	}
+
+	return upload.session, append([]byte(nil), upload.buf.Bytes()...), nil
+}
+
+func (s *InMemoryUploadSessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *InMemoryUploadSessionStore) expired(upload *inMemoryUpload) bool {
+	return time.Now().After(upload.session.ExpiresAt)
+}
+
+// RedisUploadSessionStore persists chunked-upload sessions in Redis, so an upload started
+// against one API replica can be resumed against another. Metadata is stored as JSON under
+// <id>:meta and the bytes received so far under <id>:data, appended in place with Redis's
+// APPEND command rather than read-modify-written on every chunk; both keys share an expiry
+// refreshed on every chunk so an abandoned upload is cleaned up automatically.
+type RedisUploadSessionStore struct {
+	rdb *redis.Client
+}
+
+// NewRedisUploadSessionStore creates a RedisUploadSessionStore backed by rdb.
+func NewRedisUploadSessionStore(rdb *redis.Client) *RedisUploadSessionStore {
+	return &RedisUploadSessionStore{rdb: rdb}
+}
+
+func uploadMetaKey(id string) string { return fmt.Sprintf("upload:%s:meta", id) }
+func uploadDataKey(id string) string { return fmt.Sprintf("upload:%s:data", id) }
+
+func (s *RedisUploadSessionStore) Create(ctx context.Context, session UploadSession) error {
+	metaJSON, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	pipe := s.rdb.Pipeline()
+	pipe.Set(ctx, uploadMetaKey(session.ID), metaJSON, uploadSessionIdleTimeout)
+	pipe.Set(ctx, uploadDataKey(session.ID), "", uploadSessionIdleTimeout)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisUploadSessionStore) Append(ctx context.Context, id string, offset int64, chunk []byte) (UploadSession, error) {
+	session, err := s.loadMeta(ctx, id)
+	if err != nil {
+		return UploadSession{}, err
+	}
+
+	if offset != session.Offset {
+		return UploadSession{}, ErrOutOfOrderChunk
+	}
+
+	if _, err := s.rdb.Append(ctx, uploadDataKey(id), string(chunk)).Result(); err != nil {
+		return UploadSession{}, err
+	}
+
+	session.Offset += int64(len(chunk))
+	metaJSON, err := json.Marshal(session)
+	if err != nil {
+		return UploadSession{}, err
+	}
+
+	pipe := s.rdb.Pipeline()
+	pipe.Set(ctx, uploadMetaKey(id), metaJSON, uploadSessionIdleTimeout)
+	pipe.Expire(ctx, uploadDataKey(id), uploadSessionIdleTimeout)
+	_, err = pipe.Exec(ctx)
+	return session, err
+}
+
+func (s *RedisUploadSessionStore) Load(ctx context.Context, id string) (UploadSession, []byte, error) {
+	session, err := s.loadMeta(ctx, id)
+	if err != nil {
+		return UploadSession{}, nil, err
+	}
+
+	data, err := s.rdb.Get(ctx, uploadDataKey(id)).Bytes()
+	if err != nil && err != redis.Nil {
+		return UploadSession{}, nil, err
+	}
+
+	return session, data, nil
+}
+
+func (s *RedisUploadSessionStore) Delete(ctx context.Context, id string) error {
+	return s.rdb.Del(ctx, uploadMetaKey(id), uploadDataKey(id)).Err()
+}
+
+func (s *RedisUploadSessionStore) loadMeta(ctx context.Context, id string) (UploadSession, error) {
+	metaJSON, err := s.rdb.Get(ctx, uploadMetaKey(id)).Bytes()
+	if err == redis.Nil {
+		return UploadSession{}, ErrUploadSessionNotFound
+	}
+	if err != nil {
+		return UploadSession{}, err
+	}
+
+	var session UploadSession
+	if err := json.Unmarshal(metaJSON, &session); err != nil {
+		return UploadSession{}, err
+	}
+	return session, nil
+}
@@ -0,0 +1,70 @@
+package cloudinary
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryUploadSessionStore_AppendHappyPath(t *testing.T) {
+	store := NewInMemoryUploadSessionStore()
+	ctx := context.Background()
+
+	session := UploadSession{
+		ID:        "upload-1",
+		Filename:  "photo.jpg",
+		Folder:    "products",
+		TotalSize: 10,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	require.NoError(t, store.Create(ctx, session))
+
+	updated, err := store.Append(ctx, "upload-1", 0, []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), updated.Offset)
+
+	updated, err = store.Append(ctx, "upload-1", 5, []byte("world"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), updated.Offset)
+
+	final, body, err := store.Load(ctx, "upload-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), final.Offset)
+	assert.Equal(t, "helloworld", string(body))
+}
+
+func TestInMemoryUploadSessionStore_OutOfOrderChunkRejected(t *testing.T) {
+	store := NewInMemoryUploadSessionStore()
+	ctx := context.Background()
+
+	session := UploadSession{ID: "upload-2", TotalSize: 10, ExpiresAt: time.Now().Add(time.Minute)}
+	require.NoError(t, store.Create(ctx, session))
+
+	_, err := store.Append(ctx, "upload-2", 4, []byte("oops"))
+	assert.ErrorIs(t, err, ErrOutOfOrderChunk)
+}
+
+func TestInMemoryUploadSessionStore_UnknownSessionNotFound(t *testing.T) {
+	store := NewInMemoryUploadSessionStore()
+	ctx := context.Background()
+
+	_, err := store.Append(ctx, "does-not-exist", 0, []byte("x"))
+	assert.ErrorIs(t, err, ErrUploadSessionNotFound)
+
+	_, _, err = store.Load(ctx, "does-not-exist")
+	assert.ErrorIs(t, err, ErrUploadSessionNotFound)
+}
+
+func TestInMemoryUploadSessionStore_IdleSessionExpires(t *testing.T) {
+	store := NewInMemoryUploadSessionStore()
+	ctx := context.Background()
+
+	session := UploadSession{ID: "upload-3", TotalSize: 10, ExpiresAt: time.Now().Add(-time.Second)}
+	require.NoError(t, store.Create(ctx, session))
+
+	_, err := store.Append(ctx, "upload-3", 0, []byte("x"))
+	assert.ErrorIs(t, err, ErrUploadSessionNotFound)
+}
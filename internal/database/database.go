@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	_ "github.com/joho/godotenv/autoload"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Service is the database connection other packages reach GORM through - auth's stores,
+// the product/order handlers, cmd/seed, and cmd/migrate all construct one via New.
+// cmd/migrate also opens its own *gorm.DB for Go migrations and -action=generate.
+type Service interface {
+	// Health pings the database and reports connection-pool statistics; used by the
+	// server's health check endpoint.
+	Health() map[string]string
+
+	// Close terminates the underlying database connection.
+	Close() error
+
+	// GetDB returns the underlying *gorm.DB for callers that need to run GORM queries
+	// directly rather than going through a narrower interface.
+	GetDB() *gorm.DB
+
+	// Backup streams every row of every registered model to w as newline-delimited JSON
+	// records tagged with their table name.
+	Backup(ctx context.Context, w io.Writer, opts BackupOptions) error
+
+	// Restore reads a dump produced by Backup and reloads it inside a single transaction.
+	Restore(ctx context.Context, r io.Reader, opts RestoreOptions) error
+}
+
+type service struct {
+	db *gorm.DB
+}
+
+var (
+	database = os.Getenv("BLUEPRINT_DB_DATABASE")
+	password = os.Getenv("BLUEPRINT_DB_PASSWORD")
+	username = os.Getenv("BLUEPRINT_DB_USERNAME")
+	port     = os.Getenv("BLUEPRINT_DB_PORT")
+	host     = os.Getenv("BLUEPRINT_DB_HOST")
+	schema   = os.Getenv("BLUEPRINT_DB_SCHEMA")
+	sslmode  = os.Getenv("BLUEPRINT_DB_SSLMODE")
+)
+
+// New opens a GORM connection to Postgres using the BLUEPRINT_DB_* environment variables
+// and returns a Service wrapping it.
+func New() Service {
+	connStr := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s search_path=%s",
+		host, port, username, password, database, sslmode, schema,
+	)
+
+	db, err := gorm.Open(postgres.Open(connStr), &gorm.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &service{db: db}
+}
+
+// GetDB returns the underlying *gorm.DB.
+func (s *service) GetDB() *gorm.DB {
+	return s.db
+}
+
+// Health pings the database and reports connection-pool statistics. The returned map's
+// "status"/"message"/"error" keys are meant to be surfaced directly by an HTTP health
+// check endpoint.
+func (s *service) Health() map[string]string {
+	stats := make(map[string]string)
+
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		stats["status"] = "down"
+		stats["error"] = fmt.Sprintf("failed to get underlying sql.DB: %v", err)
+		return stats
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		stats["status"] = "down"
+		stats["error"] = fmt.Sprintf("db down: %v", err)
+		log.Printf("db down: %v", err)
+		return stats
+	}
+
+	stats["status"] = "up"
+	stats["message"] = "It's healthy"
+
+	dbStats := sqlDB.Stats()
+	stats["open_connections"] = strconv.Itoa(dbStats.OpenConnections)
+	stats["in_use"] = strconv.Itoa(dbStats.InUse)
+	stats["idle"] = strconv.Itoa(dbStats.Idle)
+	stats["wait_count"] = strconv.FormatInt(dbStats.WaitCount, 10)
+	stats["wait_duration"] = dbStats.WaitDuration.String()
+	stats["max_idle_closed"] = strconv.FormatInt(dbStats.MaxIdleClosed, 10)
+	stats["max_lifetime_closed"] = strconv.FormatInt(dbStats.MaxLifetimeClosed, 10)
+
+	if dbStats.OpenConnections > 40 {
+		stats["message"] = "The database is experiencing heavy load."
+	}
+	if dbStats.WaitCount > 1000 {
+		stats["message"] = "The database has a high number of wait events, indicating potential bottlenecks."
+	}
+	if dbStats.MaxIdleClosed > int64(dbStats.OpenConnections)/2 {
+		stats["message"] = "Many idle connections are being closed, consider revising the connection pool settings."
+	}
+	if dbStats.MaxLifetimeClosed > int64(dbStats.OpenConnections)/2 {
+		stats["message"] = "Many connections are being closed due to max lifetime, consider increasing max lifetime."
+	}
+
+	return stats
+}
+
+// Close closes the underlying database connection.
+func (s *service) Close() error {
+	log.Printf("Disconnected from database: %s", database)
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
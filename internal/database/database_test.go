@@ -1,74 +1,32 @@
 package database
 
 import (
-	"context"
-	"log"
 	"testing"
-	"time"
 
-	"github.com/testcontainers/testcontainers-go"
-	"github.com/testcontainers/testcontainers-go/modules/postgres"
-	"github.com/testcontainers/testcontainers-go/wait"
+	"tundra/internal/testsupport/pgtest"
 )
 
-func mustStartPostgresContainer() (func(context.Context, ...testcontainers.TerminateOption) error, error) {
-	var (
-		dbName = "database"
-		dbPwd  = "password"
-		dbUser = "user"
-	)
-
-	dbContainer, err := postgres.Run(
-		context.Background(),
-		"postgres:latest",
-		postgres.WithDatabase(dbName),
-		postgres.WithUsername(dbUser),
-		postgres.WithPassword(dbPwd),
-		testcontainers.WithWaitStrategy(
-			wait.ForLog("database system is ready to accept connections").
-				WithOccurrence(2).
-				WithStartupTimeout(5*time.Second)),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	database = dbName
-	password = dbPwd
-	username = dbUser
-	sslmode = "disable"
-	schema = "public"
-
-	dbHost, err := dbContainer.Host(context.Background())
-	if err != nil {
-		return dbContainer.Terminate, err
-	}
-
-	dbPort, err := dbContainer.MappedPort(context.Background(), "5432/tcp")
-	if err != nil {
-		return dbContainer.Terminate, err
-	}
-
-	host = dbHost
-	port = dbPort.Port()
+// useInstance points New()'s BLUEPRINT_DB_* package vars at inst, so the rest of this file
+// can keep exercising the real New()/Service contract instead of talking to inst.DB directly.
+func useInstance(t *testing.T, inst *pgtest.Instance) {
+	t.Helper()
 
-	return dbContainer.Terminate, err
-}
-
-func TestMain(m *testing.M) {
-	teardown, err := mustStartPostgresContainer()
+	h, p, u, pw, db, ssl, err := pgtest.DSNComponents(inst.DSN)
 	if err != nil {
-		log.Fatalf("could not start postgres container: %v", err)
-	}
-
-	m.Run()
-
-	if teardown != nil && teardown(context.Background()) != nil {
-		log.Fatalf("could not teardown postgres container: %v", err)
-	}
+		t.Fatalf("failed to parse pgtest DSN: %v", err)
+	}
+	host = h
+	port = p
+	username = u
+	password = pw
+	database = db
+	sslmode = ssl
+	schema = "public"
 }
 
 func TestNew(t *testing.T) {
+	useInstance(t, pgtest.StartPostgres(t))
+
 	srv := New()
 	if srv == nil {
 		t.Fatal("New() returned nil")
@@ -76,6 +34,8 @@ func TestNew(t *testing.T) {
 }
 
 func TestHealth(t *testing.T) {
+	useInstance(t, pgtest.StartPostgres(t))
+
 	srv := New()
 
 	stats := srv.Health()
@@ -112,6 +72,8 @@ type TestModel struct {
 }
 
 func TestDatabaseConnection(t *testing.T) {
+	useInstance(t, pgtest.StartPostgres(t))
+
 	srv := New()
 
 	// Get the underlying service to access the GORM DB
@@ -177,6 +139,8 @@ func TestDatabaseConnection(t *testing.T) {
 }
 
 func TestClose(t *testing.T) {
+	useInstance(t, pgtest.StartPostgres(t))
+
 	srv := New()
 
 	if srv.Close() != nil {
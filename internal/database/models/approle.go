@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AppRole is a machine-to-machine credential, AppRole-style (as in HashiCorp Vault): an
+// operator configures RoleID/SecretID into a CI job or worker service in place of a
+// long-lived user JWT, and POST /auth/approle/login exchanges that pair for a
+// short-lived token carrying Scopes instead of a Role/RBAC grant. SecretIDHash is a
+// SHA-256 hash - SecretID is a high-entropy random value rather than a human secret, the
+// same tradeoff RefreshTokenStore makes for refresh tokens. PreviousSecretIDHash/
+// PreviousSecretIDExpiresAt let a rotated-out SecretID keep working until its overlap
+// window elapses, so an in-flight caller isn't locked out mid-rotation.
+type AppRole struct {
+	ID                        uuid.UUID     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	RoleID                    uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex" json:"role_id"`
+	Name                      string        `gorm:"not null" json:"name"`
+	SecretIDHash              string        `gorm:"not null" json:"-"`
+	PreviousSecretIDHash      string        `json:"-"`
+	PreviousSecretIDExpiresAt *time.Time    `json:"-"`
+	Scopes                    string        `gorm:"not null" json:"scopes"`
+	TTL                       time.Duration `gorm:"not null" json:"ttl"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent is a single security-relevant record (login, signup, password change, admin
+// action, ...) written by audit.GORMEmitter. Metadata is stored as a JSON-encoded string;
+// its shape depends on Type.
+type AuditEvent struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Type      string     `gorm:"not null;index" json:"type"`
+	UserID    *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	IP        string     `json:"ip"`
+	UserAgent string     `json:"user_agent"`
+	Metadata  string     `gorm:"type:jsonb" json:"metadata"`
+	RequestID string     `gorm:"index" json:"request_id,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+// Domain is a tenant boundary: every Product belongs to exactly one Domain, and a user's
+// access to it is governed by their DomainMember row rather than a global Role.
+type Domain struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Name        string    `gorm:"not null" json:"name"`
+	OwnerUserID uuid.UUID `gorm:"type:uuid;not null" json:"owner_user_id"`
+}
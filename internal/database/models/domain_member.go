@@ -0,0 +1,14 @@
+package models
+
+import "github.com/google/uuid"
+
+// DomainMember grants a User a Role ("owner", "admin", "member") scoped to a single
+// Domain. This is deliberately separate from the global Role/UserRole RBAC tables: the
+// same user can hold different roles in different domains, and domain roles aren't
+// bundles of Permissions - auth.DomainRoleMiddleware compares the role name directly.
+type DomainMember struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	DomainID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_domain_members_domain_user" json:"domain_id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_domain_members_domain_user" json:"user_id"`
+	Role     string    `gorm:"not null" json:"role"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey records an in-flight or completed request guarded by
+// server.idempotencyMiddleware, scoped per user: a retried POST /orders with the same
+// Idempotency-Key header replays ResponseStatus/ResponseBody instead of running the order
+// transaction again. The (UserID, Key) pair is unique, so a racing duplicate's INSERT fails
+// with a unique violation while the first request still holds the row.
+type IdempotencyKey struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_idempotency_keys_user_key" json:"user_id"`
+	Key            string     `gorm:"not null;uniqueIndex:idx_idempotency_keys_user_key" json:"key"`
+	RequestHash    string     `gorm:"not null" json:"request_hash"`
+	ResponseStatus int        `json:"response_status"`
+	ResponseBody   string     `gorm:"type:jsonb" json:"response_body"`
+	OrderID        *uuid.UUID `gorm:"type:uuid" json:"order_id,omitempty"`
+	LockedAt       time.Time  `gorm:"not null" json:"locked_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+}
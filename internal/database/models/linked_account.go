@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LinkedAccount associates a User with an external identity from an OAuth2/OIDC
+// provider (e.g. Google, GitHub, a generic OIDC issuer), so a user can authenticate via
+// password and/or any number of linked providers. The (Provider, ProviderUserID) pair
+// uniquely identifies the external account, regardless of which local user it's linked to.
+type LinkedAccount struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider       string    `gorm:"not null;uniqueIndex:idx_linked_accounts_provider_external" json:"provider"`
+	ProviderUserID string    `gorm:"not null;uniqueIndex:idx_linked_accounts_provider_external" json:"provider_user_id"`
+	// AvatarURL is a snapshot of the provider's profile picture at link time, if it
+	// returned one; it is not kept in sync with the provider afterwards.
+	AvatarURL string    `gorm:"default:null" json:"avatar_url,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
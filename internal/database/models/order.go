@@ -6,12 +6,26 @@ import (
 	"github.com/google/uuid"
 )
 
+// OrderStatus is an Order's place in its fulfillment lifecycle.
+type OrderStatus string
+
+const (
+	OrderStatusPending         OrderStatus = "pending"
+	OrderStatusAwaitingPayment OrderStatus = "awaiting_payment"
+	OrderStatusPaid            OrderStatus = "paid"
+	OrderStatusFailed          OrderStatus = "failed"
+	OrderStatusFulfilled       OrderStatus = "fulfilled"
+	OrderStatusCancelled       OrderStatus = "cancelled"
+	OrderStatusRefunded        OrderStatus = "refunded"
+	OrderStatusExpired         OrderStatus = "expired"
+)
+
 type Order struct {
 	ID            uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	UserID        uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
 	Description   string         `gorm:"not null" json:"description"`
 	TotalPrice    float64        `gorm:"not null" json:"total_price"`
-	Status        string         `gorm:"not null" json:"status"`
+	Status        OrderStatus    `gorm:"not null" json:"status"`
 	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	OrderProducts []OrderProduct `gorm:"foreignKey:OrderID" json:"order_products,omitempty"`
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is one domain event written in the same transaction as the business change it
+// describes (e.g. orders.Service.Create's commit), so outbox.Relay can publish it to an
+// EventPublisher afterwards without risking the classic "DB committed but message lost"
+// dual-write problem: the write either lands with its transaction or doesn't happen at all.
+// PublishedAt is nil until a Relay successfully publishes it.
+type OutboxEvent struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	AggregateID string     `gorm:"not null;index" json:"aggregate_id"`
+	Type        string     `gorm:"not null" json:"type"`
+	Payload     string     `gorm:"type:jsonb;not null" json:"payload"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "github.com/google/uuid"
+
+// Permission is a single grantable action, keyed by a "resource:action" string (e.g.
+// "products:write", "orders:read"). Middleware checks membership of a user's effective
+// permission set rather than comparing role names directly.
+type Permission struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Key         string    `gorm:"uniqueIndex;not null" json:"key"`
+	Description string    `json:"description"`
+}
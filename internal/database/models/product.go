@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -13,4 +15,6 @@ type Product struct {
 	Category    string    `gorm:"not null" json:"category"`
 	ImageURL    string    `gorm:"default:null" json:"imageUrl,omitempty"`
 	UserID      uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	DomainID    uuid.UUID `gorm:"type:uuid;not null;index" json:"domain_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index" json:"created_at"`
 }
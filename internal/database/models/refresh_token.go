@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is an opaque, rotatable token issued alongside a short-lived access JWT.
+// Tokens are stored hashed; RefreshTokenStore never persists the plaintext value.
+// FamilyID links every token produced by successive rotations of the same login so that
+// reuse of an already-rotated token can revoke the whole chain.
+type RefreshToken struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	FamilyID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"family_id"`
+	HashedToken string     `gorm:"uniqueIndex;not null" json:"-"`
+	UserAgent   string     `json:"user_agent"`
+	IP          string     `json:"ip"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy  *uuid.UUID `gorm:"type:uuid" json:"replaced_by,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
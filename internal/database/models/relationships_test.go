@@ -0,0 +1,64 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"tundra/internal/database/models"
+	"tundra/internal/testsupport/pgtest"
+)
+
+// TestOrderProductRelationships seeds an Order with two OrderProducts referencing real
+// Products and asserts GORM's Preload resolves Order -> OrderProduct -> Product end to end,
+// against a real Postgres rather than sqlite/mock, since the join table's composite primary
+// key and foreign keys are exactly what a mock would paper over.
+func TestOrderProductRelationships(t *testing.T) {
+	inst := pgtest.StartPostgres(t)
+	inst.Reset(t)
+
+	db := inst.DB
+	if err := db.AutoMigrate(&models.Product{}, &models.Order{}, &models.OrderProduct{}); err != nil {
+		t.Fatalf("failed to auto migrate: %v", err)
+	}
+
+	userID := uuid.New()
+	domainID := uuid.New()
+
+	mug := models.Product{Name: "Mug", Description: "Ceramic mug", Price: 9.99, Stock: 10, Category: "kitchen", UserID: userID, DomainID: domainID}
+	pen := models.Product{Name: "Pen", Description: "Ballpoint pen", Price: 1.99, Stock: 100, Category: "office", UserID: userID, DomainID: domainID}
+	if err := db.Create(&mug).Error; err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+	if err := db.Create(&pen).Error; err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+
+	order := models.Order{
+		UserID:      userID,
+		Description: "Office supplies",
+		TotalPrice:  mug.Price + 2*pen.Price,
+		Status:      "pending",
+		OrderProducts: []models.OrderProduct{
+			{ProductID: mug.ID, Quantity: 1, Price: mug.Price},
+			{ProductID: pen.ID, Quantity: 2, Price: pen.Price},
+		},
+	}
+	if err := db.Create(&order).Error; err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+
+	var loaded models.Order
+	if err := db.Preload("OrderProducts.Product").First(&loaded, "id = ?", order.ID).Error; err != nil {
+		t.Fatalf("failed to load order: %v", err)
+	}
+
+	if len(loaded.OrderProducts) != 2 {
+		t.Fatalf("expected 2 order products, got %d", len(loaded.OrderProducts))
+	}
+	for _, op := range loaded.OrderProducts {
+		if op.Product.ID != op.ProductID {
+			t.Fatalf("expected preloaded product %s to match order product's product_id %s", op.Product.ID, op.ProductID)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RevokedToken is a JWT jti that has been revoked before its natural expiry (logout,
+// reuse detection, admin-forced sign-out). Rows can be garbage-collected once ExpiresAt
+// has passed, since the token would be rejected on expiry alone by then.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey" json:"jti"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+// Role is a named bundle of Permissions (e.g. "admin", "user", "readonly") that can be
+// granted to a User via UserRole. Roles are seeded once and rarely change at runtime;
+// what a given Role grants is defined by its RolePermission rows.
+type Role struct {
+	ID   uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Name string    `gorm:"uniqueIndex;not null" json:"name"`
+}
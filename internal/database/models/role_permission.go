@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+// RolePermission grants a Permission to a Role. A Role's effective permission set is
+// every Permission reachable through its RolePermission rows.
+type RolePermission struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	RoleID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_role_permissions_role_permission" json:"role_id"`
+	PermissionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_role_permissions_role_permission" json:"permission_id"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockReservation records the stock createOrderHandler deducted for one order product so
+// server.releaseExpiredReservations can find it again: if the order is still pending or
+// awaiting_payment once ExpiresAt passes, the reservation is released (its quantity added
+// back onto the product, the row deleted) and the order is marked expired. A row stops
+// mattering the moment its order leaves pending/awaiting_payment through any other path
+// (checkout, cancel, webhook) - the cleanup scan filters on the order's current status, not
+// this table, so no explicit deletion is needed on those paths.
+type StockReservation struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrderID   uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null" json:"product_id"`
+	Quantity  int       `gorm:"not null" json:"quantity"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -7,4 +7,21 @@ type User struct {
 	Username string    `gorm:"uniqueIndex;not null" json:"username"`
 	Email    string    `gorm:"uniqueIndex;not null" json:"email"`
 	Password string    `gorm:"not null" json:"-"`
+	Role     string    `gorm:"not null;default:'user'" json:"role"`
+
+	// Two-factor authentication (TOTP). Secrets are stored AES-GCM encrypted at rest;
+	// TwoFactorPendingSecret holds an unconfirmed secret between /2fa/setup and /2fa/verify.
+	TwoFactorEnabled       bool   `gorm:"not null;default:false" json:"two_factor_enabled"`
+	TwoFactorSecret        string `gorm:"default:null" json:"-"`
+	TwoFactorPendingSecret string `gorm:"default:null" json:"-"`
+
+	// LinkedAccounts are the OAuth2/OIDC identities (Google, GitHub, ...) this user has
+	// signed in with, in addition to or instead of a password.
+	LinkedAccounts []LinkedAccount `gorm:"foreignKey:UserID" json:"linked_accounts,omitempty"`
+
+	// Email verification. VerificationTokenHash holds the SHA-256 hash of the token emailed
+	// to the user at signup until GET /auth/verify consumes it; EmailVerified gates login
+	// when EMAIL_VERIFICATION_REQUIRED is set.
+	EmailVerified         bool   `gorm:"not null;default:false" json:"email_verified"`
+	VerificationTokenHash string `gorm:"default:null" json:"-"`
 }
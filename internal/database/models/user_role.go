@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+// UserRole grants a Role to a User. A user's effective permission set is the union of
+// every Permission granted to every Role they hold.
+type UserRole struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_roles_user_role" json:"user_id"`
+	RoleID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_roles_user_role" json:"role_id"`
+}
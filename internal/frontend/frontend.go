@@ -0,0 +1,57 @@
+// Package frontend embeds the built single-page app (dist/, produced by `make frontend`
+// from the ./web project) so the API binary can serve it directly when EMBED_FRONTEND=true,
+// instead of requiring a separately hosted static site and the CORS origin that implies.
+package frontend
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed dist
+var distFS embed.FS
+
+// Enabled reports whether EMBED_FRONTEND is set, the switch RegisterRoutes uses to decide
+// between mounting this embedded SPA (and dropping the hard-coded dev CORS origin) and
+// running API-only behind a separately hosted frontend during local development.
+func Enabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("EMBED_FRONTEND"))
+	return enabled
+}
+
+// Register mounts dist/'s static assets and falls back to index.html for any other GET
+// request, the standard SPA-router pattern: a direct load of /products/123 should reach
+// the client-side router, not the 404 gin's own NoRoute would otherwise produce.
+func Register(r *gin.Engine) {
+	assets, err := fs.Sub(distFS, "dist")
+	if err != nil {
+		panic(err)
+	}
+
+	index, err := fs.ReadFile(assets, "index.html")
+	if err != nil {
+		panic(err)
+	}
+
+	fileServer := http.FileServer(http.FS(assets))
+
+	r.NoRoute(func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		if _, err := fs.Stat(assets, strings.TrimPrefix(c.Request.URL.Path, "/")); err == nil {
+			fileServer.ServeHTTP(c.Writer, c.Request)
+			return
+		}
+
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	})
+}
@@ -0,0 +1,63 @@
+// Package grpcserver exposes the order/product operations already implemented for the REST
+// API (internal/server) as a gRPC service, defined in proto/tundra/v1/*.proto, so a machine
+// client can call CreateOrder/GetOrders/GetOrder/ListProducts/GetProduct/checkout/cancel
+// without going through HTTP+JSON. CreateOrder in particular runs internal/orders.Service.Create
+// - the exact transaction server.createOrderHandler runs - so the two transports can never
+// drift on how an order is placed.
+//
+// The generated stubs this package's handlers implement (ordersv1.OrderServiceServer etc.)
+// come from running `make proto` against proto/tundra/v1 once protoc and the Go protobuf
+// plugins are available; see server.go for where they're registered.
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/GRACENOBLE/tundra/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// claimsContextKey is the context key AuthUnaryInterceptor stashes the validated
+// *auth.Claims under; use ClaimsFromContext to retrieve it.
+type claimsContextKey struct{}
+
+// ClaimsFromContext returns the *auth.Claims AuthUnaryInterceptor validated for this call, the
+// gRPC equivalent of auth.ClaimsFromContext for a gin.Context.
+func ClaimsFromContext(ctx context.Context) (*auth.Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*auth.Claims)
+	return claims, ok
+}
+
+// AuthUnaryInterceptor authenticates every unary RPC's "authorization" metadata value the
+// same way auth.AuthMiddleware authenticates a REST request's Authorization header - both
+// call auth.Authenticate, so a token accepted by one transport is accepted by the other.
+func AuthUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing metadata")
+		}
+
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata must be in format: Bearer <token>")
+		}
+
+		claims, err := auth.Authenticate(ctx, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+
+		return handler(context.WithValue(ctx, claimsContextKey{}, claims), req)
+	}
+}
@@ -0,0 +1,123 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+	"github.com/GRACENOBLE/tundra/internal/orders"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// OrderServiceServer implements the order operations proto/tundra/v1/orders.proto describes.
+// Its methods take and return plain Go/domain types rather than the generated request/response
+// messages directly, so RegisterOrderServiceServer (added once `make proto` produces
+// ordersv1.OrderServiceServer) only has to translate wire messages at the edge instead of
+// duplicating this logic.
+type OrderServiceServer struct {
+	db     *gorm.DB
+	orders *orders.Service
+}
+
+// NewOrderServiceServer wires db and orderService - the same *orders.Service instance
+// server.Server uses for POST /orders, so CreateOrder behaves identically over gRPC and REST.
+func NewOrderServiceServer(db *gorm.DB, orderService *orders.Service) *OrderServiceServer {
+	return &OrderServiceServer{db: db, orders: orderService}
+}
+
+// userIDFromContext pulls the authenticated caller's user ID out of ctx, the gRPC equivalent of
+// a REST handler reading claims.UserID() off the gin.Context auth.AuthMiddleware populated.
+// AuthUnaryInterceptor guarantees ClaimsFromContext succeeds for every call that reaches a
+// method on this service, so ok=false here would mean the interceptor wasn't wired up.
+func userIDFromContext(ctx context.Context) (uuid.UUID, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return uuid.UUID{}, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	userID, err := uuid.Parse(claims.UserID())
+	if err != nil {
+		return uuid.UUID{}, status.Error(codes.Internal, "invalid user id in claims")
+	}
+	return userID, nil
+}
+
+// CreateOrder runs the exact same transaction server.createOrderHandler runs, via the shared
+// orders.Service - the requirement this service exists to satisfy in the first place. The
+// caller is identified from ctx (via AuthUnaryInterceptor's claims), matching how the generated
+// ordersv1.OrderServiceServer.CreateOrder(context.Context, *CreateOrderRequest) stub this
+// adapts to would get it - a request message has no room for a user ID callers don't control.
+func (s *OrderServiceServer) CreateOrder(ctx context.Context, items []orders.Item) (*models.Order, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orders.Create(ctx, userID, items)
+	if err != nil {
+		var notFound *orders.ProductNotFoundError
+		var insufficientStock *orders.InsufficientStockError
+		switch {
+		case errors.Is(err, orders.ErrEmptyOrder):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.As(err, &notFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.As(err, &insufficientStock):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "failed to create order")
+		}
+	}
+	return order, nil
+}
+
+// GetOrder fetches an order by ID, scoped to the caller the same way server.getOrdersHandler
+// scopes every query - a user can only read their own orders.
+func (s *OrderServiceServer) GetOrder(ctx context.Context, orderID uuid.UUID) (*models.Order, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var order models.Order
+	if err := s.db.WithContext(ctx).Preload("OrderProducts.Product").Where("id = ? AND user_id = ?", orderID, userID).First(&order).Error; err != nil {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	return &order, nil
+}
+
+// GetOrders lists the caller's orders newest-first. It doesn't yet implement the keyset cursor
+// server.getOrdersHandler supports - only enough to exercise CreateOrder end-to-end over gRPC.
+func (s *OrderServiceServer) GetOrders(ctx context.Context, limit int) ([]models.Order, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.Order
+	if err := s.db.WithContext(ctx).Preload("OrderProducts.Product").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&results).Error; err != nil {
+		return nil, status.Error(codes.Internal, "failed to list orders")
+	}
+	return results, nil
+}
+
+// CheckoutOrder and CancelOrder aren't wired yet: they depend on
+// server.isValidOrderTransition/restoreOrderStock, which are unexported to internal/server.
+// Moving that transition logic into internal/orders alongside Create (so both this service
+// and the REST handlers share it, the same way CreateOrder does) is a follow-up - returning
+// Unimplemented here rather than re-deriving the transition rules a second time and risking
+// the two copies drifting apart.
+func (s *OrderServiceServer) CheckoutOrder(_ context.Context, _ uuid.UUID) (string, error) {
+	return "", status.Error(codes.Unimplemented, "checkout is not yet available over gRPC")
+}
+
+func (s *OrderServiceServer) CancelOrder(_ context.Context, _ uuid.UUID) (*models.Order, error) {
+	return nil, status.Error(codes.Unimplemented, "cancel is not yet available over gRPC")
+}
@@ -0,0 +1,49 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ProductServiceServer implements the read-only operations proto/tundra/v1/products.proto
+// describes, backed directly by db the same way server.listProductsHandler/getProductHandler
+// are - there's no write path to share here, so unlike OrderServiceServer this doesn't need an
+// intermediate internal/<pkg>.Service.
+type ProductServiceServer struct {
+	db *gorm.DB
+}
+
+// NewProductServiceServer wires db, the same *gorm.DB instance server.Server queries.
+func NewProductServiceServer(db *gorm.DB) *ProductServiceServer {
+	return &ProductServiceServer{db: db}
+}
+
+// ListProducts returns up to limit products, optionally filtered by category, newest first.
+// It doesn't yet implement search or the page parameter server.listProductsHandler supports.
+func (s *ProductServiceServer) ListProducts(ctx context.Context, category string, limit int) ([]models.Product, error) {
+	query := s.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var products []models.Product
+	if err := query.Find(&products).Error; err != nil {
+		return nil, status.Error(codes.Internal, "failed to list products")
+	}
+	return products, nil
+}
+
+// GetProduct fetches a single product by ID.
+func (s *ProductServiceServer) GetProduct(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	var product models.Product
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&product).Error; err != nil {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return &product, nil
+}
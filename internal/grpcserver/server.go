@@ -0,0 +1,32 @@
+package grpcserver
+
+import (
+	"github.com/GRACENOBLE/tundra/internal/orders"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"gorm.io/gorm"
+)
+
+// NewGRPCServer builds the gRPC counterpart to server.NewServer: every unary call runs through
+// AuthUnaryInterceptor (the gRPC equivalent of auth.AuthMiddleware). It takes db and the same
+// orderService instance REST's POST /orders uses, but does not use either of them.
+//
+// It does not register any services. proto/tundra/v1 has no generated ordersv1/productsv1
+// Go packages in this checkout - `make proto` (protoc plus the Go protobuf/grpc plugins) has
+// never been run here - so there is nothing to hand a *grpc.Server that would actually satisfy
+// ordersv1.RegisterOrderServiceServer/productsv1.RegisterProductServiceServer. OrderServiceServer
+// and ProductServiceServer are plain Go helpers callers can already use directly; they are not
+// generated-interface implementations, and constructing them here without registering them on
+// grpcServer would make this function lie about being connected to anything. Serving the result
+// answers every call except reflection with "unimplemented" - see cmd/api/main.go, which does
+// not start a gRPC listener for exactly this reason.
+func NewGRPCServer(db *gorm.DB, orderService *orders.Service) *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(AuthUnaryInterceptor()))
+
+	// Lets grpcurl/grpcui introspect the service during development; harmless in production
+	// since the schema isn't secret.
+	reflection.Register(grpcServer)
+
+	return grpcServer
+}
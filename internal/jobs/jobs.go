@@ -0,0 +1,35 @@
+// Package jobs implements a small async job queue: HTTP handlers enqueue work (e.g. an
+// image upload too slow to do inline) and return immediately with a job id; one or more
+// Workers pull jobs off the Queue, run them through a registered HandlerFunc, and record the
+// result so GET /jobs/:id can report it back.
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status is a Job's place in its processing lifecycle.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+)
+
+// Job is one unit of queued work: Type selects which registered HandlerFunc a Worker runs it
+// through, Payload is that handler's input, and Result/Error record its outcome once
+// Status leaves StatusPending.
+type Job struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	Status    Status          `json:"status"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Attempts  int             `json:"attempts"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryQueue is an in-process Queue, for tests and for deployments without Redis. It has no
+// cross-instance visibility: enqueuing on one process's MemoryQueue is only ever seen by
+// Workers running in that same process.
+type MemoryQueue struct {
+	mu         sync.Mutex
+	jobs       map[string]*Job
+	pending    []string
+	deadLetter []string
+	notify     chan struct{}
+}
+
+// NewMemoryQueue creates an empty MemoryQueue.
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		jobs:   make(map[string]*Job),
+		notify: make(chan struct{}, 1),
+	}
+}
+
+func (q *MemoryQueue) Enqueue(ctx context.Context, job *Job) error {
+	q.mu.Lock()
+	q.jobs[job.ID] = job
+	q.pending = append(q.pending, job.ID)
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *MemoryQueue) Dequeue(ctx context.Context, timeout time.Duration) (*Job, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		q.mu.Lock()
+		if len(q.pending) > 0 {
+			id := q.pending[0]
+			q.pending = q.pending[1:]
+			job := q.jobs[id]
+			q.mu.Unlock()
+			return job, nil
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.notify:
+			continue
+		case <-deadline.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (q *MemoryQueue) Get(ctx context.Context, id string) (*Job, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	return job, ok, nil
+}
+
+func (q *MemoryQueue) Save(ctx context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job.UpdatedAt = time.Now()
+	q.jobs[job.ID] = job
+	return nil
+}
+
+func (q *MemoryQueue) DeadLetter(ctx context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.deadLetter = append(q.deadLetter, job.ID)
+	return nil
+}
+
+func (q *MemoryQueue) ListDeadLetter(ctx context.Context) ([]*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobList := make([]*Job, 0, len(q.deadLetter))
+	for _, id := range q.deadLetter {
+		jobList = append(jobList, q.jobs[id])
+	}
+	return jobList, nil
+}
+
+func (q *MemoryQueue) List(ctx context.Context, limit int) ([]*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobList := make([]*Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobList = append(jobList, job)
+	}
+	sort.Slice(jobList, func(i, j int) bool { return jobList[i].CreatedAt.After(jobList[j].CreatedAt) })
+	if limit > 0 && len(jobList) > limit {
+		jobList = jobList[:limit]
+	}
+	return jobList, nil
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// Queue is a FIFO job queue with per-job status tracking and a dead-letter list for jobs
+// that exhausted their retries. RedisQueue (LIST + BRPOP) is the production backend, shared
+// across every API instance and every worker; MemoryQueue exists for tests and for
+// deployments without Redis, the same dual-backend pattern as auth.RefreshStore and
+// auth.RevocationStore.
+type Queue interface {
+	// Enqueue saves job (see Save) and pushes its id onto the pending list for Dequeue to
+	// pick up.
+	Enqueue(ctx context.Context, job *Job) error
+	// Dequeue blocks up to timeout for a pending job, returning (nil, nil) on timeout rather
+	// than an error - there being nothing to do isn't a failure.
+	Dequeue(ctx context.Context, timeout time.Duration) (*Job, error)
+	// Get returns the job with the given id, or ok=false if it doesn't exist (e.g. GET
+	// /jobs/:id for an id nobody ever enqueued).
+	Get(ctx context.Context, id string) (*Job, bool, error)
+	// Save persists job's current state, without affecting its position in any queue -
+	// callers move it between StatusPending/StatusProcessing/StatusCompleted/StatusFailed
+	// and then call Save to record that transition.
+	Save(ctx context.Context, job *Job) error
+	// DeadLetter moves job onto the dead-letter list once it has exhausted its retries.
+	DeadLetter(ctx context.Context, job *Job) error
+	// ListDeadLetter returns every job on the dead-letter list, for /admin/jobs.
+	ListDeadLetter(ctx context.Context) ([]*Job, error)
+	// List returns up to limit of the most recently created jobs (regardless of status),
+	// newest first, for /admin/jobs.
+	List(ctx context.Context, limit int) ([]*Job, error)
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// jobTTL bounds how long a completed/failed job's record (and its place in the index used by
+// List) survives, so GET /jobs/:id and /admin/jobs don't grow Redis memory unbounded.
+const jobTTL = 24 * time.Hour
+
+const (
+	pendingListKey    = "jobs:pending"
+	deadLetterListKey = "jobs:deadletter"
+	indexKey          = "jobs:index"
+)
+
+func jobKey(id string) string { return "jobs:job:" + id }
+
+// RedisQueue is a Queue backed by Redis: a LIST (LPUSH/BRPOP) for pending work, a string key
+// per job for its current state, a sorted set (score = CreatedAt) for List, and a second LIST
+// for the dead-letter list.
+type RedisQueue struct {
+	rdb *redis.Client
+}
+
+// NewRedisQueue creates a RedisQueue backed by rdb.
+func NewRedisQueue(rdb *redis.Client) *RedisQueue {
+	return &RedisQueue{rdb: rdb}
+}
+
+func (q *RedisQueue) Enqueue(ctx context.Context, job *Job) error {
+	if err := q.Save(ctx, job); err != nil {
+		return err
+	}
+	return q.rdb.LPush(ctx, pendingListKey, job.ID).Err()
+}
+
+func (q *RedisQueue) Dequeue(ctx context.Context, timeout time.Duration) (*Job, error) {
+	result, err := q.rdb.BRPop(ctx, timeout, pendingListKey).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// result is [listKey, value]; BRPop can report multiple keys but we only ever pass one.
+	job, ok, err := q.Get(ctx, result[1])
+	if err != nil || !ok {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q *RedisQueue) Get(ctx context.Context, id string) (*Job, bool, error) {
+	raw, err := q.rdb.Get(ctx, jobKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var job Job
+	if err := json.Unmarshal(raw, &job); err != nil {
+		return nil, false, err
+	}
+	return &job, true, nil
+}
+
+func (q *RedisQueue) Save(ctx context.Context, job *Job) error {
+	job.UpdatedAt = time.Now()
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := q.rdb.Set(ctx, jobKey(job.ID), raw, jobTTL).Err(); err != nil {
+		return err
+	}
+	if err := q.rdb.ZAdd(ctx, indexKey, redis.Z{Score: float64(job.CreatedAt.UnixNano()), Member: job.ID}).Err(); err != nil {
+		return err
+	}
+	// Drop index entries for jobs created more than jobTTL ago, so the index doesn't outgrow
+	// the job keys it points at.
+	cutoff := strconv.FormatInt(time.Now().Add(-jobTTL).UnixNano(), 10)
+	return q.rdb.ZRemRangeByScore(ctx, indexKey, "-inf", "("+cutoff).Err()
+}
+
+func (q *RedisQueue) DeadLetter(ctx context.Context, job *Job) error {
+	return q.rdb.LPush(ctx, deadLetterListKey, job.ID).Err()
+}
+
+func (q *RedisQueue) ListDeadLetter(ctx context.Context) ([]*Job, error) {
+	ids, err := q.rdb.LRange(ctx, deadLetterListKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return q.loadAll(ctx, ids)
+}
+
+func (q *RedisQueue) List(ctx context.Context, limit int) ([]*Job, error) {
+	ids, err := q.rdb.ZRevRange(ctx, indexKey, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return q.loadAll(ctx, ids)
+}
+
+func (q *RedisQueue) loadAll(ctx context.Context, ids []string) ([]*Job, error) {
+	jobList := make([]*Job, 0, len(ids))
+	for _, id := range ids {
+		job, ok, err := q.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			jobList = append(jobList, job)
+		}
+	}
+	return jobList, nil
+}
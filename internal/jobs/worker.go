@@ -0,0 +1,108 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// DefaultMaxAttempts is how many times Worker retries a failing job, including its first
+// attempt, before moving it to the dead-letter list.
+const DefaultMaxAttempts = 5
+
+// DefaultBaseBackoff is the delay before a job's second attempt; each subsequent retry
+// doubles it (1s, 2s, 4s, 8s, ...).
+const DefaultBaseBackoff = time.Second
+
+// HandlerFunc runs one job's Payload and returns its Result (or an error, triggering a
+// retry or a dead-letter move).
+type HandlerFunc func(ctx context.Context, job *Job) (json.RawMessage, error)
+
+// Worker pulls jobs off a Queue and runs them through handlers registered by job Type,
+// retrying a failing job with exponential backoff up to MaxAttempts before moving it to the
+// dead-letter list. Run N of these as goroutines (see Server.startProductImageWorkers) to
+// get N concurrent workers.
+type Worker struct {
+	Queue       Queue
+	Handlers    map[string]HandlerFunc
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+// NewWorker creates a Worker against queue with the default retry policy.
+func NewWorker(queue Queue) *Worker {
+	return &Worker{
+		Queue:       queue,
+		Handlers:    make(map[string]HandlerFunc),
+		MaxAttempts: DefaultMaxAttempts,
+		BaseBackoff: DefaultBaseBackoff,
+	}
+}
+
+// Register wires handler to run every job whose Type equals jobType.
+func (w *Worker) Register(jobType string, handler HandlerFunc) {
+	w.Handlers[jobType] = handler
+}
+
+// Run dequeues and processes jobs until ctx is canceled. It polls Dequeue with a short
+// blocking timeout rather than once forever, so ctx cancellation is noticed promptly instead
+// of only between jobs.
+func (w *Worker) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		job, err := w.Queue.Dequeue(ctx, 5*time.Second)
+		if err != nil || job == nil {
+			continue
+		}
+		w.process(ctx, job)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *Job) {
+	handler, ok := w.Handlers[job.Type]
+	if !ok {
+		job.Status = StatusFailed
+		job.Error = "no handler registered for job type " + job.Type
+		w.Queue.Save(ctx, job)
+		w.Queue.DeadLetter(ctx, job)
+		return
+	}
+
+	job.Status = StatusProcessing
+	job.Attempts++
+	w.Queue.Save(ctx, job)
+
+	result, err := handler(ctx, job)
+	if err != nil {
+		job.Error = err.Error()
+
+		if job.Attempts >= w.MaxAttempts {
+			job.Status = StatusFailed
+			w.Queue.Save(ctx, job)
+			w.Queue.DeadLetter(ctx, job)
+			return
+		}
+
+		job.Status = StatusPending
+		w.Queue.Save(ctx, job)
+
+		// Exponential backoff: 1x, 2x, 4x, ... BaseBackoff. Re-enqueuing happens off this
+		// goroutine so a slow retry of one job doesn't stall this worker's whole loop.
+		backoff := w.BaseBackoff << uint(job.Attempts-1)
+		go func() {
+			time.Sleep(backoff)
+			w.Queue.Enqueue(context.Background(), job)
+		}()
+		return
+	}
+
+	job.Status = StatusCompleted
+	job.Result = result
+	job.Error = ""
+	w.Queue.Save(ctx, job)
+}
@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestJob(jobType string) *Job {
+	now := time.Now()
+	return &Job{
+		ID:        "job-1",
+		Type:      jobType,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TestWorkerProcessSucceedsOnFirstAttempt(t *testing.T) {
+	queue := NewMemoryQueue()
+	worker := NewWorker(queue)
+	worker.Register("echo", func(ctx context.Context, job *Job) (json.RawMessage, error) {
+		return json.RawMessage(`{"ok":true}`), nil
+	})
+
+	job := newTestJob("echo")
+	queue.Save(context.Background(), job)
+
+	worker.process(context.Background(), job)
+
+	if job.Status != StatusCompleted {
+		t.Fatalf("expected StatusCompleted, got %s", job.Status)
+	}
+	if job.Attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", job.Attempts)
+	}
+	if string(job.Result) != `{"ok":true}` {
+		t.Errorf("expected result to be recorded, got %q", job.Result)
+	}
+}
+
+func TestWorkerProcessRetriesThenSucceeds(t *testing.T) {
+	queue := NewMemoryQueue()
+	worker := NewWorker(queue)
+	worker.BaseBackoff = time.Millisecond
+	worker.MaxAttempts = 3
+
+	attempts := 0
+	worker.Register("flaky", func(ctx context.Context, job *Job) (json.RawMessage, error) {
+		attempts++
+		if attempts < 2 {
+			return nil, errors.New("transient failure")
+		}
+		return json.RawMessage(`{}`), nil
+	})
+
+	job := newTestJob("flaky")
+	queue.Save(context.Background(), job)
+	worker.process(context.Background(), job)
+
+	if job.Status != StatusPending {
+		t.Fatalf("expected job to be re-pending after a failed attempt, got %s", job.Status)
+	}
+
+	retried, err := queue.Dequeue(context.Background(), time.Second)
+	if err != nil {
+		t.Fatalf("Dequeue() error = %v", err)
+	}
+	if retried == nil {
+		t.Fatal("expected the job to be re-enqueued after its backoff elapsed")
+	}
+
+	worker.process(context.Background(), retried)
+	if retried.Status != StatusCompleted {
+		t.Fatalf("expected StatusCompleted after retry, got %s", retried.Status)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 handler invocations, got %d", attempts)
+	}
+}
+
+func TestWorkerProcessDeadLettersAfterMaxAttempts(t *testing.T) {
+	queue := NewMemoryQueue()
+	worker := NewWorker(queue)
+	worker.MaxAttempts = 2
+
+	worker.Register("alwaysFails", func(ctx context.Context, job *Job) (json.RawMessage, error) {
+		return nil, errors.New("permanent failure")
+	})
+
+	job := newTestJob("alwaysFails")
+	job.Attempts = 1 // simulate one prior failed attempt
+	queue.Save(context.Background(), job)
+
+	worker.process(context.Background(), job)
+
+	if job.Status != StatusFailed {
+		t.Fatalf("expected StatusFailed once MaxAttempts is reached, got %s", job.Status)
+	}
+
+	deadLettered, err := queue.ListDeadLetter(context.Background())
+	if err != nil {
+		t.Fatalf("ListDeadLetter() error = %v", err)
+	}
+	if len(deadLettered) != 1 || deadLettered[0].ID != job.ID {
+		t.Fatalf("expected job %s on the dead-letter list, got %+v", job.ID, deadLettered)
+	}
+}
+
+func TestWorkerProcessDeadLettersUnknownJobType(t *testing.T) {
+	queue := NewMemoryQueue()
+	worker := NewWorker(queue)
+
+	job := newTestJob("no-handler-registered")
+	queue.Save(context.Background(), job)
+
+	worker.process(context.Background(), job)
+
+	if job.Status != StatusFailed {
+		t.Fatalf("expected StatusFailed for an unregistered job type, got %s", job.Status)
+	}
+
+	deadLettered, err := queue.ListDeadLetter(context.Background())
+	if err != nil {
+		t.Fatalf("ListDeadLetter() error = %v", err)
+	}
+	if len(deadLettered) != 1 {
+		t.Fatalf("expected 1 dead-lettered job, got %d", len(deadLettered))
+	}
+}
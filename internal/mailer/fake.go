@@ -0,0 +1,40 @@
+package mailer
+
+import "sync"
+
+// SentMessage is one email captured by FakeMailer.
+type SentMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// FakeMailer is a Mailer for tests: it records every message instead of sending it so a test
+// can assert on the subject/body (e.g. to extract a verification or reset token) without a
+// real SMTP relay.
+type FakeMailer struct {
+	mu       sync.Mutex
+	Messages []SentMessage
+}
+
+// NewFakeMailer creates an empty FakeMailer.
+func NewFakeMailer() *FakeMailer {
+	return &FakeMailer{}
+}
+
+func (m *FakeMailer) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Messages = append(m.Messages, SentMessage{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// Last returns the most recently sent message, or zero value if none were sent.
+func (m *FakeMailer) Last() SentMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.Messages) == 0 {
+		return SentMessage{}
+	}
+	return m.Messages[len(m.Messages)-1]
+}
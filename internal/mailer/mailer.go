@@ -0,0 +1,21 @@
+// Package mailer sends the transactional emails the auth flows depend on (account
+// verification, password reset) behind a small interface so the server can run without a
+// configured SMTP relay in development and tests.
+package mailer
+
+// Mailer sends a single plain-text email. Implementations should treat to/subject/body as
+// already-rendered content; callers are responsible for building the message text.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewFromEnv returns an SMTPMailer configured from SMTP_HOST/SMTP_PORT/SMTP_USERNAME/
+// SMTP_PASSWORD/SMTP_FROM, or a NoOpMailer if SMTP_HOST is unset so the server still starts
+// (and signup/reset flows still work end-to-end in development) without a relay configured.
+func NewFromEnv() Mailer {
+	cfg, ok := smtpConfigFromEnv()
+	if !ok {
+		return NewNoOpMailer()
+	}
+	return NewSMTPMailer(cfg)
+}
@@ -0,0 +1,15 @@
+package mailer
+
+// NoOpMailer discards every message. It's the default when SMTP_HOST isn't configured, so a
+// server without a mail relay still starts and its signup/reset flows still run end-to-end -
+// the token just never reaches an inbox.
+type NoOpMailer struct{}
+
+// NewNoOpMailer creates a NoOpMailer.
+func NewNoOpMailer() *NoOpMailer {
+	return &NoOpMailer{}
+}
+
+func (m *NoOpMailer) Send(to, subject, body string) error {
+	return nil
+}
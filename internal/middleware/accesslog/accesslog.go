@@ -0,0 +1,187 @@
+// Package accesslog is a gin middleware that logs one record per request, in a format
+// inspired by Apache's mod_log_config combined log, or as structured slog attributes for
+// ingestion by a log aggregator. See New.
+package accesslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header a request's ID is read from if present, and is always
+// echoed back on the response, so a client or upstream proxy can correlate its own logs
+// with this service's.
+const RequestIDHeader = "X-Request-ID"
+
+// Format selects how a request record is rendered.
+type Format int
+
+const (
+	// FormatApache renders the classic mod_log_config combined line -
+	// `%h %l %u %t "%r" %>s %b %D "%{Referer}i" "%{User-Agent}i"`, plus a trailing
+	// id=<request ID> - as the log message, for a human tailing a terminal.
+	FormatApache Format = iota
+	// FormatJSON emits the same fields as structured slog attributes instead of one
+	// preformatted string, so a JSON-handler-backed *slog.Logger produces one JSON
+	// object per request for a log aggregator to ingest.
+	FormatJSON
+)
+
+// Logger is the access-log middleware's configuration. Build one with New; the zero
+// value is never used directly.
+type Logger struct {
+	out        *slog.Logger
+	format     Format
+	skipPaths  map[string]bool
+	sampleRate float64
+}
+
+// Option configures a Logger beyond its defaults (slog.Default(), FormatApache, no
+// skipped paths, sampleRate 1.0 - every request logged).
+type Option func(*Logger)
+
+// WithLogger sets the *slog.Logger records are written to (default slog.Default()).
+func WithLogger(logger *slog.Logger) Option {
+	return func(l *Logger) { l.out = logger }
+}
+
+// WithFormat selects FormatApache or FormatJSON (default FormatApache).
+func WithFormat(format Format) Option {
+	return func(l *Logger) { l.format = format }
+}
+
+// WithSkipPaths exempts exact request paths (e.g. "/healthz") from logging entirely,
+// regardless of sampleRate - for endpoints a load balancer polls every few seconds that
+// would otherwise drown out real traffic in the log.
+func WithSkipPaths(paths ...string) Option {
+	return func(l *Logger) {
+		for _, p := range paths {
+			l.skipPaths[p] = true
+		}
+	}
+}
+
+// WithSampleRate logs only a random rate fraction of requests not already exempted by
+// WithSkipPaths (0.0 drops everything, 1.0 - the default - logs everything).
+func WithSampleRate(rate float64) Option {
+	return func(l *Logger) { l.sampleRate = rate }
+}
+
+// New builds the access-log gin.HandlerFunc. It generates or propagates an
+// X-Request-ID, stashes the request ID in the request's context.Context (via
+// ContextWithRequestID) so handlers and the database service can include it in their own
+// logs and audit trails, measures latency around the handler chain, and emits one record
+// per request - reading status code and bytes written off gin's own ResponseWriter,
+// which already tracks both.
+func New(opts ...Option) gin.HandlerFunc {
+	l := &Logger{
+		out:        slog.Default(),
+		format:     FormatApache,
+		skipPaths:  make(map[string]bool),
+		sampleRate: 1.0,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(ContextWithRequestID(c.Request.Context(), requestID))
+
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		if l.skipPaths[c.Request.URL.Path] {
+			return
+		}
+		if l.sampleRate < 1.0 && rand.Float64() >= l.sampleRate {
+			return
+		}
+
+		l.emit(c, requestID, start, latency)
+	}
+}
+
+func (l *Logger) emit(c *gin.Context, requestID string, start time.Time, latency time.Duration) {
+	status := c.Writer.Status()
+	bytesWritten := c.Writer.Size()
+	if bytesWritten < 0 {
+		bytesWritten = 0
+	}
+
+	switch l.format {
+	case FormatJSON:
+		l.out.Info("http_request",
+			"request_id", requestID,
+			"remote_addr", c.ClientIP(),
+			"user", remoteUser(c),
+			"time", start.Format(time.RFC3339),
+			"method", c.Request.Method,
+			"path", c.Request.URL.RequestURI(),
+			"proto", c.Request.Proto,
+			"status", status,
+			"bytes", bytesWritten,
+			"duration_us", latency.Microseconds(),
+			"referer", c.Request.Referer(),
+			"user_agent", c.Request.UserAgent(),
+		)
+	default:
+		l.out.Info(apacheLine(c, requestID, start, latency, status, bytesWritten))
+	}
+}
+
+// apacheLine renders `%h %l %u %t "%r" %>s %b %D "%{Referer}i" "%{User-Agent}i"`, followed by
+// id=<request ID>.
+func apacheLine(c *gin.Context, requestID string, start time.Time, latency time.Duration, status, bytesWritten int) string {
+	bytes := "-"
+	if bytesWritten > 0 {
+		bytes = fmt.Sprintf("%d", bytesWritten)
+	}
+
+	return fmt.Sprintf(
+		`%s - %s [%s] "%s %s %s" %d %s %d "%s" "%s" id=%s`,
+		c.ClientIP(),
+		remoteUser(c),
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		c.Request.Method, c.Request.URL.RequestURI(), c.Request.Proto,
+		status, bytes, latency.Microseconds(),
+		c.Request.Referer(), c.Request.UserAgent(),
+		requestID,
+	)
+}
+
+// remoteUser is Apache's %u: the authenticated username if AuthMiddleware (or
+// RequireScopes) has already run and populated it, "-" otherwise.
+func remoteUser(c *gin.Context) string {
+	if username := c.GetString("username"); username != "" {
+		return username
+	}
+	return "-"
+}
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// ContextWithRequestID returns a copy of ctx carrying id, retrievable with
+// RequestIDFromContext.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID New stashed in ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok
+}
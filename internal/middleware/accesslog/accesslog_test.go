@@ -0,0 +1,87 @@
+package accesslog
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGeneratesAndEchoesRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(New())
+	r.GET("/test", func(c *gin.Context) {
+		id, ok := RequestIDFromContext(c.Request.Context())
+		assert.True(t, ok, "request ID should be stashed in the request context")
+		assert.NotEmpty(t, id)
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.NotEmpty(t, resp.Header().Get(RequestIDHeader), "response should echo an X-Request-ID")
+}
+
+func TestNewPropagatesIncomingRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(New())
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "client-supplied-id")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, "client-supplied-id", resp.Header().Get(RequestIDHeader))
+}
+
+func TestNewFormatJSONLogsStructuredFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := gin.New()
+	r.Use(New(WithLogger(logger), WithFormat(FormatJSON)))
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Contains(t, buf.String(), `"method":"GET"`)
+	assert.Contains(t, buf.String(), `"status":200`)
+}
+
+func TestNewSkipsConfiguredPaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := gin.New()
+	r.Use(New(WithLogger(logger), WithSkipPaths("/healthz")))
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Empty(t, buf.String(), "skipped paths should never be logged")
+}
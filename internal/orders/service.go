@@ -0,0 +1,203 @@
+// Package orders holds the create-order transaction shared by every API surface that can
+// place an order - today server.createOrderHandler, and (see internal/grpcserver) the gRPC
+// OrderService - so the row-locking, stock-decrement, and reservation logic exists exactly
+// once instead of two copies that could drift out of sync.
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+	"github.com/GRACENOBLE/tundra/internal/outbox"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrEmptyOrder is returned by Create when items is empty.
+var ErrEmptyOrder = errors.New("order must contain at least one item")
+
+// ProductNotFoundError is returned by Create when one of the requested product IDs doesn't
+// exist. ProductID is kept as the raw string the caller supplied, matching the identifier the
+// failed lookup used.
+type ProductNotFoundError struct {
+	ProductID string
+}
+
+func (e *ProductNotFoundError) Error() string {
+	return fmt.Sprintf("Product with ID %s not found", e.ProductID)
+}
+
+// InsufficientStockError is returned by Create when a product can't cover the requested
+// quantity.
+type InsufficientStockError struct {
+	ProductName string
+	Available   int64
+	Requested   int
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("Insufficient stock for product: %s (available: %d, requested: %d)", e.ProductName, e.Available, e.Requested)
+}
+
+// ReservationTTL is how long Create's stock reservation holds before
+// server.startStockReservationCleanup reclaims it from an order still pending/awaiting_payment.
+const ReservationTTL = 15 * time.Minute
+
+// lowStockThreshold is the remaining-stock level at or below which Create writes an
+// outbox.EventStockLow event for a product, for a downstream system to restock or alert on.
+const lowStockThreshold = 5
+
+// stockLowPayload is the outbox.EventStockLow event payload.
+type stockLowPayload struct {
+	ProductID      uuid.UUID `json:"product_id"`
+	RemainingStock int64     `json:"remaining_stock"`
+}
+
+// orderCreatedPayload is the outbox.EventOrderCreated event payload.
+type orderCreatedPayload struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	TotalPrice float64   `json:"total_price"`
+}
+
+// Item is one line of a CreateOrder request: a product ID (as supplied by the caller, not yet
+// validated) and the quantity wanted.
+type Item struct {
+	ProductID string
+	Quantity  int
+}
+
+// Service runs the order-creation transaction against a *gorm.DB. It holds no other state, so
+// callers construct one per *gorm.DB (server.Server does this once, in NewServer).
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService wraps db for order creation.
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Create validates stock for every item, deducts it, reserves it with ReservationTTL, and
+// persists the order - all in one transaction, row-locking each product exactly like
+// server.createOrderHandler did before this logic was extracted here. Returns
+// *ProductNotFoundError or *InsufficientStockError for a caller-correctable failure, or
+// ErrEmptyOrder if items is empty.
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, items []Item) (*models.Order, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyOrder
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	var totalPrice float64
+	var orderProducts []models.OrderProduct
+	reservationExpiresAt := time.Now().Add(ReservationTTL)
+	var stockReservations []models.StockReservation
+	var lowStockProducts []stockLowPayload
+
+	for _, item := range items {
+		var product models.Product
+
+		// Lock the product row for update to prevent a concurrent order from overselling it.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", item.ProductID).First(&product).Error; err != nil {
+			tx.Rollback()
+			return nil, &ProductNotFoundError{ProductID: item.ProductID}
+		}
+
+		if product.Stock < int64(item.Quantity) {
+			tx.Rollback()
+			return nil, &InsufficientStockError{ProductName: product.Name, Available: product.Stock, Requested: item.Quantity}
+		}
+
+		totalPrice += product.Price * float64(item.Quantity)
+
+		product.Stock -= int64(item.Quantity)
+		if err := tx.Save(&product).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if product.Stock <= lowStockThreshold {
+			lowStockProducts = append(lowStockProducts, stockLowPayload{ProductID: product.ID, RemainingStock: product.Stock})
+		}
+
+		orderProducts = append(orderProducts, models.OrderProduct{
+			ProductID: product.ID,
+			Quantity:  item.Quantity,
+			Price:     product.Price, // Price at time of order
+		})
+
+		// Track the stock just deducted as a reservation, so startStockReservationCleanup
+		// can restore it if this order is abandoned before it's paid.
+		stockReservations = append(stockReservations, models.StockReservation{
+			ProductID: product.ID,
+			Quantity:  item.Quantity,
+			ExpiresAt: reservationExpiresAt,
+		})
+	}
+
+	order := models.Order{
+		UserID:      userID,
+		Description: fmt.Sprintf("Order with %d item(s)", len(items)),
+		TotalPrice:  totalPrice,
+		Status:      models.OrderStatusPending,
+	}
+	if err := tx.Create(&order).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for i := range orderProducts {
+		orderProducts[i].OrderID = order.ID
+	}
+	if err := tx.Create(&orderProducts).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for i := range stockReservations {
+		stockReservations[i].OrderID = order.ID
+	}
+	if err := tx.Create(&stockReservations).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Written inside this same transaction so the event can never be lost to a crash between
+	// this commit and a Relay publishing it - see internal/outbox's package doc.
+	if err := outbox.Write(tx, order.ID.String(), outbox.EventOrderCreated, orderCreatedPayload{
+		OrderID:    order.ID,
+		UserID:     order.UserID,
+		TotalPrice: order.TotalPrice,
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, lowStock := range lowStockProducts {
+		if err := outbox.Write(tx, lowStock.ProductID.String(), outbox.EventStockLow, lowStock); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	order.OrderProducts = orderProducts
+	return &order, nil
+}
@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// NewPublisherFromEnv configures an EventPublisher from NATS_URL or KAFKA_BROKERS/
+// KAFKA_TOPIC, preferring NATS when both are set, and otherwise falls back to an
+// InProcessPublisher (buffered for 256 events) so Relay still runs end-to-end in development
+// without either broker configured - the same env-driven dual-backend pattern
+// payment.NewStripeProviderFromEnv and mailer.NewFromEnv follow.
+func NewPublisherFromEnv() EventPublisher {
+	if url := os.Getenv("NATS_URL"); url != "" {
+		conn, err := nats.Connect(url)
+		if err != nil {
+			fmt.Printf("Warning: failed to connect to NATS at %s: %v. Falling back to an in-process publisher.\n", url, err)
+			return NewInProcessPublisher(256)
+		}
+		return NewNATSPublisher(conn)
+	}
+
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		topic := os.Getenv("KAFKA_TOPIC")
+		if topic == "" {
+			topic = "tundra.events"
+		}
+		writer := &kafka.Writer{
+			Addr:     kafka.TCP(strings.Split(brokers, ",")...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		}
+		return NewKafkaPublisher(writer)
+	}
+
+	return NewInProcessPublisher(256)
+}
@@ -0,0 +1,30 @@
+package outbox
+
+import "context"
+
+// InProcessPublisher delivers events over a buffered Go channel instead of a real broker -
+// the same role FakeProvider plays for payment.Provider - so a test can assert on exactly
+// which events a code path published without standing up NATS or Kafka.
+type InProcessPublisher struct {
+	events chan Event
+}
+
+// NewInProcessPublisher creates an InProcessPublisher whose channel holds up to buffer
+// unconsumed events before Publish blocks.
+func NewInProcessPublisher(buffer int) *InProcessPublisher {
+	return &InProcessPublisher{events: make(chan Event, buffer)}
+}
+
+func (p *InProcessPublisher) Publish(ctx context.Context, event Event) error {
+	select {
+	case p.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Events returns the channel a test can range over or select on to observe published events.
+func (p *InProcessPublisher) Events() <-chan Event {
+	return p.events
+}
@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestInProcessPublisherPublishDeliversToEvents(t *testing.T) {
+	publisher := NewInProcessPublisher(1)
+
+	event := Event{AggregateID: "order-1", Type: EventOrderCreated, Payload: json.RawMessage(`{"order_id":"order-1"}`)}
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	select {
+	case got := <-publisher.Events():
+		if got.AggregateID != event.AggregateID || got.Type != event.Type {
+			t.Fatalf("got %+v, want %+v", got, event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for published event")
+	}
+}
+
+func TestInProcessPublisherPublishRespectsContextCancellation(t *testing.T) {
+	publisher := NewInProcessPublisher(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := publisher.Publish(ctx, Event{AggregateID: "order-1", Type: EventOrderCreated}); err == nil {
+		t.Fatal("expected Publish to return an error for a cancelled context with no consumer, got nil")
+	}
+}
@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher publishes each Event to writer's topic, keyed by AggregateID so every event
+// for the same order lands on the same partition and a downstream consumer sees them in order.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a KafkaPublisher over writer; the caller owns writer's lifecycle
+// (including Close).
+func NewKafkaPublisher(writer *kafka.Writer) *KafkaPublisher {
+	return &KafkaPublisher{writer: writer}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.AggregateID),
+		Value: event.Payload,
+	})
+}
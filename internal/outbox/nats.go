@@ -0,0 +1,24 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes each Event as a NATS message on a subject equal to its Type (e.g.
+// "order.created"), so a downstream service subscribes to exactly the events it cares about
+// instead of a single firehose subject.
+type NATSPublisher struct {
+	conn *nats.Conn
+}
+
+// NewNATSPublisher creates a NATSPublisher over an already-connected conn; the caller owns
+// conn's lifecycle (including Close).
+func NewNATSPublisher(conn *nats.Conn) *NATSPublisher {
+	return &NATSPublisher{conn: conn}
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	return p.conn.Publish(string(event.Type), event.Payload)
+}
@@ -0,0 +1,58 @@
+// Package outbox implements the transactional outbox pattern for domain events: Write inserts
+// an OutboxEvent row in the same transaction as the business change it describes, so the
+// event can never be lost to a crash between that transaction committing and the event being
+// published - the classic "DB committed but message lost" dual-write problem. A Relay then
+// polls for unpublished rows and hands them to an EventPublisher (NATS, Kafka, or an
+// in-process channel for tests), the same emit-now/deliver-later split internal/audit draws
+// around its own Emitter.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+
+	"gorm.io/gorm"
+)
+
+// EventType identifies the kind of domain event an OutboxEvent row carries.
+type EventType string
+
+const (
+	EventOrderCreated   EventType = "order.created"
+	EventOrderPaid      EventType = "order.paid"
+	EventOrderCancelled EventType = "order.cancelled"
+	EventStockLow       EventType = "stock.low"
+)
+
+// Event is what a Relay hands an EventPublisher once it claims an OutboxEvent row.
+type Event struct {
+	AggregateID string
+	Type        EventType
+	Payload     json.RawMessage
+}
+
+// EventPublisher dispatches a claimed outbox Event to a downstream system (email, analytics,
+// fulfillment, ...). Implementations: NATSPublisher, KafkaPublisher, InProcessPublisher.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Write inserts an OutboxEvent row for aggregateID/eventType/payload using tx. Call this
+// against the same *gorm.DB transaction as the business change it describes and before that
+// transaction commits - e.g. orders.Service.Create writing EventOrderCreated just before its
+// own tx.Commit() - never against a standalone connection, or the event could be written
+// (or lost) independently of the change it's meant to describe.
+func Write(tx *gorm.DB, aggregateID string, eventType EventType, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.OutboxEvent{
+		AggregateID: aggregateID,
+		Type:        string(eventType),
+		Payload:     string(data),
+	}).Error
+}
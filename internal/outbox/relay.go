@@ -0,0 +1,120 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultRelayBatchSize bounds how many rows Relay claims per SELECT ... FOR UPDATE SKIP
+// LOCKED query, so one pass can't hold a transaction open over an unbounded backlog.
+const defaultRelayBatchSize = 100
+
+// Relay polls outbox_events for unpublished rows and dispatches them to an EventPublisher,
+// claiming rows with SELECT ... FOR UPDATE SKIP LOCKED so any number of Relay instances (one
+// per API replica) can run against the same table concurrently without duplicating a
+// publish or blocking on rows another instance already has locked.
+type Relay struct {
+	db        *gorm.DB
+	publisher EventPublisher
+	batchSize int
+}
+
+// NewRelay creates a Relay reading unpublished rows from db and dispatching them to publisher.
+func NewRelay(db *gorm.DB, publisher EventPublisher) *Relay {
+	return &Relay{db: db, publisher: publisher, batchSize: defaultRelayBatchSize}
+}
+
+// Start polls for unpublished events every interval until the returned stop function is
+// called, the same ticker/stop-channel shape as server.startStockReservationCleanup.
+func (r *Relay) Start(interval time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.drain(context.Background())
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// drain claims and publishes batches until one comes back short, i.e. the backlog is caught
+// up, rather than publishing only a single batch per tick.
+func (r *Relay) drain(ctx context.Context) {
+	for {
+		n, err := r.relayBatch(ctx)
+		if err != nil {
+			fmt.Printf("Warning: outbox relay batch failed: %v\n", err)
+			return
+		}
+		if n < r.batchSize {
+			return
+		}
+	}
+}
+
+// relayBatch claims up to batchSize unpublished rows, publishes each one, marks the ones
+// that published successfully, and returns how many it published. A row whose Publish call fails is left unpublished (not rolled
+// back) so the next pass retries it; the rows a Relay instance claims here are invisible to
+// every other instance's SKIP LOCKED query until this transaction commits or rolls back.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	defer func() {
+		if rec := recover(); rec != nil {
+			tx.Rollback()
+		}
+	}()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	var rows []models.OutboxEvent
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL").
+		Order("created_at ASC").
+		Limit(r.batchSize).
+		Find(&rows).Error
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	published := 0
+	for i := range rows {
+		event := Event{
+			AggregateID: rows[i].AggregateID,
+			Type:        EventType(rows[i].Type),
+			Payload:     []byte(rows[i].Payload),
+		}
+
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			fmt.Printf("Warning: failed to publish outbox event %s (%s): %v\n", rows[i].ID, rows[i].Type, err)
+			continue
+		}
+
+		now := time.Now()
+		rows[i].PublishedAt = &now
+		if err := tx.Save(&rows[i]).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		published++
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return published, nil
+}
@@ -0,0 +1,48 @@
+// Package payment abstracts the checkout step of an order's lifecycle - creating a payment
+// intent for its total, then later verifying the provider's webhook reporting whether it was
+// paid - behind a small interface, the same split cloudinary.Client and mailer.Mailer draw
+// around their own third parties so the order flow can run against a StripeProvider in
+// production and a FakeProvider in tests.
+package payment
+
+import "context"
+
+// PaymentIntent is the subset of a provider's payment intent checkoutOrderHandler hands back
+// to the client: an id to correlate with the eventual webhook, and the client secret the
+// frontend's payment SDK completes the charge with.
+type PaymentIntent struct {
+	ID           string
+	ClientSecret string
+}
+
+// EventStatus is what a verified webhook Event reports happened to a payment intent.
+type EventStatus string
+
+const (
+	EventStatusSucceeded EventStatus = "succeeded"
+	EventStatusFailed    EventStatus = "failed"
+	EventStatusRefunded  EventStatus = "refunded"
+	EventStatusCancelled EventStatus = "cancelled"
+)
+
+// Event is a provider webhook event, already signature-verified and normalized down to the
+// order it concerns and what happened to it.
+type Event struct {
+	// OrderID is recovered from the payment intent's metadata, where CreatePaymentIntent
+	// stored it.
+	OrderID string
+	Status  EventStatus
+}
+
+// Provider is implemented by every payment backend checkoutOrderHandler and orderWebhookHandler
+// can run against.
+type Provider interface {
+	// CreatePaymentIntent starts a payment for amount (in the currency's smallest unit,
+	// e.g. cents) against orderID, returning the intent a client completes with its own
+	// payment SDK.
+	CreatePaymentIntent(ctx context.Context, orderID string, amount int64, currency string) (*PaymentIntent, error)
+
+	// VerifyWebhook authenticates payload against its provider signature header and
+	// returns the Event it describes, or an error if the signature doesn't check out.
+	VerifyWebhook(payload []byte, signatureHeader string) (*Event, error)
+}
@@ -0,0 +1,78 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/paymentintent"
+	"github.com/stripe/stripe-go/v81/webhook"
+)
+
+// StripeProvider is the production Provider, backed by Stripe's PaymentIntents API.
+type StripeProvider struct {
+	webhookSecret string
+}
+
+// NewStripeProvider configures the stripe-go client's package-level API key and returns a
+// Provider that verifies webhooks against webhookSecret.
+func NewStripeProvider(apiKey, webhookSecret string) *StripeProvider {
+	stripe.Key = apiKey
+	return &StripeProvider{webhookSecret: webhookSecret}
+}
+
+// NewStripeProviderFromEnv configures a StripeProvider from STRIPE_SECRET_KEY/
+// STRIPE_WEBHOOK_SECRET, or returns ok=false if STRIPE_SECRET_KEY isn't set.
+func NewStripeProviderFromEnv() (*StripeProvider, bool) {
+	apiKey := os.Getenv("STRIPE_SECRET_KEY")
+	if apiKey == "" {
+		return nil, false
+	}
+	return NewStripeProvider(apiKey, os.Getenv("STRIPE_WEBHOOK_SECRET")), true
+}
+
+func (p *StripeProvider) CreatePaymentIntent(ctx context.Context, orderID string, amount int64, currency string) (*PaymentIntent, error) {
+	params := &stripe.PaymentIntentParams{
+		Amount:   stripe.Int64(amount),
+		Currency: stripe.String(currency),
+		Metadata: map[string]string{"order_id": orderID},
+	}
+	params.Context = ctx
+
+	intent, err := paymentintent.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("stripe: failed to create payment intent: %w", err)
+	}
+
+	return &PaymentIntent{ID: intent.ID, ClientSecret: intent.ClientSecret}, nil
+}
+
+func (p *StripeProvider) VerifyWebhook(payload []byte, signatureHeader string) (*Event, error) {
+	stripeEvent, err := webhook.ConstructEvent(payload, signatureHeader, p.webhookSecret)
+	if err != nil {
+		return nil, fmt.Errorf("stripe: webhook signature verification failed: %w", err)
+	}
+
+	var intent stripe.PaymentIntent
+	if err := json.Unmarshal(stripeEvent.Data.Raw, &intent); err != nil {
+		return nil, fmt.Errorf("stripe: failed to parse payment intent: %w", err)
+	}
+
+	var status EventStatus
+	switch stripeEvent.Type {
+	case "payment_intent.succeeded":
+		status = EventStatusSucceeded
+	case "payment_intent.payment_failed":
+		status = EventStatusFailed
+	case "payment_intent.canceled":
+		status = EventStatusCancelled
+	case "charge.refunded":
+		status = EventStatusRefunded
+	default:
+		return nil, fmt.Errorf("stripe: unhandled event type %q", stripeEvent.Type)
+	}
+
+	return &Event{OrderID: intent.Metadata["order_id"], Status: status}, nil
+}
@@ -1,12 +1,15 @@
 package ratelimit
 
 import (
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ulule/limiter/v3"
-	mgin "github.com/ulule/limiter/v3/drivers/middleware/gin"
 	"github.com/ulule/limiter/v3/drivers/store/memory"
+
+	"github.com/GRACENOBLE/tundra/internal/audit"
 )
 
 // RateLimitConfig holds configuration for different rate limiters
@@ -17,22 +20,124 @@ type RateLimitConfig struct {
 	Auth string
 	// API rate limit for general API endpoints (e.g., "60-M" = 60 requests per minute)
 	API string
+
+	// DefaultAction is the enforcement mode every limiter built from this config applies
+	// once its limit is reached, unless RouteActions overrides it for the matched route.
+	DefaultAction Action
+	// RouteActions overrides DefaultAction per route, keyed by the route's registered
+	// pattern (gin's c.FullPath(), e.g. "/products" or "/auth/login"), so operators can roll
+	// a new limit out in DryRun or Warn mode against production traffic before flipping it
+	// to Deny.
+	RouteActions map[string]Action
 }
 
 // DefaultConfig returns sensible default rate limit configuration
 func DefaultConfig() RateLimitConfig {
 	return RateLimitConfig{
-		Global: "1000-H", // 1000 requests per hour per IP
-		Auth:   "5-M",    // 5 login/register attempts per minute per IP
-		API:    "100-M",  // 100 API requests per minute per IP
+		Global:        "1000-H", // 1000 requests per hour per IP
+		Auth:          "5-M",    // 5 login/register attempts per minute per IP
+		API:           "100-M",  // 100 API requests per minute per IP
+		DefaultAction: Deny,
 	}
 }
 
-// NewRateLimiter creates a new rate limiter middleware with the specified rate
+// Action controls what a rate limiter does once its limit is reached.
+type Action string
+
+const (
+	// Deny rejects the request with 429 once its limit is reached - the original,
+	// enforcing behavior.
+	Deny Action = "deny"
+	// Warn allows the request through but attaches an X-RateLimit-Warning header and emits
+	// an audit.Event, so operators can see what a limit would have blocked before enforcing
+	// it.
+	Warn Action = "warn"
+	// DryRun only increments the limiter's counter and sets the X-RateLimit-* headers every
+	// mode sets - it never blocks or adds a warning, for silently observing a new limit's hit
+	// rate against real traffic.
+	DryRun Action = "dryrun"
+)
+
+// config is the process-wide RateLimitConfig GlobalLimiter, AuthLimiter, and APILimiter
+// build their rates and enforcement Actions from, the same package-level-var-plus-setter
+// pattern as SetRedisClient.
+var config = DefaultConfig()
+
+// SetConfig replaces the process-wide RateLimitConfig.
+func SetConfig(cfg RateLimitConfig) {
+	config = cfg
+}
+
+// Option configures a rate limiter's key derivation, enforcement Action, and, for a
+// Redis-backed limiter, the key prefix its counters are tracked under. Shared by
+// NewRateLimiter and NewRateLimiterWithStore so GlobalLimiter/AuthLimiter/APILimiter can pass
+// the same options regardless of which backend limiterFor ends up choosing.
+type Option func(*limiterConfig)
+
+type limiterConfig struct {
+	keyFunc      KeyExtractor
+	prefix       string
+	action       Action
+	routeActions map[string]Action
+}
+
+func defaultLimiterConfig() limiterConfig {
+	return limiterConfig{keyFunc: IPKeyExtractor, prefix: "ratelimit", action: Deny}
+}
+
+// WithKeyFunc overrides the default (client-IP-based) key derivation - pass UserKeyExtractor
+// so AuthLimiter can key on the authenticated account instead of source IP, defeating a
+// credential-stuffing attack spread across many IPs but reusing one account.
+func WithKeyFunc(keyFunc KeyExtractor) Option {
+	return func(cfg *limiterConfig) { cfg.keyFunc = keyFunc }
+}
+
+// WithPrefix overrides the key prefix a limiter's counters are tracked under, so multiple
+// Redis-backed limiters sharing one Redis instance don't collide.
+func WithPrefix(prefix string) Option {
+	return func(cfg *limiterConfig) { cfg.prefix = prefix }
+}
+
+// WithAction overrides the default enforcement Action (Deny) applied once the limit is
+// reached.
+func WithAction(action Action) Option {
+	return func(cfg *limiterConfig) { cfg.action = action }
+}
+
+// WithRouteActions overrides the Action per route, keyed by c.FullPath(). A route with no
+// entry falls back to the Action set via WithAction (Deny by default).
+func WithRouteActions(actions map[string]Action) Option {
+	return func(cfg *limiterConfig) { cfg.routeActions = actions }
+}
+
+// actionFor resolves which Action applies to the request at hand, preferring a
+// route-specific override over the limiter's default.
+func (cfg limiterConfig) actionFor(c *gin.Context) Action {
+	if action, ok := cfg.routeActions[c.FullPath()]; ok {
+		return action
+	}
+	if cfg.action == "" {
+		return Deny
+	}
+	return cfg.action
+}
+
+// NewRateLimiter creates a new in-memory rate limiter middleware with the specified rate.
 // Rate format: "limit-period" where period can be S (second), M (minute), H (hour)
 // Examples: "10-S" (10/second), "100-M" (100/minute), "1000-H" (1000/hour)
-func NewRateLimiter(rate string) gin.HandlerFunc {
-	// Parse rate string
+func NewRateLimiter(rate string, opts ...Option) gin.HandlerFunc {
+	cfg := defaultLimiterConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return newLimiterMiddleware(memory.NewStore(), rate, cfg)
+}
+
+// newLimiterMiddleware is the enforcement core shared by NewRateLimiter and
+// NewRateLimiterWithStore: both just hand it a different limiter.Store. Every mode reads and
+// increments the counter and sets the standard X-RateLimit-* headers; only cfg.action decides
+// whether a reached limit actually blocks the request.
+func newLimiterMiddleware(store limiter.Store, rate string, cfg limiterConfig) gin.HandlerFunc {
 	rateLimit, err := limiter.NewRateFromFormatted(rate)
 	if err != nil {
 		// Fallback to a safe default if parsing fails
@@ -42,28 +147,86 @@ func NewRateLimiter(rate string) gin.HandlerFunc {
 		}
 	}
 
-	// Create in-memory store for rate limiting
-	store := memory.NewStore()
-
-	// Create limiter instance
 	instance := limiter.New(store, rateLimit)
 
-	// Return Gin middleware
-	return mgin.NewMiddleware(instance)
+	return func(c *gin.Context) {
+		limiterCtx, err := instance.Get(c.Request.Context(), cfg.keyFunc(c))
+		if err != nil {
+			// Fail open - a store hiccup (e.g. Redis down) shouldn't take the whole API
+			// down with it.
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(limiterCtx.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(limiterCtx.Remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(limiterCtx.Reset, 10))
+
+		if !limiterCtx.Reached {
+			c.Next()
+			return
+		}
+
+		switch cfg.actionFor(c) {
+		case DryRun:
+			// The counter above is already incremented; the response is otherwise left
+			// untouched so operators can watch a new limit's hit rate risk-free.
+			c.Next()
+		case Warn:
+			c.Header("X-RateLimit-Warning", "rate limit exceeded; currently in warn mode")
+			emitRateLimitWarning(c, rate)
+			c.Next()
+		default: // Deny
+			retryAfter := limiterCtx.Reset - time.Now().Unix()
+			if retryAfter < 0 {
+				retryAfter = 0
+			}
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+			markBlocked(c)
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			c.Abort()
+		}
+	}
 }
 
-// GlobalLimiter creates a rate limiter for global API access
-func GlobalLimiter() gin.HandlerFunc {
-	return NewRateLimiter(DefaultConfig().Global)
+// emitRateLimitWarning records that a limit would have blocked this request, had it been
+// set to Deny instead of Warn.
+func emitRateLimitWarning(c *gin.Context, rate string) {
+	_ = audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventRateLimitWarned,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"route": c.FullPath(), "rate": rate},
+	})
 }
 
-// AuthLimiter creates a rate limiter for authentication endpoints
-// More restrictive to prevent brute force attacks
-func AuthLimiter() gin.HandlerFunc {
-	return NewRateLimiter(DefaultConfig().Auth)
+// GlobalLimiter creates a rate limiter for global API access. Enforced through Redis (see
+// SetRedisClient) when one is configured, so the limit holds across every replica instead
+// of resetting per pod; otherwise falls back to the in-memory limiter.
+func GlobalLimiter(opts ...Option) gin.HandlerFunc {
+	return limiterFor(config.Global, opts...)
+}
+
+// AuthLimiter creates a rate limiter for authentication endpoints, more restrictive to
+// prevent brute force attacks. Pass WithKeyFunc(UserKeyExtractor) to key on the authenticated
+// account rather than client IP, so brute-forcing spread across many source IPs still hits
+// one shared limit.
+func AuthLimiter(opts ...Option) gin.HandlerFunc {
+	return limiterFor(config.Auth, opts...)
 }
 
 // APILimiter creates a rate limiter for general API endpoints
-func APILimiter() gin.HandlerFunc {
-	return NewRateLimiter(DefaultConfig().API)
+func APILimiter(opts ...Option) gin.HandlerFunc {
+	return limiterFor(config.API, opts...)
+}
+
+// limiterFor picks the Redis-backed limiter when SetRedisClient has configured one, falling
+// back to NewRateLimiter's in-memory implementation otherwise. It applies config's
+// DefaultAction/RouteActions first so opts can still override them per call site.
+func limiterFor(rate string, opts ...Option) gin.HandlerFunc {
+	allOpts := append([]Option{WithAction(config.DefaultAction), WithRouteActions(config.RouteActions)}, opts...)
+	if redisClient != nil {
+		return NewRateLimiterWithStore(redisClient, rate, allOpts...)
+	}
+	return NewRateLimiter(rate, allOpts...)
 }
@@ -231,3 +231,113 @@ func TestRateLimitReset(t *testing.T) {
 	r.ServeHTTP(resp2, req)
 	assert.Equal(t, http.StatusOK, resp2.Code, "Rate limit should reset after period")
 }
+
+func TestRateLimiterActions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("DryRun never blocks and carries no warning header", func(t *testing.T) {
+		r := gin.New()
+		r.Use(NewRateLimiter("2-S", WithAction(DryRun)))
+		r.GET("/test", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "success"})
+		})
+
+		for i := 0; i < 5; i++ {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.RemoteAddr = "127.0.0.1:1234"
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code, "DryRun should never block, even past the limit")
+			assert.Empty(t, resp.Header().Get("X-RateLimit-Warning"))
+		}
+	})
+
+	t.Run("Warn allows the request through with a warning header", func(t *testing.T) {
+		r := gin.New()
+		r.Use(NewRateLimiter("1-S", WithAction(Warn)))
+		r.GET("/test", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "success"})
+		})
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "127.0.0.1:1234"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code, "Warn should allow the request through")
+		assert.NotEmpty(t, resp.Header().Get("X-RateLimit-Warning"))
+	})
+
+	t.Run("RouteActions overrides the default Action per route", func(t *testing.T) {
+		r := gin.New()
+		r.Use(NewRateLimiter("1-S", WithAction(Deny), WithRouteActions(map[string]Action{
+			"/dryrun": DryRun,
+		})))
+		r.GET("/dryrun", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+		r.GET("/enforced", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest("GET", "/dryrun", nil)
+			req.RemoteAddr = "127.0.0.1:1234"
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code, "/dryrun is overridden to DryRun and should never block")
+		}
+
+		req := httptest.NewRequest("GET", "/enforced", nil)
+		req.RemoteAddr = "127.0.0.1:1234"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code, "/enforced keeps the default Deny action")
+	})
+}
+
+func TestKeyExtractors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("IPKeyExtractor uses the client IP", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = "203.0.113.5:1234"
+
+		assert.Equal(t, "203.0.113.5", IPKeyExtractor(c))
+	})
+
+	t.Run("UserKeyExtractor prefers the authenticated user ID", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = "203.0.113.5:1234"
+		c.Set("userID", "user-42")
+
+		assert.Equal(t, "user:user-42", UserKeyExtractor(c))
+	})
+
+	t.Run("UserKeyExtractor falls back to the client IP when unauthenticated", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = "203.0.113.5:1234"
+
+		assert.Equal(t, "203.0.113.5", UserKeyExtractor(c))
+	})
+
+	t.Run("APIKeyExtractor prefers the X-API-Key header", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = "203.0.113.5:1234"
+		c.Request.Header.Set("X-API-Key", "secret-key")
+
+		assert.Equal(t, "apikey:secret-key", APIKeyExtractor(c))
+	})
+
+	t.Run("APIKeyExtractor falls back to the client IP when absent", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = "203.0.113.5:1234"
+
+		assert.Equal(t, "203.0.113.5", APIKeyExtractor(c))
+	})
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary List audit events
+// @Description Paginate recorded audit events (Admin only), optionally filtered by user, type, and time range.
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param user_id query string false "Filter by user ID (UUID)"
+// @Param type query string false "Filter by event type (e.g. login_succeeded)"
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Items per page" default(20)
+// @Success 200 {object} object{currentPage=int,pageSize=int,totalEvents=int,events=[]models.AuditEvent} "Paginated audit events"
+// @Failure 400 {object} object{error=string} "Invalid filter value"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
+// @Router /admin/audit [get]
+func (s *Server) listAuditEventsHandler(c *gin.Context) {
+	page := 1
+	pageSize := 20
+
+	if pageParam := c.Query("page"); pageParam != "" {
+		if parsed, err := parsePositiveInt(pageParam); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if pageSizeParam := c.Query("pageSize"); pageSizeParam != "" {
+		if parsed, err := parsePositiveInt(pageSizeParam); err == nil && parsed > 0 {
+			pageSize = parsed
+		}
+	}
+
+	query := s.db.Model(&models.AuditEvent{})
+
+	if userIDParam := c.Query("user_id"); userIDParam != "" {
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if eventType := c.Query("type"); eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+
+	if fromParam := c.Query("from"); fromParam != "" {
+		from, err := time.Parse(time.RFC3339, fromParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from timestamp, expected RFC3339"})
+			return
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+
+	if toParam := c.Query("to"); toParam != "" {
+		to, err := time.Parse(time.RFC3339, toParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to timestamp, expected RFC3339"})
+			return
+		}
+		query = query.Where("created_at <= ?", to)
+	}
+
+	var totalEvents int64
+	if err := query.Count(&totalEvents).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count audit events"})
+		return
+	}
+
+	var events []models.AuditEvent
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"currentPage": page,
+		"pageSize":    pageSize,
+		"totalEvents": totalEvents,
+		"events":      events,
+	})
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tundra/internal/auth"
+	"tundra/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countAuditEvents returns how many audit_events rows have the given type.
+func countAuditEvents(t *testing.T, server *Server, eventType string) int64 {
+	var count int64
+	require.NoError(t, server.db.Model(&models.AuditEvent{}).Where("type = ?", eventType).Count(&count).Error)
+	return count
+}
+
+func TestSignUpHandler_EmitsSignUpEvent(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.POST("/auth/register", server.signUpHandler)
+
+	reqBody := map[string]string{
+		"username": "audituser",
+		"email":    "audituser@example.com",
+		"password": "Password123!",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusCreated, resp.Code)
+
+	assert.EqualValues(t, 1, countAuditEvents(t, server, "sign_up"))
+}
+
+func TestLoginHandler_EmitsLoginEvents(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.POST("/auth/login", server.loginHandler)
+
+	createTestUser(t, server.db, "audituser", "audituser@example.com", "Password123!")
+
+	t.Run("wrong password emits exactly one login_failed event", func(t *testing.T) {
+		reqBody := map[string]string{
+			"email":    "audituser@example.com",
+			"password": "WrongPassword!",
+		}
+		jsonBody, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		assert.EqualValues(t, 1, countAuditEvents(t, server, "login_failed"))
+		assert.EqualValues(t, 0, countAuditEvents(t, server, "login_succeeded"))
+	})
+
+	t.Run("correct password emits exactly one login_succeeded event", func(t *testing.T) {
+		reqBody := map[string]string{
+			"email":    "audituser@example.com",
+			"password": "Password123!",
+		}
+		jsonBody, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		assert.EqualValues(t, 1, countAuditEvents(t, server, "login_succeeded"))
+		assert.EqualValues(t, 1, countAuditEvents(t, server, "login_failed"))
+	})
+}
+
+func TestLogoutHandler_EmitsTokenRevokedEvent(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	server.refreshTokens = auth.NewRefreshTokenStore(server.db)
+
+	router.POST("/auth/login", server.loginHandler)
+	router.POST("/auth/logout", server.logoutHandler)
+
+	createTestUser(t, server.db, "audituser", "audituser@example.com", "Password123!")
+
+	loginBody, _ := json.Marshal(map[string]string{
+		"email":    "audituser@example.com",
+		"password": "Password123!",
+	})
+	loginReq, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(loginBody))
+	loginReq.Header.Set("Content-Type", "application/json")
+	loginResp := httptest.NewRecorder()
+	router.ServeHTTP(loginResp, loginReq)
+	require.Equal(t, http.StatusOK, loginResp.Code)
+
+	var loginResponse map[string]interface{}
+	require.NoError(t, json.Unmarshal(loginResp.Body.Bytes(), &loginResponse))
+	refreshToken, ok := loginResponse["refreshToken"].(string)
+	require.True(t, ok)
+	require.NotEmpty(t, refreshToken)
+
+	logoutBody, _ := json.Marshal(map[string]string{"refreshToken": refreshToken})
+	logoutReq, _ := http.NewRequest("POST", "/auth/logout", bytes.NewBuffer(logoutBody))
+	logoutReq.Header.Set("Content-Type", "application/json")
+	logoutResp := httptest.NewRecorder()
+	router.ServeHTTP(logoutResp, logoutReq)
+	require.Equal(t, http.StatusOK, logoutResp.Code)
+
+	assert.EqualValues(t, 1, countAuditEvents(t, server, "token_revoked"))
+}
+
+func TestListAuditEventsHandler(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.POST("/auth/register", server.signUpHandler)
+	router.GET("/admin/audit", server.listAuditEventsHandler)
+
+	reqBody := map[string]string{
+		"username": "audituser",
+		"email":    "audituser@example.com",
+		"password": "Password123!",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusCreated, resp.Code)
+
+	listReq, _ := http.NewRequest("GET", "/admin/audit?type=sign_up", nil)
+	listResp := httptest.NewRecorder()
+	router.ServeHTTP(listResp, listReq)
+	require.Equal(t, http.StatusOK, listResp.Code)
+
+	var listResponse map[string]interface{}
+	require.NoError(t, json.Unmarshal(listResp.Body.Bytes(), &listResponse))
+	assert.EqualValues(t, 1, listResponse["totalEvents"])
+
+	events, ok := listResponse["events"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, events, 1)
+}
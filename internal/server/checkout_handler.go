@@ -0,0 +1,197 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+
+	"github.com/GRACENOBLE/tundra/internal/apierr"
+	"github.com/GRACENOBLE/tundra/internal/audit"
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+	"github.com/GRACENOBLE/tundra/internal/outbox"
+	"github.com/GRACENOBLE/tundra/internal/payment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// @Summary Start checkout for an order
+// @Description Create a payment intent for a pending order's total, transitioning it to awaiting_payment. The order is finalized later by POST /orders/webhook, not by this request returning.
+// @Tags Orders
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Success 200 {object} object{client_secret=string} "Payment intent created; pass client_secret to the frontend's payment SDK"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "User not authenticated"
+// @Failure 403 {object} object{error=object{code=string,message=string,request_id=string}} "Order belongs to a different user"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Order not found"
+// @Failure 409 {object} object{error=object{code=string,message=string,request_id=string}} "Order is not awaiting checkout"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Failed to create payment intent"
+// @Router /orders/{id}/checkout [post]
+func (s *Server) checkoutOrderHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
+		return
+	}
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
+		return
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", c.Param("id")).First(&order).Error; err != nil {
+		tx.Rollback()
+		c.Error(apierr.NotFound("order_not_found", "Order not found"))
+		return
+	}
+	if order.UserID != userUUID {
+		tx.Rollback()
+		c.Error(apierr.Forbidden("cross_user_order", "Order belongs to a different user"))
+		return
+	}
+
+	if !isValidOrderTransition(order.Status, models.OrderStatusAwaitingPayment) {
+		tx.Rollback()
+		c.Error(apierr.Conflict("invalid_transition", fmt.Sprintf("Order cannot be checked out from status %s", order.Status)))
+		return
+	}
+
+	// Payment providers bill in the currency's smallest unit (cents, not dollars), so
+	// round the float total the same way it's already stored.
+	amount := int64(math.Round(order.TotalPrice * 100))
+
+	intent, err := s.paymentProvider.CreatePaymentIntent(c.Request.Context(), order.ID.String(), amount, "usd")
+	if err != nil {
+		tx.Rollback()
+		c.Error(apierr.Internal("payment_intent_failed", "Failed to create payment intent"))
+		return
+	}
+
+	order.Status = models.OrderStatusAwaitingPayment
+	if err := tx.Save(&order).Error; err != nil {
+		tx.Rollback()
+		c.Error(apierr.Internal("order_update_failed", "Failed to update order"))
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apierr.Internal("transaction_commit_failed", "Failed to commit transaction"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"client_secret": intent.ClientSecret})
+}
+
+// orderWebhookStatuses maps a verified payment.Event's Status to the OrderStatus it
+// transitions the referenced order to.
+var orderWebhookStatuses = map[payment.EventStatus]models.OrderStatus{
+	payment.EventStatusSucceeded: models.OrderStatusPaid,
+	payment.EventStatusFailed:    models.OrderStatusFailed,
+	payment.EventStatusRefunded:  models.OrderStatusRefunded,
+	payment.EventStatusCancelled: models.OrderStatusCancelled,
+}
+
+// @Summary Payment provider webhook
+// @Description Verify a payment provider webhook event and transition the order it concerns to paid/failed/refunded/cancelled, restocking reserved products when it didn't end up paid. Unauthenticated: trust is established by the provider signature, not a bearer token.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{message=string} "Event processed (or already applied by an earlier delivery)"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Invalid payload or signature"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Order referenced by the event not found"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Failed to apply the event"
+// @Router /orders/webhook [post]
+func (s *Server) orderWebhookHandler(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apierr.BadRequest("invalid_payload", "Failed to read webhook payload"))
+		return
+	}
+
+	event, err := s.paymentProvider.VerifyWebhook(payload, c.GetHeader("Stripe-Signature"))
+	if err != nil {
+		c.Error(apierr.BadRequest("invalid_signature", "Failed to verify webhook signature"))
+		return
+	}
+
+	newStatus, ok := orderWebhookStatuses[event.Status]
+	if !ok {
+		c.Error(apierr.BadRequest("unknown_event_status", "Unknown payment event status"))
+		return
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", event.OrderID).First(&order).Error; err != nil {
+		tx.Rollback()
+		c.Error(apierr.NotFound("order_not_found", "Order referenced by webhook event not found"))
+		return
+	}
+
+	if !isValidOrderTransition(order.Status, newStatus) {
+		// Providers redeliver webhooks, including ones already applied (e.g. after this
+		// order moved on to fulfilled) - report success instead of erroring so the
+		// provider stops retrying a delivery we've already handled.
+		tx.Rollback()
+		c.JSON(http.StatusOK, gin.H{"message": "Event already applied"})
+		return
+	}
+
+	if newStatus == models.OrderStatusFailed || newStatus == models.OrderStatusCancelled {
+		if err := restoreOrderStock(tx, order.ID); err != nil {
+			tx.Rollback()
+			c.Error(apierr.Internal("stock_restore_failed", "Failed to restore stock"))
+			return
+		}
+	}
+
+	order.Status = newStatus
+	if err := tx.Save(&order).Error; err != nil {
+		tx.Rollback()
+		c.Error(apierr.Internal("order_update_failed", "Failed to update order"))
+		return
+	}
+
+	if newStatus == models.OrderStatusPaid {
+		if err := outbox.Write(tx, order.ID.String(), outbox.EventOrderPaid, map[string]any{"order_id": order.ID}); err != nil {
+			tx.Rollback()
+			c.Error(apierr.Internal("transaction_commit_failed", "Failed to record order.paid event"))
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apierr.Internal("transaction_commit_failed", "Failed to commit transaction"))
+		return
+	}
+
+	if newStatus == models.OrderStatusFailed || newStatus == models.OrderStatusCancelled {
+		s.invalidateProductCache()
+	}
+
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventAdminAction,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"action": "payment_webhook", "order_id": order.ID, "status": newStatus},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Event processed"})
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tundra/internal/auth"
+	"tundra/internal/database/models"
+	"tundra/internal/payment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCheckoutRouter(t *testing.T) (*Server, *gin.Engine) {
+	server, router := setupTestServer(t)
+	router.POST("/orders/:id/checkout", auth.AuthMiddleware(), server.idempotencyMiddleware(), server.checkoutOrderHandler)
+	router.POST("/orders/webhook", server.orderWebhookHandler)
+	return server, router
+}
+
+func TestCheckoutOrderHandler_CreatesPaymentIntent(t *testing.T) {
+	server, router := setupCheckoutRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "checkoutuser", "checkout@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	order, _ := createTestOrderWithProduct(t, server, *user, 5, 2)
+
+	req, _ := http.NewRequest("POST", "/orders/"+order.ID.String()+"/checkout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		ClientSecret string `json:"client_secret"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.NotEmpty(t, body.ClientSecret)
+
+	var updated models.Order
+	require.NoError(t, server.db.First(&updated, order.ID).Error)
+	assert.Equal(t, models.OrderStatusAwaitingPayment, updated.Status)
+
+	fake := server.paymentProvider.(*payment.FakeProvider)
+	require.Len(t, fake.Intents, 1)
+	assert.Equal(t, order.ID.String(), fake.Intents[0].OrderID)
+}
+
+// TestCheckoutOrderHandler_IdempotentRetryReusesIntent asserts a retried checkout request with
+// the same Idempotency-Key doesn't create a second payment intent with the provider.
+func TestCheckoutOrderHandler_IdempotentRetryReusesIntent(t *testing.T) {
+	server, router := setupCheckoutRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "checkoutretryuser", "checkoutretry@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	order, _ := createTestOrderWithProduct(t, server, *user, 5, 2)
+
+	post := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/orders/"+order.ID.String()+"/checkout", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Idempotency-Key", "checkout-retry-key")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	first := post()
+	require.Equal(t, http.StatusOK, first.Code)
+
+	second := post()
+	require.Equal(t, http.StatusOK, second.Code)
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+
+	fake := server.paymentProvider.(*payment.FakeProvider)
+	assert.Len(t, fake.Intents, 1, "a retried checkout must not create a second payment intent")
+}
+
+func TestCheckoutOrderHandler_RejectsOtherUsersOrder(t *testing.T) {
+	server, router := setupCheckoutRouter(t)
+	defer cleanupTestDatabase(t)
+
+	owner := createTestUser(t, server.db, "checkoutowner", "checkoutowner@test.com", "Password123!")
+	other := createTestUser(t, server.db, "checkoutother", "checkoutother@test.com", "Password123!")
+	otherToken, err := auth.GenerateJWT(other.ID, other.Username, other.Email, other.Role)
+	require.NoError(t, err)
+
+	order, _ := createTestOrderWithProduct(t, server, *owner, 5, 1)
+
+	req, _ := http.NewRequest("POST", "/orders/"+order.ID.String()+"/checkout", nil)
+	req.Header.Set("Authorization", "Bearer "+otherToken)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestOrderWebhookHandler_TransitionsToPaid(t *testing.T) {
+	server, router := setupCheckoutRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "webhookuser", "webhook@test.com", "Password123!")
+	order, _ := createTestOrderWithProduct(t, server, *user, 5, 2)
+	require.NoError(t, server.db.Model(&order).Update("status", models.OrderStatusAwaitingPayment).Error)
+
+	payload, sig := payment.BuildWebhookPayload(payment.Event{OrderID: order.ID.String(), Status: payment.EventStatusSucceeded})
+	req, _ := http.NewRequest("POST", "/orders/webhook", bytes.NewBuffer(payload))
+	req.Header.Set("Stripe-Signature", sig)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var updated models.Order
+	require.NoError(t, server.db.First(&updated, order.ID).Error)
+	assert.Equal(t, models.OrderStatusPaid, updated.Status)
+}
+
+func TestOrderWebhookHandler_RestocksOnFailed(t *testing.T) {
+	server, router := setupCheckoutRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "webhookfailuser", "webhookfail@test.com", "Password123!")
+	order, product := createTestOrderWithProduct(t, server, *user, 5, 3)
+	require.NoError(t, server.db.Model(&order).Update("status", models.OrderStatusAwaitingPayment).Error)
+
+	payload, sig := payment.BuildWebhookPayload(payment.Event{OrderID: order.ID.String(), Status: payment.EventStatusFailed})
+	req, _ := http.NewRequest("POST", "/orders/webhook", bytes.NewBuffer(payload))
+	req.Header.Set("Stripe-Signature", sig)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var updated models.Order
+	require.NoError(t, server.db.First(&updated, order.ID).Error)
+	assert.Equal(t, models.OrderStatusFailed, updated.Status)
+
+	var restoredProduct models.Product
+	require.NoError(t, server.db.First(&restoredProduct, product.ID).Error)
+	assert.Equal(t, int64(8), restoredProduct.Stock)
+}
+
+func TestOrderWebhookHandler_RejectsBadSignature(t *testing.T) {
+	server, router := setupCheckoutRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "webhookbaduser", "webhookbad@test.com", "Password123!")
+	order, _ := createTestOrderWithProduct(t, server, *user, 5, 1)
+	require.NoError(t, server.db.Model(&order).Update("status", models.OrderStatusAwaitingPayment).Error)
+
+	payload, _ := payment.BuildWebhookPayload(payment.Event{OrderID: order.ID.String(), Status: payment.EventStatusSucceeded})
+	req, _ := http.NewRequest("POST", "/orders/webhook", bytes.NewBuffer(payload))
+	req.Header.Set("Stripe-Signature", "not-the-real-signature")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
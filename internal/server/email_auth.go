@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GRACENOBLE/tundra/internal/audit"
+	"github.com/GRACENOBLE/tundra/internal/auth"
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordResetTTL is how long a POST /auth/forgot-password token remains redeemable.
+const passwordResetTTL = 30 * time.Minute
+
+// emailVerificationRequired reports whether loginHandler should refuse an unverified
+// account. It's opt-in via EMAIL_VERIFICATION_REQUIRED so existing deployments that haven't
+// configured a mailer aren't locked out of their own accounts.
+func emailVerificationRequired() bool {
+	required, _ := strconv.ParseBool(os.Getenv("EMAIL_VERIFICATION_REQUIRED"))
+	return required
+}
+
+// sendVerificationEmail emails token to the given address so the user can complete
+// GET /auth/verify. Failures are logged, not surfaced to the caller - signUpHandler has
+// already committed the user, and the account remains usable (or not, per
+// EMAIL_VERIFICATION_REQUIRED) regardless of whether the email actually lands.
+func (s *Server) sendVerificationEmail(to, token string) {
+	body := fmt.Sprintf("Verify your Tundra account by visiting: /auth/verify?token=%s", token)
+	if err := s.mailer.Send(to, "Verify your email", body); err != nil {
+		fmt.Printf("Warning: failed to send verification email to %s: %v\n", to, err)
+	}
+}
+
+// @Summary Verify an email address
+// @Description Consume the token emailed at signup and mark the account's email as verified.
+// @Tags Authentication
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} object{message=string} "Email verified"
+// @Failure 400 {object} object{error=string} "Missing token"
+// @Failure 401 {object} object{error=string} "Invalid or already-used token"
+// @Router /auth/verify [get]
+func (s *Server) verifyEmailHandler(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token query parameter is required"})
+		return
+	}
+
+	var user models.User
+	if err := s.db.Where("verification_token_hash = ?", auth.HashSecureToken(token)).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+
+	user.EmailVerified = true
+	user.VerificationTokenHash = ""
+	if err := s.db.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// @Summary Request a password reset
+// @Description Email a single-use, 30-minute password reset token if the address belongs to an account. Returns 200 whether or not the address is registered, so the endpoint can't be used to enumerate registered emails; returns 503 when Redis isn't configured.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body object{email=string} true "Forgot Password Request"
+// @Success 200 {object} object{message=string} "Reset email sent if the account exists"
+// @Failure 400 {object} object{error=string} "Missing email"
+// @Router /auth/forgot-password [post]
+func (s *Server) forgotPasswordHandler(c *gin.Context) {
+	var forgotRequest struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&forgotRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		return
+	}
+
+	const genericResponse = "If an account with that email exists, a password reset link has been sent"
+
+	if s.redis == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Password reset is temporarily unavailable"})
+		return
+	}
+
+	var user models.User
+	if err := s.db.Where("email = ?", forgotRequest.Email).First(&user).Error; err != nil {
+		// Don't reveal whether the email is registered.
+		c.JSON(http.StatusOK, gin.H{"message": genericResponse})
+		return
+	}
+
+	token, hash, err := auth.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start password reset"})
+		return
+	}
+
+	if err := s.redis.Set(context.Background(), "pwreset:"+hash, user.ID.String(), passwordResetTTL).Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start password reset"})
+		return
+	}
+
+	body := fmt.Sprintf("Reset your Tundra password by visiting: /auth/reset-password?token=%s", token)
+	if err := s.mailer.Send(user.Email, "Reset your password", body); err != nil {
+		fmt.Printf("Warning: failed to send password reset email to %s: %v\n", user.Email, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": genericResponse})
+}
+
+// @Summary Complete a password reset
+// @Description Exchange a token from POST /auth/forgot-password plus a new password for an updated password.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body object{token=string,password=string} true "Reset Password Request"
+// @Success 200 {object} object{message=string} "Password updated"
+// @Failure 400 {object} object{error=string} "Missing fields or weak password"
+// @Failure 401 {object} object{error=string} "Invalid or expired token"
+// @Router /auth/reset-password [post]
+func (s *Server) resetPasswordHandler(c *gin.Context) {
+	var resetRequest struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&resetRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token and password are required"})
+		return
+	}
+
+	if err := auth.ValidatePassword(resetRequest.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if s.redis == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Password reset is temporarily unavailable"})
+		return
+	}
+
+	ctx := context.Background()
+	key := "pwreset:" + auth.HashSecureToken(resetRequest.Token)
+
+	userID, err := s.redis.Get(ctx, key).Result()
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired password reset token"})
+		return
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired password reset token"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(resetRequest.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	user.Password = string(hashedPassword)
+	if err := s.db.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	s.redis.Del(ctx, key)
+
+	audit.Emit(ctx, audit.Event{
+		Type:      audit.EventPasswordChanged,
+		UserID:    &user.ID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tundra/internal/database/models"
+	"tundra/internal/mailer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenFromEmailBody extracts the "token=..." query value a verification/reset email body
+// links to, so a test can drive the follow-up request without reaching into the database.
+func tokenFromEmailBody(body string) string {
+	idx := strings.Index(body, "token=")
+	if idx == -1 {
+		return ""
+	}
+	return body[idx+len("token="):]
+}
+
+func TestVerifyEmailHandler(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.POST("/auth/register", server.signUpHandler)
+	router.GET("/auth/verify", server.verifyEmailHandler)
+
+	reqBody := map[string]string{
+		"username": "verifyme",
+		"email":    "verifyme@example.com",
+		"password": "Password123!",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusCreated, resp.Code)
+
+	fake := server.mailer.(*mailer.FakeMailer)
+	require.Len(t, fake.Messages, 1)
+	token := tokenFromEmailBody(fake.Last().Body)
+	require.NotEmpty(t, token)
+
+	t.Run("Rejects an invalid token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/auth/verify?token=not-the-real-token", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	req, _ = http.NewRequest("GET", "/auth/verify?token="+token, nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var user models.User
+	require.NoError(t, server.db.Where("email = ?", "verifyme@example.com").First(&user).Error)
+	assert.True(t, user.EmailVerified)
+	assert.Empty(t, user.VerificationTokenHash)
+
+	t.Run("Rejects a token that was already consumed", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/auth/verify?token="+token, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
+
+func TestLoginHandler_RequiresVerifiedEmailWhenConfigured(t *testing.T) {
+	t.Setenv("EMAIL_VERIFICATION_REQUIRED", "true")
+
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.POST("/auth/login", server.loginHandler)
+
+	createTestUser(t, server.db, "unverified", "unverified@example.com", "Password123!")
+
+	reqBody := map[string]string{
+		"email":    "unverified@example.com",
+		"password": "Password123!",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestForgotAndResetPasswordHandlers(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+
+	router.POST("/auth/forgot-password", server.forgotPasswordHandler)
+	router.POST("/auth/reset-password", server.resetPasswordHandler)
+	router.POST("/auth/login", server.loginHandler)
+
+	createTestUser(t, server.db, "resetme", "resetme@example.com", "OldPassword123!")
+
+	t.Run("Unknown email still returns 200", func(t *testing.T) {
+		reqBody := map[string]string{"email": "nobody@example.com"}
+		jsonBody, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/auth/forgot-password", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	reqBody := map[string]string{"email": "resetme@example.com"}
+	jsonBody, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("POST", "/auth/forgot-password", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	fake := server.mailer.(*mailer.FakeMailer)
+	require.Len(t, fake.Messages, 1)
+	token := tokenFromEmailBody(fake.Last().Body)
+	require.NotEmpty(t, token)
+
+	t.Run("Rejects an invalid token", func(t *testing.T) {
+		resetBody := map[string]string{"token": "not-the-real-token", "password": "NewPassword123!"}
+		jsonBody, _ := json.Marshal(resetBody)
+		req, _ := http.NewRequest("POST", "/auth/reset-password", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	resetBody := map[string]string{"token": token, "password": "NewPassword123!"}
+	jsonBody, _ = json.Marshal(resetBody)
+	req, _ = http.NewRequest("POST", "/auth/reset-password", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	t.Run("Token can't be reused", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/reset-password", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Can log in with the new password", func(t *testing.T) {
+		loginBody := map[string]string{"email": "resetme@example.com", "password": "NewPassword123!"}
+		jsonBody, _ := json.Marshal(loginBody)
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+}
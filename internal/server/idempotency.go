@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// idempotencyKeyTTL is how long an idempotency_keys row is kept before
+// startIdempotencyKeyCleanup reaps it. A client retrying well past this window is treated as
+// a brand-new request rather than a duplicate.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyMiddleware makes the wrapped handler safe to retry: a client that resends the
+// same Idempotency-Key header with the same request body gets the original response replayed
+// verbatim instead of the handler running (and its side effects happening) again. Requests
+// without the header, or made before AuthMiddleware has set userID, pass straight through
+// unguarded.
+func (s *Server) idempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.Next()
+			return
+		}
+		userUUID, err := uuid.Parse(userID.(string))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+			c.Abort()
+			return
+		}
+
+		bodyBytes, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+		sum := sha256.Sum256(bodyBytes)
+		requestHash := hex.EncodeToString(sum[:])
+
+		record := models.IdempotencyKey{
+			UserID:      userUUID,
+			Key:         key,
+			RequestHash: requestHash,
+			LockedAt:    time.Now(),
+		}
+
+		if err := s.db.Create(&record).Error; err != nil {
+			if !isUniqueViolation(err) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process idempotency key"})
+				c.Abort()
+				return
+			}
+
+			// Another request already holds this (user, key) pair - decide how to respond
+			// without running the handler a second time.
+			var existing models.IdempotencyKey
+			if err := s.db.Where("user_id = ? AND key = ?", record.UserID, key).First(&existing).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process idempotency key"})
+				c.Abort()
+				return
+			}
+
+			if existing.RequestHash != requestHash {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key reuse with different payload"})
+				c.Abort()
+				return
+			}
+
+			if existing.CompletedAt == nil {
+				c.JSON(http.StatusConflict, gin.H{"error": "Request in progress"})
+				c.Abort()
+				return
+			}
+
+			c.Data(existing.ResponseStatus, "application/json", []byte(existing.ResponseBody))
+			c.Abort()
+			return
+		}
+
+		capture := &idempotencyResponseCapture{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = capture
+
+		c.Next()
+
+		status := capture.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		now := time.Now()
+		updates := map[string]interface{}{
+			"response_status": status,
+			"response_body":   capture.body.String(),
+			"completed_at":    now,
+		}
+		if orderID, ok := extractOrderID(capture.body.Bytes()); ok {
+			updates["order_id"] = orderID
+		}
+		s.db.Model(&models.IdempotencyKey{}).Where("id = ?", record.ID).Updates(updates)
+	}
+}
+
+// idempotencyResponseCapture mirrors everything written to the real gin.ResponseWriter into
+// body too, so idempotencyMiddleware can persist the exact bytes a replayed duplicate
+// request should see.
+type idempotencyResponseCapture struct {
+	gin.ResponseWriter
+	body   *bytes.Buffer
+	status int
+}
+
+func (w *idempotencyResponseCapture) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyResponseCapture) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// extractOrderID pulls the "id" field out of a successful create-order response body, so the
+// idempotency record can reference the order it produced.
+func extractOrderID(body []byte) (uuid.UUID, bool) {
+	var parsed struct {
+		ID uuid.UUID `json:"id"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil || parsed.ID == uuid.Nil {
+		return uuid.UUID{}, false
+	}
+	return parsed.ID, true
+}
+
+// isUniqueViolation reports whether err is a Postgres unique-constraint violation - used to
+// detect a racing duplicate request that beat this one to inserting its idempotency key row.
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "duplicate key")
+}
+
+// startIdempotencyKeyCleanup periodically deletes idempotency_keys rows older than
+// idempotencyKeyTTL so the table doesn't grow unbounded. Runs for the lifetime of the
+// process; stop via the returned function (used by tests to avoid leaking goroutines).
+func (s *Server) startIdempotencyKeyCleanup(interval time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.db.Where("created_at < ?", time.Now().Add(-idempotencyKeyTTL)).Delete(&models.IdempotencyKey{})
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"tundra/internal/auth"
+	"tundra/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIdempotencyOrderRouter(t *testing.T) (*Server, *gin.Engine, string, *models.Product) {
+	server, router := setupTestServer(t)
+	router.POST("/orders", auth.AuthMiddleware(), server.idempotencyMiddleware(), server.createOrderHandler)
+
+	user := createTestUser(t, server.db, "idempotencyuser", "idempotency@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	product := models.Product{
+		Name:        "Idempotency Test Product",
+		Description: "Description",
+		Price:       10.0,
+		Stock:       100,
+		Category:    "Category",
+	}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	return server, router, token, &product
+}
+
+func postOrder(router *gin.Engine, token, idempotencyKey string, body []byte) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// TestIdempotentOrderCreation_ReplaysStoredResponse asserts that a duplicate request with
+// the same Idempotency-Key and the same body gets the exact original response back, and
+// that stock is only deducted once.
+func TestIdempotentOrderCreation_ReplaysStoredResponse(t *testing.T) {
+	server, router, token, product := setupIdempotencyOrderRouter(t)
+	defer cleanupTestDatabase(t)
+
+	body, _ := json.Marshal([]map[string]interface{}{{"productId": product.ID.String(), "quantity": 2}})
+
+	first := postOrder(router, token, "replay-key", body)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	second := postOrder(router, token, "replay-key", body)
+	require.Equal(t, http.StatusCreated, second.Code)
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+
+	var refreshed models.Product
+	require.NoError(t, server.db.First(&refreshed, "id = ?", product.ID).Error)
+	assert.Equal(t, product.Stock-2, refreshed.Stock, "stock should only be deducted once across the replayed requests")
+}
+
+// TestIdempotentOrderCreation_DifferentBodyRejected asserts that reusing a key with a
+// different request body is rejected outright rather than replayed or re-executed.
+func TestIdempotentOrderCreation_DifferentBodyRejected(t *testing.T) {
+	_, router, token, product := setupIdempotencyOrderRouter(t)
+	defer cleanupTestDatabase(t)
+
+	firstBody, _ := json.Marshal([]map[string]interface{}{{"productId": product.ID.String(), "quantity": 1}})
+	first := postOrder(router, token, "mismatch-key", firstBody)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	secondBody, _ := json.Marshal([]map[string]interface{}{{"productId": product.ID.String(), "quantity": 2}})
+	second := postOrder(router, token, "mismatch-key", secondBody)
+	assert.Equal(t, http.StatusUnprocessableEntity, second.Code)
+	assert.Contains(t, second.Body.String(), "Idempotency-Key reuse with different payload")
+}
+
+// TestIdempotentOrderCreation_ConcurrentDuplicateConflicts asserts that a duplicate request
+// arriving while the first is still in flight gets 409 instead of racing the transaction.
+func TestIdempotentOrderCreation_ConcurrentDuplicateConflicts(t *testing.T) {
+	server, router, token, product := setupIdempotencyOrderRouter(t)
+	defer cleanupTestDatabase(t)
+
+	// Pre-seed a locked, not-yet-completed row, simulating a request that's still holding
+	// the lock - the concurrency scenario the middleware needs to protect against.
+	user := &models.User{}
+	require.NoError(t, server.db.Where("username = ?", "idempotencyuser").First(user).Error)
+
+	body, _ := json.Marshal([]map[string]interface{}{{"productId": product.ID.String(), "quantity": 1}})
+	sum := sha256.Sum256(body)
+
+	require.NoError(t, server.db.Create(&models.IdempotencyKey{
+		UserID:      user.ID,
+		Key:         "in-flight-key",
+		RequestHash: hex.EncodeToString(sum[:]),
+	}).Error)
+
+	resp := postOrder(router, token, "in-flight-key", body)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Request in progress")
+}
+
+// TestIdempotentOrderCreation_ConcurrentFreshKeysRaceSafely fires the same brand-new key
+// from several goroutines at once and asserts exactly one order was created - the rest see
+// either the replayed response or a 409, never a second order.
+func TestIdempotentOrderCreation_ConcurrentFreshKeysRaceSafely(t *testing.T) {
+	server, router, token, product := setupIdempotencyOrderRouter(t)
+	defer cleanupTestDatabase(t)
+
+	body, _ := json.Marshal([]map[string]interface{}{{"productId": product.ID.String(), "quantity": 1}})
+
+	const attempts = 5
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = postOrder(router, token, "racing-key", body).Code
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		require.Contains(t, []int{http.StatusCreated, http.StatusConflict}, code, "unexpected status %d", code)
+		if code == http.StatusCreated {
+			created++
+		}
+	}
+	assert.GreaterOrEqual(t, created, 1, "at least one request should have succeeded")
+
+	var orderCount int64
+	require.NoError(t, server.db.Model(&models.Order{}).Count(&orderCount).Error)
+	assert.Equal(t, int64(1), orderCount, "only one order should have been created across all racing duplicates")
+}
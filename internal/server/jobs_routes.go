@@ -0,0 +1,70 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultJobListLimit bounds how many jobs listJobsHandler returns when the caller doesn't
+// specify a limit.
+const defaultJobListLimit = 50
+
+// @Summary Get job status
+// @Description Get the status and result of a background job (e.g. a queued product image upload). Any authenticated user may poll a job id they were given.
+// @Tags Jobs
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Job ID"
+// @Success 200 {object} jobs.Job "Job status and result"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 404 {object} object{error=string} "Job not found"
+// @Router /jobs/{id} [get]
+func (s *Server) getJobHandler(c *gin.Context) {
+	job, ok, err := s.jobQueue.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// @Summary List recent background jobs
+// @Description List the most recently created background jobs, newest first (Admin only).
+// @Tags Jobs
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} object{jobs=[]jobs.Job} "Recent jobs"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
+// @Router /admin/jobs [get]
+func (s *Server) listJobsHandler(c *gin.Context) {
+	jobList, err := s.jobQueue.List(c.Request.Context(), defaultJobListLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobList})
+}
+
+// @Summary List dead-lettered background jobs
+// @Description List every job that exhausted its retries without succeeding (Admin only).
+// @Tags Jobs
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} object{jobs=[]jobs.Job} "Dead-lettered jobs"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
+// @Router /admin/jobs/dead-letter [get]
+func (s *Server) listDeadLetterJobsHandler(c *gin.Context) {
+	jobList, err := s.jobQueue.ListDeadLetter(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list dead-lettered jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobList})
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tundra/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doLoginAttempt(router http.Handler, email, password string) *httptest.ResponseRecorder {
+	reqBody, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestLoginHandler_RateLimitAndLockout(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	cfg := auth.LoginRateLimitConfig{
+		Window:              time.Minute,
+		MaxFailuresPerEmail: 3,
+		MaxFailuresPerIP:    10,
+		LockoutDuration:     time.Minute,
+	}
+	router.POST("/auth/login", auth.LoginRateLimiter(server.redis, cfg), server.loginHandler)
+
+	createTestUser(t, server.db, "lockoutuser", "lockoutuser@example.com", "Password123!")
+
+	t.Run("locks the account after MaxFailuresPerEmail consecutive failures", func(t *testing.T) {
+		for i := 0; i < cfg.MaxFailuresPerEmail; i++ {
+			resp := doLoginAttempt(router, "lockoutuser@example.com", "WrongPassword!")
+			assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		}
+
+		// The account should now be locked, even with the correct password.
+		resp := doLoginAttempt(router, "lockoutuser@example.com", "Password123!")
+		assert.Equal(t, http.StatusLocked, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+	})
+
+	t.Run("a successful login clears the email failure counter", func(t *testing.T) {
+		createTestUser(t, server.db, "resetuser", "resetuser@example.com", "Password123!")
+
+		for i := 0; i < cfg.MaxFailuresPerEmail-1; i++ {
+			resp := doLoginAttempt(router, "resetuser@example.com", "WrongPassword!")
+			require.Equal(t, http.StatusUnauthorized, resp.Code)
+		}
+
+		resp := doLoginAttempt(router, "resetuser@example.com", "Password123!")
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		// Having reset the counter, another run of failures up to the threshold should not
+		// be locked out yet.
+		for i := 0; i < cfg.MaxFailuresPerEmail-1; i++ {
+			resp := doLoginAttempt(router, "resetuser@example.com", "WrongPassword!")
+			assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		}
+	})
+
+	t.Run("throttles by IP once MaxFailuresPerIP is exceeded, across different emails", func(t *testing.T) {
+		// Use a distinct, never-repeated email per attempt so none of them individually
+		// trips the per-email lockout before the shared IP counter does.
+		for i := 0; i < cfg.MaxFailuresPerIP; i++ {
+			email := fmt.Sprintf("ip-throttle-%d@example.com", i)
+			doLoginAttempt(router, email, "WrongPassword!")
+		}
+
+		resp := doLoginAttempt(router, "ip-throttle-final@example.com", "WrongPassword!")
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+	})
+}
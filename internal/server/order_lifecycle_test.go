@@ -0,0 +1,140 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tundra/internal/auth"
+	"tundra/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupOrderLifecycleRouter(t *testing.T) (*Server, *gin.Engine) {
+	server, router := setupTestServer(t)
+	router.POST("/orders/:id/cancel", auth.AuthMiddleware(), server.cancelOrderHandler)
+	router.PATCH("/orders/:id/status", auth.AuthMiddleware(), auth.RequireAnyRole("admin"), server.updateOrderStatusHandler)
+	return server, router
+}
+
+func createTestOrderWithProduct(t *testing.T, server *Server, userID models.User, stock int64, quantity int) (models.Order, models.Product) {
+	product := models.Product{Name: "Widget", Description: "A widget", Price: 10, Stock: stock, Category: "Misc"}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	order := models.Order{UserID: userID.ID, Description: "Test order", TotalPrice: 10 * float64(quantity), Status: models.OrderStatusPending}
+	require.NoError(t, server.db.Create(&order).Error)
+
+	orderProduct := models.OrderProduct{OrderID: order.ID, ProductID: product.ID, Quantity: quantity, Price: product.Price}
+	require.NoError(t, server.db.Create(&orderProduct).Error)
+
+	return order, product
+}
+
+func TestCancelOrderHandler_RestoresStock(t *testing.T) {
+	server, router := setupOrderLifecycleRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "canceluser", "cancel@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	order, product := createTestOrderWithProduct(t, server, *user, 5, 3)
+
+	req, _ := http.NewRequest("POST", "/orders/"+order.ID.String()+"/cancel", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var updated models.Order
+	require.NoError(t, server.db.First(&updated, order.ID).Error)
+	assert.Equal(t, models.OrderStatusCancelled, updated.Status)
+
+	var restoredProduct models.Product
+	require.NoError(t, server.db.First(&restoredProduct, product.ID).Error)
+	assert.Equal(t, int64(8), restoredProduct.Stock)
+}
+
+func TestCancelOrderHandler_RejectsOtherUsersOrder(t *testing.T) {
+	server, router := setupOrderLifecycleRouter(t)
+	defer cleanupTestDatabase(t)
+
+	owner := createTestUser(t, server.db, "owneruser", "owner@test.com", "Password123!")
+	other := createTestUser(t, server.db, "otheruser", "other@test.com", "Password123!")
+	otherToken, err := auth.GenerateJWT(other.ID, other.Username, other.Email, other.Role)
+	require.NoError(t, err)
+
+	order, _ := createTestOrderWithProduct(t, server, *owner, 5, 1)
+
+	req, _ := http.NewRequest("POST", "/orders/"+order.ID.String()+"/cancel", nil)
+	req.Header.Set("Authorization", "Bearer "+otherToken)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestCancelOrderHandler_RejectsAlreadyCancelledOrder(t *testing.T) {
+	server, router := setupOrderLifecycleRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "canceltwiceuser", "canceltwice@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	order, _ := createTestOrderWithProduct(t, server, *user, 5, 1)
+	require.NoError(t, server.db.Model(&order).Update("status", models.OrderStatusCancelled).Error)
+
+	req, _ := http.NewRequest("POST", "/orders/"+order.ID.String()+"/cancel", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestUpdateOrderStatusHandler_RequiresAdmin(t *testing.T) {
+	server, router := setupOrderLifecycleRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "nonadminuser", "nonadmin@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	order, _ := createTestOrderWithProduct(t, server, *user, 5, 1)
+
+	req, _ := http.NewRequest("PATCH", "/orders/"+order.ID.String()+"/status", strings.NewReader(`{"status":"paid"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestUpdateOrderStatusHandler_RejectsInvalidTransition(t *testing.T) {
+	server, router := setupOrderLifecycleRouter(t)
+	defer cleanupTestDatabase(t)
+
+	admin := createTestUser(t, server.db, "statusadmin", "statusadmin@test.com", "Password123!")
+	admin.Role = "admin"
+	require.NoError(t, server.db.Save(admin).Error)
+	token, err := auth.GenerateJWT(admin.ID, admin.Username, admin.Email, admin.Role)
+	require.NoError(t, err)
+
+	order, _ := createTestOrderWithProduct(t, server, *admin, 5, 1)
+
+	// pending -> fulfilled is not an allowed transition; paid must happen first.
+	req, _ := http.NewRequest("PATCH", "/orders/"+order.ID.String()+"/status", strings.NewReader(`{"status":"fulfilled"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
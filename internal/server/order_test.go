@@ -1,7 +1,21 @@
 package server
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"tundra/internal/auth"
+	"tundra/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestCreateOrderValidation(t *testing.T) {
@@ -253,7 +267,7 @@ func TestGetOrdersResponse(t *testing.T) {
 	t.Run("Success response with orders", func(t *testing.T) {
 		t.Log("Successful retrieval with orders should return:")
 		t.Log("- Status: 200 OK")
-		t.Log("- Body: Array of order objects")
+		t.Log("- Body: { orders: [...], next_cursor: string, has_more: bool }")
 		t.Log("- Each order includes:")
 		t.Log("  - id: Order UUID")
 		t.Log("  - user_id: User who placed the order")
@@ -267,7 +281,7 @@ func TestGetOrdersResponse(t *testing.T) {
 	t.Run("Success response with no orders", func(t *testing.T) {
 		t.Log("Successful retrieval with no orders should return:")
 		t.Log("- Status: 200 OK")
-		t.Log("- Body: Empty array []")
+		t.Log("- Body: { orders: [], next_cursor: \"\", has_more: false }")
 		t.Log("- Not an error condition")
 	})
 
@@ -295,11 +309,11 @@ func TestGetOrdersDataScope(t *testing.T) {
 		t.Log("- Ensures privacy and data security")
 	})
 
-	t.Run("All user orders included", func(t *testing.T) {
+	t.Run("All user orders reachable via pagination", func(t *testing.T) {
 		t.Log("Completeness requirements:")
-		t.Log("- All orders for the user returned")
-		t.Log("- No pagination (all orders in single response)")
-		t.Log("- Includes orders with any status (pending, completed, cancelled, etc.)")
+		t.Log("- Every order for the user is reachable by following next_cursor")
+		t.Log("- Keyset pagination (created_at, id) rather than OFFSET, so deep pages stay cheap")
+		t.Log("- Includes orders with any status unless the status filter narrows them")
 	})
 }
 
@@ -309,7 +323,8 @@ func TestGetOrdersEndpoint(t *testing.T) {
 		t.Log("- Method: GET")
 		t.Log("- Path: /orders")
 		t.Log("- Headers: Authorization: Bearer <jwt-token>")
-		t.Log("- No query parameters or request body")
+		t.Log("- Query parameters: limit, cursor, status, from, to (all optional)")
+		t.Log("- No request body")
 	})
 
 	t.Run("Use case", func(t *testing.T) {
@@ -329,3 +344,241 @@ func TestGetOrdersTimestamps(t *testing.T) {
 		t.Log("- Used for sorting and display")
 	})
 }
+
+// ==================== GET /orders Pagination Tests ====================
+
+type ordersPageResponse struct {
+	Orders     []models.Order `json:"orders"`
+	NextCursor string         `json:"next_cursor"`
+	HasMore    bool           `json:"has_more"`
+}
+
+func setupOrdersPaginationRouter(t *testing.T) (*Server, *gin.Engine, string, *models.User) {
+	server, router := setupTestServer(t)
+	router.GET("/orders", auth.AuthMiddleware(), server.getOrdersHandler)
+
+	user := createTestUser(t, server.db, "ordersuser", "orders@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	return server, router, token, user
+}
+
+// createTestOrder inserts an order directly (bypassing createOrderHandler, which isn't
+// under test here) with an explicit createdAt so pagination ordering is deterministic.
+func createTestOrder(t *testing.T, db *gorm.DB, userID uuid.UUID, status string, createdAt time.Time) models.Order {
+	order := models.Order{
+		UserID:      userID,
+		Description: "Test order",
+		TotalPrice:  9.99,
+		Status:      models.OrderStatus(status),
+	}
+	require.NoError(t, db.Create(&order).Error)
+	// CreatedAt has autoCreateTime, so it must be overwritten with a direct UPDATE rather
+	// than a second Save, which GORM's hooks would just reset to "now" again.
+	require.NoError(t, db.Model(&models.Order{}).Where("id = ?", order.ID).Update("created_at", createdAt).Error)
+	order.CreatedAt = createdAt
+	return order
+}
+
+func getOrders(t *testing.T, router *gin.Engine, token, query string) (*httptest.ResponseRecorder, ordersPageResponse) {
+	req, _ := http.NewRequest("GET", "/orders"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var page ordersPageResponse
+	if resp.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+	}
+	return resp, page
+}
+
+func TestGetOrdersHandler_EmptyFirstPage(t *testing.T) {
+	_, router, token, _ := setupOrdersPaginationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	resp, page := getOrders(t, router, token, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, page.Orders)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestGetOrdersHandler_CursorRoundTrip(t *testing.T) {
+	server, router, token, user := setupOrdersPaginationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	base := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		createTestOrder(t, server.db, user.ID, "pending", base.Add(time.Duration(i)*time.Minute))
+	}
+
+	first, page1 := getOrders(t, router, token, "?limit=2")
+	require.Equal(t, http.StatusOK, first.Code)
+	require.Len(t, page1.Orders, 2)
+	assert.True(t, page1.HasMore)
+	assert.NotEmpty(t, page1.NextCursor)
+	// Newest first: order 4 (base+4m) then order 3 (base+3m).
+	assert.Equal(t, base.Add(4*time.Minute), page1.Orders[0].CreatedAt.UTC())
+	assert.Equal(t, base.Add(3*time.Minute), page1.Orders[1].CreatedAt.UTC())
+
+	second, page2 := getOrders(t, router, token, "?limit=2&cursor="+page1.NextCursor)
+	require.Equal(t, http.StatusOK, second.Code)
+	require.Len(t, page2.Orders, 2)
+	assert.True(t, page2.HasMore)
+	assert.Equal(t, base.Add(2*time.Minute), page2.Orders[0].CreatedAt.UTC())
+	assert.Equal(t, base.Add(1*time.Minute), page2.Orders[1].CreatedAt.UTC())
+
+	third, page3 := getOrders(t, router, token, "?limit=2&cursor="+page2.NextCursor)
+	require.Equal(t, http.StatusOK, third.Code)
+	require.Len(t, page3.Orders, 1)
+	assert.False(t, page3.HasMore)
+	assert.Empty(t, page3.NextCursor)
+	assert.Equal(t, base, page3.Orders[0].CreatedAt.UTC())
+
+	seen := map[uuid.UUID]bool{}
+	for _, o := range append(append(page1.Orders, page2.Orders...), page3.Orders...) {
+		assert.False(t, seen[o.ID], "order %s returned more than once across pages", o.ID)
+		seen[o.ID] = true
+	}
+	assert.Len(t, seen, 5)
+}
+
+func TestGetOrdersHandler_TamperedCursorRejected(t *testing.T) {
+	_, router, token, _ := setupOrdersPaginationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	cases := []string{
+		"not-valid-base64!!!",
+		base64.URLEncoding.EncodeToString([]byte("missing-separator")),
+		base64.URLEncoding.EncodeToString([]byte("not-a-timestamp|" + uuid.NewString())),
+		base64.URLEncoding.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano) + "|not-a-uuid")),
+	}
+
+	for _, cursor := range cases {
+		resp, _ := getOrders(t, router, token, "?cursor="+cursor)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "cursor %q should be rejected", cursor)
+	}
+}
+
+func TestGetOrdersHandler_InvalidLimitRejected(t *testing.T) {
+	_, router, token, _ := setupOrdersPaginationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	for _, limit := range []string{"0", "-1", "101", "not-a-number"} {
+		resp, _ := getOrders(t, router, token, "?limit="+limit)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "limit %q should be rejected", limit)
+	}
+}
+
+func TestGetOrdersHandler_StatusFilter(t *testing.T) {
+	server, router, token, user := setupOrdersPaginationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	createTestOrder(t, server.db, user.ID, "pending", now.Add(-3*time.Minute))
+	createTestOrder(t, server.db, user.ID, "completed", now.Add(-2*time.Minute))
+	createTestOrder(t, server.db, user.ID, "cancelled", now.Add(-1*time.Minute))
+
+	resp, page := getOrders(t, router, token, "?status=pending,completed")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, page.Orders, 2)
+	for _, o := range page.Orders {
+		assert.Contains(t, []string{"pending", "completed"}, o.Status)
+	}
+}
+
+func TestGetOrdersHandler_CannotPageIntoAnotherUsersOrders(t *testing.T) {
+	server, router, token, user := setupOrdersPaginationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	otherUser := createTestUser(t, server.db, "otherordersuser", "otherorders@test.com", "Password123!")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	createTestOrder(t, server.db, user.ID, "pending", now.Add(-2*time.Minute))
+	otherOrder := createTestOrder(t, server.db, otherUser.ID, "pending", now.Add(-1*time.Minute))
+
+	// Hand-craft a cursor pointing just after the other user's order - if user scoping were
+	// applied only to the initial filter and not the keyset comparison, this could leak
+	// otherOrder or orders beyond it into this user's page.
+	forgedCursor := encodeOrdersCursor(otherOrder.CreatedAt, otherOrder.ID)
+
+	resp, page := getOrders(t, router, token, "?cursor="+forgedCursor)
+	require.Equal(t, http.StatusOK, resp.Code)
+	for _, o := range page.Orders {
+		assert.Equal(t, user.ID, o.UserID)
+	}
+}
+
+// ==================== GET /admin/orders Tests ====================
+
+func getAdminOrders(t *testing.T, router *gin.Engine, token, query string) (*httptest.ResponseRecorder, ordersPageResponse) {
+	req, _ := http.NewRequest("GET", "/admin/orders"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var page ordersPageResponse
+	if resp.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+	}
+	return resp, page
+}
+
+func TestListAllOrdersHandler_RequiresAdmin(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.GET("/admin/orders", auth.AuthMiddleware(), auth.RequireAnyRole("admin"), server.listAllOrdersHandler)
+
+	user := createTestUser(t, server.db, "notadmin", "notadmin@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	resp, _ := getAdminOrders(t, router, token, "")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestListAllOrdersHandler_ReturnsOrdersAcrossUsers(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.GET("/admin/orders", auth.AuthMiddleware(), auth.RequireAnyRole("admin"), server.listAllOrdersHandler)
+
+	adminUser := createTestUser(t, server.db, "admin", "admin@test.com", "Password123!")
+	adminUser.Role = "admin"
+	require.NoError(t, server.db.Save(adminUser).Error)
+	token, err := auth.GenerateJWT(adminUser.ID, adminUser.Username, adminUser.Email, adminUser.Role)
+	require.NoError(t, err)
+
+	userA := createTestUser(t, server.db, "adminordersa", "adminordersa@test.com", "Password123!")
+	userB := createTestUser(t, server.db, "adminordersb", "adminordersb@test.com", "Password123!")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	createTestOrder(t, server.db, userA.ID, "pending", now.Add(-2*time.Minute))
+	createTestOrder(t, server.db, userB.ID, "pending", now.Add(-1*time.Minute))
+
+	resp, page := getAdminOrders(t, router, token, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, page.Orders, 2)
+
+	resp, page = getAdminOrders(t, router, token, "?user_id="+userA.ID.String())
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, page.Orders, 1)
+	assert.Equal(t, userA.ID, page.Orders[0].UserID)
+}
+
+func TestGetOrdersHandler_SetsNextCursorHeader(t *testing.T) {
+	server, router, token, user := setupOrdersPaginationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	createTestOrder(t, server.db, user.ID, "pending", now.Add(-2*time.Minute))
+	createTestOrder(t, server.db, user.ID, "pending", now.Add(-1*time.Minute))
+
+	resp, page := getOrders(t, router, token, "?limit=1")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, page.NextCursor, resp.Header().Get("X-Next-Cursor"))
+	assert.NotEmpty(t, resp.Header().Get("X-Next-Cursor"))
+}
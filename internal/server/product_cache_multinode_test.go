@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tundra/internal/apierr"
+	"tundra/internal/auth"
+	"tundra/internal/cache"
+	"tundra/internal/database/models"
+	"tundra/internal/mailer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCacheOnlyServer builds a minimal Server sharing db and rdb with whatever other servers
+// the test constructs, but with its own independent Layered cache (and thus its own L1) -
+// simulating a second API replica.
+func newCacheOnlyServer(t *testing.T, server *Server) (*Server, *gin.Engine) {
+	productCache, err := cache.NewLayered(server.redis, 1<<20)
+	require.NoError(t, err)
+
+	replica := &Server{
+		db:            server.db,
+		redis:         server.redis,
+		mailer:        mailer.NewFakeMailer(),
+		productCache:  productCache,
+		productTags:   cache.NewTagIndex(server.redis),
+		cachePolicies: server.cachePolicies,
+	}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(apierr.Middleware())
+	return replica, router
+}
+
+// TestProductListCaching_CrossNodeL1Invalidation asserts that invalidating the product cache
+// on one API replica evicts the corresponding L1 entry on another replica, via the
+// cache:invalidate Redis pub/sub channel - not just the shared L2.
+func TestProductListCaching_CrossNodeL1Invalidation(t *testing.T) {
+	server1, router1 := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	server2, router2 := newCacheOnlyServer(t, server1)
+
+	router1.PUT("/products/:id", auth.AuthMiddleware(), auth.AdminMiddleware(), server1.updateProductHandler)
+	router2.GET("/products", server2.listProductsHandler)
+
+	adminUser := createTestUser(t, server1.db, "admin", "admin@test.com", "Password123!")
+	adminUser.Role = "admin"
+	require.NoError(t, server1.db.Save(adminUser).Error)
+	token, err := auth.GenerateJWT(adminUser.ID, adminUser.Username, adminUser.Email, adminUser.Role)
+	require.NoError(t, err)
+
+	product := models.Product{
+		Name:        "Original Product",
+		Description: "Original Description",
+		Price:       100.0,
+		Stock:       10,
+		Category:    "Original",
+	}
+	require.NoError(t, server1.db.Create(&product).Error)
+
+	// Populate server2's L1 by listing through it.
+	req1, _ := http.NewRequest("GET", "/products", nil)
+	resp1 := httptest.NewRecorder()
+	router2.ServeHTTP(resp1, req1)
+	require.Equal(t, http.StatusOK, resp1.Code)
+
+	assert.Contains(t, resp1.Body.String(), "Original Product")
+
+	// Update the product through server1, which invalidates both cache tiers and
+	// broadcasts the key on cache:invalidate.
+	updateJSON, _ := json.Marshal(map[string]interface{}{"name": "Updated Product"})
+	updateReq, _ := http.NewRequest("PUT", "/products/"+product.ID.String(), bytes.NewBuffer(updateJSON))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateReq.Header.Set("Authorization", "Bearer "+token)
+	updateResp := httptest.NewRecorder()
+	router1.ServeHTTP(updateResp, updateReq)
+	require.Equal(t, http.StatusOK, updateResp.Code)
+
+	// server2 never called invalidateProductCache itself - its only path to a fresh read is
+	// the subscriber evicting its L1 entry in response to server1's broadcast. Poll for
+	// propagation since it's asynchronous.
+	require.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/products", nil)
+		resp := httptest.NewRecorder()
+		router2.ServeHTTP(resp, req)
+		return resp.Code == http.StatusOK && bytes.Contains(resp.Body.Bytes(), []byte("Updated Product"))
+	}, 2*time.Second, 20*time.Millisecond, "server2's L1 should have been evicted by the cache:invalidate broadcast")
+}
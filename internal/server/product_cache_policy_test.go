@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tundra/internal/cache"
+	"tundra/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListProductsHandler_StaleWhileRevalidate asserts that a request landing after a cached
+// page's soft TTL but inside its stale-while-revalidate window gets the stale bytes back
+// immediately, while a background refresh (coalesced through productListGroup) brings the
+// entry back up to date for the next request.
+func TestListProductsHandler_StaleWhileRevalidate(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	router.GET("/products", server.listProductsHandler)
+
+	policy := cache.DefaultProductsListPolicy()
+	policy.TTL = 50 * time.Millisecond
+	policy.StaleWhileRevalidate = 2 * time.Second
+	server.cachePolicies.Set("products:list", policy)
+
+	product := models.Product{
+		Name:        "Original Product",
+		Description: "Description",
+		Price:       10.0,
+		Stock:       5,
+		Category:    "Category",
+	}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	// Populate the cache.
+	req1, _ := http.NewRequest("GET", "/products", nil)
+	resp1 := httptest.NewRecorder()
+	router.ServeHTTP(resp1, req1)
+	require.Equal(t, http.StatusOK, resp1.Code)
+	assert.Contains(t, resp1.Body.String(), "Original Product")
+
+	// Cross the soft TTL (but stay well inside the SWR window), then change the underlying
+	// row directly - simulating a write that didn't go through updateProductHandler's
+	// invalidation, so the only way stale data gets refreshed is this handler's own SWR path.
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, server.db.Model(&product).Update("name", "Refreshed Product").Error)
+
+	// This request should still get the stale cached body...
+	req2, _ := http.NewRequest("GET", "/products", nil)
+	resp2 := httptest.NewRecorder()
+	router.ServeHTTP(resp2, req2)
+	require.Equal(t, http.StatusOK, resp2.Code)
+	assert.Contains(t, resp2.Body.String(), "Original Product")
+
+	// ...while a background refresh brings the cache up to date shortly after.
+	require.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/products", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Code == http.StatusOK && bytes.Contains(resp.Body.Bytes(), []byte("Refreshed Product"))
+	}, 2*time.Second, 20*time.Millisecond, "cache should have been refreshed in the background")
+}
+
+// TestListProductsHandler_PolicyDisabledBypassesRedis asserts that disabling the
+// "products:list" policy at runtime makes the handler skip the cache entirely - no read, no
+// write - even though Redis is otherwise available.
+func TestListProductsHandler_PolicyDisabledBypassesRedis(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	router.GET("/products", server.listProductsHandler)
+
+	policy := cache.DefaultProductsListPolicy()
+	policy.Enabled = false
+	server.cachePolicies.Set("products:list", policy)
+
+	product := models.Product{
+		Name:        "Test Product",
+		Description: "Description",
+		Price:       10.0,
+		Stock:       5,
+		Category:    "Category",
+	}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	req, _ := http.NewRequest("GET", "/products", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get("ETag"))
+
+	ctx := context.Background()
+	cacheKey := "products:page:1:size:10:search::sort:"
+	exists, err := server.redis.Exists(ctx, cacheKey).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), exists, "disabled policy should never touch Redis")
+}
+
+// TestListProductsHandler_VaryByProducesDistinctKeys asserts that the default
+// "products:list" policy's VaryBy (["sort"]) fans requests differing only by ?sort out into
+// separate cache entries.
+func TestListProductsHandler_VaryByProducesDistinctKeys(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	router.GET("/products", server.listProductsHandler)
+
+	product := models.Product{
+		Name:        "Test Product",
+		Description: "Description",
+		Price:       10.0,
+		Stock:       5,
+		Category:    "Category",
+	}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	reqPrice, _ := http.NewRequest("GET", "/products?sort=price", nil)
+	respPrice := httptest.NewRecorder()
+	router.ServeHTTP(respPrice, reqPrice)
+	require.Equal(t, http.StatusOK, respPrice.Code)
+
+	reqName, _ := http.NewRequest("GET", "/products?sort=name", nil)
+	respName := httptest.NewRecorder()
+	router.ServeHTTP(respName, reqName)
+	require.Equal(t, http.StatusOK, respName.Code)
+
+	ctx := context.Background()
+	priceExists, err := server.redis.Exists(ctx, "products:page:1:size:10:search::sort:price").Result()
+	require.NoError(t, err)
+	nameExists, err := server.redis.Exists(ctx, "products:page:1:size:10:search::sort:name").Result()
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), priceExists)
+	assert.Equal(t, int64(1), nameExists)
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tundra/internal/auth"
+	"tundra/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProductListCaching_TagBasedInvalidationIsPrecise populates cache entries for three
+// separate listing pages, updates the product that appears only on the middle page, and
+// asserts that only the page it could have affected was evicted - the other two pages
+// survive in Redis untouched.
+func TestProductListCaching_TagBasedInvalidationIsPrecise(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	router.GET("/products", server.listProductsHandler)
+	router.PUT("/products/:id", auth.AuthMiddleware(), auth.AdminMiddleware(), server.updateProductHandler)
+
+	adminUser := createTestUser(t, server.db, "admin", "admin@test.com", "Password123!")
+	adminUser.Role = "admin"
+	require.NoError(t, server.db.Save(adminUser).Error)
+	token, err := auth.GenerateJWT(adminUser.ID, adminUser.Username, adminUser.Email, adminUser.Role)
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		product := models.Product{
+			Name:        fmt.Sprintf("Product %d", i),
+			Description: fmt.Sprintf("Description %d", i),
+			Price:       float64(i * 10),
+			Stock:       int64(i * 5),
+			Category:    fmt.Sprintf("Category %d", i),
+		}
+		require.NoError(t, server.db.Create(&product).Error)
+	}
+
+	pageKey := func(page int) string {
+		return fmt.Sprintf("products:page:%d:size:%d:search::sort:", page, 1)
+	}
+
+	var page2Product map[string]interface{}
+	for page := 1; page <= 3; page++ {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/products?page=%d&pageSize=1", page), nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		if page == 2 {
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+			products := body["products"].([]interface{})
+			require.Len(t, products, 1)
+			page2Product = products[0].(map[string]interface{})
+		}
+	}
+
+	ctx := context.Background()
+	require.Equal(t, int64(1), server.redis.Exists(ctx, pageKey(1)).Val())
+	require.Equal(t, int64(1), server.redis.Exists(ctx, pageKey(2)).Val())
+	require.Equal(t, int64(1), server.redis.Exists(ctx, pageKey(3)).Val())
+
+	// Update the product that only appears on page 2.
+	updateJSON, _ := json.Marshal(map[string]interface{}{"name": "Updated Product"})
+	updateReq, _ := http.NewRequest("PUT", "/products/"+page2Product["id"].(string), bytes.NewBuffer(updateJSON))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateReq.Header.Set("Authorization", "Bearer "+token)
+	updateResp := httptest.NewRecorder()
+	router.ServeHTTP(updateResp, updateReq)
+	require.Equal(t, http.StatusOK, updateResp.Code)
+
+	assert.Equal(t, int64(1), server.redis.Exists(ctx, pageKey(1)).Val(), "page 1 should be untouched")
+	assert.Equal(t, int64(0), server.redis.Exists(ctx, pageKey(2)).Val(), "page 2 should have been invalidated")
+	assert.Equal(t, int64(1), server.redis.Exists(ctx, pageKey(3)).Val(), "page 3 should be untouched")
+}
+
+// TestProductListCaching_PriceOrStockUpdateInvalidatesAllListingPages asserts that, unlike a
+// plain name edit, changing a product's price or stock evicts every cached listing page: a
+// sort=price_asc/desc or in_stock=true page can reorder or drop rows based on exactly those
+// fields, so precise per-category invalidation isn't enough to keep them consistent.
+func TestProductListCaching_PriceOrStockUpdateInvalidatesAllListingPages(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	router.GET("/products", server.listProductsHandler)
+	router.PUT("/products/:id", auth.AuthMiddleware(), auth.AdminMiddleware(), server.updateProductHandler)
+
+	adminUser := createTestUser(t, server.db, "admin", "admin@test.com", "Password123!")
+	adminUser.Role = "admin"
+	require.NoError(t, server.db.Save(adminUser).Error)
+	token, err := auth.GenerateJWT(adminUser.ID, adminUser.Username, adminUser.Email, adminUser.Role)
+	require.NoError(t, err)
+
+	var page2Product map[string]interface{}
+	for i := 1; i <= 3; i++ {
+		product := models.Product{
+			Name:        fmt.Sprintf("Product %d", i),
+			Description: fmt.Sprintf("Description %d", i),
+			Price:       float64(i * 10),
+			Stock:       int64(i * 5),
+			Category:    fmt.Sprintf("Category %d", i),
+		}
+		require.NoError(t, server.db.Create(&product).Error)
+	}
+
+	pageKey := func(page int) string {
+		return fmt.Sprintf("products:page:%d:size:%d:search::sort:", page, 1)
+	}
+
+	for page := 1; page <= 3; page++ {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/products?page=%d&pageSize=1", page), nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		if page == 2 {
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+			products := body["products"].([]interface{})
+			require.Len(t, products, 1)
+			page2Product = products[0].(map[string]interface{})
+		}
+	}
+
+	ctx := context.Background()
+	require.Equal(t, int64(1), server.redis.Exists(ctx, pageKey(1)).Val())
+	require.Equal(t, int64(1), server.redis.Exists(ctx, pageKey(2)).Val())
+	require.Equal(t, int64(1), server.redis.Exists(ctx, pageKey(3)).Val())
+
+	// Drop the price of the product that only appears on page 2.
+	updateJSON, _ := json.Marshal(map[string]interface{}{"price": 1})
+	updateReq, _ := http.NewRequest("PUT", "/products/"+page2Product["id"].(string), bytes.NewBuffer(updateJSON))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateReq.Header.Set("Authorization", "Bearer "+token)
+	updateResp := httptest.NewRecorder()
+	router.ServeHTTP(updateResp, updateReq)
+	require.Equal(t, http.StatusOK, updateResp.Code)
+
+	assert.Equal(t, int64(0), server.redis.Exists(ctx, pageKey(1)).Val(), "page 1 should have been invalidated: a price change can reorder every listing page")
+	assert.Equal(t, int64(0), server.redis.Exists(ctx, pageKey(2)).Val(), "page 2 should have been invalidated")
+	assert.Equal(t, int64(0), server.redis.Exists(ctx, pageKey(3)).Val(), "page 3 should have been invalidated")
+}
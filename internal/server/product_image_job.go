@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"time"
+
+	"github.com/GRACENOBLE/tundra/internal/audit"
+	cldinary "github.com/GRACENOBLE/tundra/internal/cloudinary"
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+	"github.com/GRACENOBLE/tundra/internal/jobs"
+
+	"github.com/google/uuid"
+)
+
+// productImageJobType selects the handler startProductImageWorkers registers for uploads
+// enqueued by uploadProductImageHandler and createProductHandler.
+const productImageJobType = "product_image_upload"
+
+// productImageJobPayload is the Job.Payload for productImageJobType: the uploaded file has
+// already been written to TempFilePath by saveUploadToTempFile, since a multipart.File isn't
+// itself JSON-serializable and may not outlive the HTTP request.
+type productImageJobPayload struct {
+	ProductID    uuid.UUID `json:"productId"`
+	TempFilePath string    `json:"tempFilePath"`
+	Filename     string    `json:"filename"`
+	OldImageURL  string    `json:"oldImageUrl,omitempty"`
+}
+
+// productImageJobResult is the Job.Result recorded on success, returned verbatim by
+// getJobHandler.
+type productImageJobResult struct {
+	ImageURL string `json:"imageUrl"`
+}
+
+// enqueueProductImageJob buffers file to a temp file and enqueues a productImageJobType job
+// to upload it to Cloudinary and attach it to productID, deleting oldImageURL's Cloudinary
+// asset if one is given. Returns the new job's id, for the caller to hand back to the client
+// as imageJobId/jobId.
+func (s *Server) enqueueProductImageJob(ctx context.Context, productID uuid.UUID, file multipart.File, filename, oldImageURL string) (string, error) {
+	tempPath, err := saveUploadToTempFile(file, filename)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(productImageJobPayload{
+		ProductID:    productID,
+		TempFilePath: tempPath,
+		Filename:     filename,
+		OldImageURL:  oldImageURL,
+	})
+	if err != nil {
+		os.Remove(tempPath)
+		return "", fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	job := &jobs.Job{
+		ID:        uuid.NewString(),
+		Type:      productImageJobType,
+		Payload:   payload,
+		Status:    jobs.StatusPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.jobQueue.Enqueue(ctx, job); err != nil {
+		os.Remove(tempPath)
+		return "", fmt.Errorf("failed to enqueue image job: %w", err)
+	}
+	return job.ID, nil
+}
+
+// startProductImageWorkers starts n goroutines draining s.jobQueue for productImageJobType
+// jobs, following the same ticker/stop-channel-owned-by-Server shape as
+// startIdempotencyKeyCleanup, except a Worker's Run loop takes the place of the ticker.
+// Workers run for the lifetime of the process; there is currently no caller that needs to
+// stop them early (unlike idempotency cleanup, which tests stop to avoid leaking goroutines).
+func (s *Server) startProductImageWorkers(n int) {
+	worker := jobs.NewWorker(s.jobQueue)
+	worker.Register(productImageJobType, s.handleProductImageJob)
+
+	for i := 0; i < n; i++ {
+		go worker.Run(context.Background())
+	}
+}
+
+// saveUploadToTempFile copies an uploaded multipart file to a temp file on disk, so its bytes
+// survive past the end of the HTTP request that enqueues a productImageJobPayload referencing
+// it. The caller owns deleting the file; handleProductImageJob removes it once the job
+// finishes, successfully or not.
+func saveUploadToTempFile(file multipart.File, filename string) (string, error) {
+	dst, err := os.CreateTemp("", "product-image-*-"+filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, file); err != nil {
+		os.Remove(dst.Name())
+		return "", fmt.Errorf("failed to buffer upload: %w", err)
+	}
+	return dst.Name(), nil
+}
+
+// handleProductImageJob is the jobs.HandlerFunc for productImageJobType: it uploads the
+// buffered file to Cloudinary, deletes the product's previous image (if any), updates
+// Product.ImageURL, and invalidates the product listing cache - the same steps
+// uploadProductImageHandler used to run inline before image processing moved to a
+// background job.
+func (s *Server) handleProductImageJob(ctx context.Context, job *jobs.Job) (json.RawMessage, error) {
+	var payload productImageJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid job payload: %w", err)
+	}
+	defer os.Remove(payload.TempFilePath)
+
+	file, err := os.Open(payload.TempFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open buffered upload: %w", err)
+	}
+	defer file.Close()
+
+	imageURL, err := s.cloudinary.UploadImage(ctx, file, payload.Filename, "products")
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
+
+	if payload.OldImageURL != "" {
+		if publicID := cldinary.ExtractPublicID(payload.OldImageURL); publicID != "" {
+			_ = s.cloudinary.DeleteImage(ctx, publicID)
+		}
+	}
+
+	if err := s.db.Model(&models.Product{}).Where("id = ?", payload.ProductID).Update("image_url", imageURL).Error; err != nil {
+		return nil, fmt.Errorf("failed to update product with image URL: %w", err)
+	}
+
+	s.invalidateProductCache()
+
+	audit.Emit(ctx, audit.Event{
+		Type:     audit.EventAdminAction,
+		Metadata: map[string]any{"action": "product_image_uploaded", "product_id": payload.ProductID, "job_id": job.ID},
+	})
+
+	result, err := json.Marshal(productImageJobResult{ImageURL: imageURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job result: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tundra/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListProductsHandler_CategoryAndPriceFilters asserts that category/min_price/max_price/
+// in_stock narrow the offset-paginated listing the same way search already does.
+func TestListProductsHandler_CategoryAndPriceFilters(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.GET("/products", server.listProductsHandler)
+
+	products := []models.Product{
+		{Name: "Cheap Widget", Description: "d", Price: 5.0, Stock: 10, Category: "Widgets"},
+		{Name: "Pricey Widget", Description: "d", Price: 500.0, Stock: 0, Category: "Widgets"},
+		{Name: "Gadget", Description: "d", Price: 50.0, Stock: 3, Category: "Gadgets"},
+	}
+	for _, p := range products {
+		require.NoError(t, server.db.Create(&p).Error)
+	}
+
+	req, _ := http.NewRequest("GET", "/products?category=Widgets&min_price=1&max_price=100&in_stock=true", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+
+	assert.EqualValues(t, 1, response["totalProducts"])
+	list, ok := response["products"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, list, 1)
+	product := list[0].(map[string]interface{})
+	assert.Equal(t, "Cheap Widget", product["name"])
+}
+
+// TestListProductsHandler_CursorPagination asserts that passing ?cursor=... switches to
+// keyset pagination and that the returned nextCursor walks through every row exactly once.
+func TestListProductsHandler_CursorPagination(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.GET("/products", server.listProductsHandler)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, server.db.Create(&models.Product{
+			Name:        "Product",
+			Description: "d",
+			Price:       float64(i),
+			Stock:       1,
+			Category:    "Cat",
+		}).Error)
+	}
+
+	seen := make(map[string]bool)
+	cursor := ""
+	for page := 0; page < 10; page++ {
+		url := "/products?pageSize=2"
+		if cursor != "" {
+			url += "&cursor=" + cursor
+		}
+		req, _ := http.NewRequest("GET", url, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+
+		list, ok := response["products"].([]interface{})
+		require.True(t, ok)
+		for _, item := range list {
+			id := item.(map[string]interface{})["id"].(string)
+			assert.False(t, seen[id], "cursor pagination returned %s twice", id)
+			seen[id] = true
+		}
+
+		next, _ := response["nextCursor"].(string)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	assert.Len(t, seen, 5)
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"tundra/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestListProductsHandler_SingleflightCoalescesCacheStampede fires a burst of concurrent
+// requests against an empty cache and asserts they share a single database round trip
+// instead of each running their own COUNT + SELECT.
+func TestListProductsHandler_SingleflightCoalescesCacheStampede(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	router.GET("/products", server.listProductsHandler)
+
+	product := models.Product{
+		Name:        "Stampede Product",
+		Description: "Description",
+		Price:       10.0,
+		Stock:       5,
+		Category:    "Category",
+	}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	var queryCount atomic.Int64
+	require.NoError(t, server.db.Callback().Query().Before("gorm:query").Register("test:count_queries", func(tx *gorm.DB) {
+		queryCount.Add(1)
+	}))
+	defer server.db.Callback().Query().Remove("test:count_queries")
+
+	const concurrency = 50
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	statuses := make([]int, concurrency)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			req, _ := http.NewRequest("GET", "/products", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			statuses[i] = resp.Code
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for _, status := range statuses {
+		assert.Equal(t, http.StatusOK, status)
+	}
+
+	// The handler's singleflight closure runs one COUNT and one SELECT against the products
+	// table; both pass through this callback, so a single coalesced execution produces
+	// exactly 2 - not 2*concurrency.
+	assert.Equal(t, int64(2), queryCount.Load())
+
+	assert.Greater(t, server.SingleflightSharedTotal(), uint64(0))
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/GRACENOBLE/tundra/internal/apierr"
+	"github.com/GRACENOBLE/tundra/internal/audit"
+	"github.com/GRACENOBLE/tundra/internal/auth"
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// currentUser loads the user AuthMiddleware authenticated this request as. On failure it
+// attaches the appropriate apierr response itself and returns ok=false, so callers can
+// just return.
+func (s *Server) currentUser(c *gin.Context) (*models.User, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Error(apierr.Unauthorized("authentication_required", "User authentication required"))
+		return nil, false
+	}
+
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.Error(apierr.Internal("invalid_user_id", "Invalid user ID"))
+		return nil, false
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", userUUID).First(&user).Error; err != nil {
+		c.Error(apierr.NotFound("user_not_found", "User not found"))
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// @Summary Get the current user's profile
+// @Description Fetch the authenticated user's own profile.
+// @Tags Profile
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} object{id=string,username=string,email=string,role=string,email_verified=bool,two_factor_enabled=bool} "Current user"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "Unauthorized"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "User not found"
+// @Router /me [get]
+func (s *Server) getProfileHandler(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                 user.ID,
+		"username":           user.Username,
+		"email":              user.Email,
+		"role":               user.Role,
+		"email_verified":     user.EmailVerified,
+		"two_factor_enabled": user.TwoFactorEnabled,
+	})
+}
+
+// @Summary Update the current user's username/email
+// @Description Change the authenticated user's own username and/or email; either field may be omitted to leave it unchanged.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body object{username=string,email=string} true "Profile Update Request"
+// @Success 200 {object} object{id=string,username=string,email=string,role=string} "Updated profile"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Validation error"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "Unauthorized"
+// @Failure 409 {object} object{error=object{code=string,message=string,request_id=string}} "Username or email already in use"
+// @Router /me [put]
+func (s *Server) updateProfileHandler(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Email    string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierr.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	if req.Username != "" && req.Username != user.Username {
+		if err := auth.ValidateUsername(req.Username); err != nil {
+			c.Error(apierr.BadRequest("invalid_username", err.Error()))
+			return
+		}
+
+		var existing models.User
+		if err := s.db.Where("username = ? AND id <> ?", req.Username, user.ID).First(&existing).Error; err == nil {
+			c.Error(apierr.Conflict("username_taken", "Username is already taken"))
+			return
+		}
+		user.Username = req.Username
+	}
+
+	if req.Email != "" && req.Email != user.Email {
+		if err := auth.ValidateEmail(req.Email); err != nil {
+			c.Error(apierr.BadRequest("invalid_email", err.Error()))
+			return
+		}
+
+		var existing models.User
+		if err := s.db.Where("email = ? AND id <> ?", req.Email, user.ID).First(&existing).Error; err == nil {
+			c.Error(apierr.Conflict("email_taken", "Email is already registered"))
+			return
+		}
+		user.Email = req.Email
+	}
+
+	if err := s.db.Save(user).Error; err != nil {
+		c.Error(apierr.Internal("profile_update_failed", "Failed to update profile"))
+		return
+	}
+
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventAdminAction,
+		UserID:    &user.ID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"action": "profile_update"},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":       user.ID,
+		"username": user.Username,
+		"email":    user.Email,
+		"role":     user.Role,
+	})
+}
+
+// @Summary Change the current user's password
+// @Description Change the authenticated user's own password, verifying the current password first.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body object{current_password=string,new_password=string} true "Password Change Request"
+// @Success 200 {object} object{message=string} "Password updated"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Validation error"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "Unauthorized, or current password incorrect"
+// @Router /me/password [put]
+func (s *Server) updateProfilePasswordHandler(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierr.BadRequest("invalid_request", "Current and new password are required"))
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		c.Error(apierr.Unauthorized("invalid_credentials", "Current password is incorrect"))
+		return
+	}
+
+	if err := auth.ValidatePassword(req.NewPassword); err != nil {
+		c.Error(apierr.BadRequest("invalid_password", err.Error()))
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.Error(apierr.Internal("password_update_failed", "Failed to update password"))
+		return
+	}
+	user.Password = string(hashedPassword)
+
+	if err := s.db.Save(user).Error; err != nil {
+		c.Error(apierr.Internal("password_update_failed", "Failed to update password"))
+		return
+	}
+
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventPasswordChanged,
+		UserID:    &user.ID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
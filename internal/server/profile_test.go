@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tundra/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetProfileHandler(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.GET("/me", auth.AuthMiddleware(), server.getProfileHandler)
+
+	user := createTestUser(t, server.db, "profileuser", "profile@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Username string `json:"username"`
+		Email    string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, "profileuser", body.Username)
+	assert.Equal(t, "profile@test.com", body.Email)
+}
+
+func TestGetProfileHandler_RequiresAuth(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.GET("/me", auth.AuthMiddleware(), server.getProfileHandler)
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestUpdateProfileHandler(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.PUT("/me", auth.AuthMiddleware(), server.updateProfileHandler)
+
+	user := createTestUser(t, server.db, "origuser", "orig@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	reqBody, _ := json.Marshal(map[string]string{"username": "renameduser"})
+	req, _ := http.NewRequest("PUT", "/me", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		Username string `json:"username"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, "renameduser", body.Username)
+}
+
+func TestUpdateProfileHandler_RejectsTakenUsername(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.PUT("/me", auth.AuthMiddleware(), server.updateProfileHandler)
+
+	createTestUser(t, server.db, "taken", "taken@test.com", "Password123!")
+	user := createTestUser(t, server.db, "me", "me@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	reqBody, _ := json.Marshal(map[string]string{"username": "taken"})
+	req, _ := http.NewRequest("PUT", "/me", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestUpdateProfilePasswordHandler(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.PUT("/me/password", auth.AuthMiddleware(), server.updateProfilePasswordHandler)
+
+	user := createTestUser(t, server.db, "pwuser", "pwuser@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	reqBody, _ := json.Marshal(map[string]string{
+		"current_password": "Password123!",
+		"new_password":     "NewPassword456!",
+	})
+	req, _ := http.NewRequest("PUT", "/me/password", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestUpdateProfilePasswordHandler_RejectsWrongCurrentPassword(t *testing.T) {
+	server, router := setupTestServer(t)
+	defer cleanupTestDatabase(t)
+
+	router.PUT("/me/password", auth.AuthMiddleware(), server.updateProfilePasswordHandler)
+
+	user := createTestUser(t, server.db, "pwuser2", "pwuser2@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	reqBody, _ := json.Marshal(map[string]string{
+		"current_password": "WrongPassword!",
+		"new_password":     "NewPassword456!",
+	})
+	req, _ := http.NewRequest("PUT", "/me/password", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
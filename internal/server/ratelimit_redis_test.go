@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tundra/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAPILimiter_RedisBacked asserts that once ratelimit.SetRedisClient is wired (as
+// NewServer does whenever Redis is available), APILimiter enforces its limit through Redis
+// rather than per-process memory, and sets the headers a client needs to back off.
+func TestAPILimiter_RedisBacked(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	ratelimit.SetRedisClient(server.redis)
+	t.Cleanup(func() { ratelimit.SetRedisClient(nil) })
+
+	router.Use(ratelimit.NewRateLimiterWithStore(server.redis, "3-M"))
+	router.GET("/limited", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	var last *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/limited", nil)
+		req.RemoteAddr = "198.51.100.7:1234"
+		last = httptest.NewRecorder()
+		router.ServeHTTP(last, req)
+		assert.Equal(t, http.StatusOK, last.Code, "request %d should be within the limit", i+1)
+	}
+	assert.Equal(t, "3", last.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", last.Header().Get("X-RateLimit-Remaining"))
+
+	req := httptest.NewRequest("GET", "/limited", nil)
+	req.RemoteAddr = "198.51.100.7:1234"
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+	assert.NotEmpty(t, resp.Header().Get("X-RateLimit-Reset"))
+
+	// A different client IP is tracked independently.
+	reqOther := httptest.NewRequest("GET", "/limited", nil)
+	reqOther.RemoteAddr = "198.51.100.8:1234"
+	respOther := httptest.NewRecorder()
+	router.ServeHTTP(respOther, reqOther)
+	assert.Equal(t, http.StatusOK, respOther.Code)
+}
@@ -2,16 +2,29 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/GRACENOBLE/tundra/internal/apierr"
+	"github.com/GRACENOBLE/tundra/internal/audit"
 	"github.com/GRACENOBLE/tundra/internal/auth"
+	"github.com/GRACENOBLE/tundra/internal/cache"
 	cldinary "github.com/GRACENOBLE/tundra/internal/cloudinary"
 	"github.com/GRACENOBLE/tundra/internal/database/models"
+	"github.com/GRACENOBLE/tundra/internal/frontend"
+	"github.com/GRACENOBLE/tundra/internal/middleware/accesslog"
+	"github.com/GRACENOBLE/tundra/internal/orders"
+	"github.com/GRACENOBLE/tundra/internal/outbox"
 	"github.com/GRACENOBLE/tundra/internal/ratelimit"
+	"github.com/GRACENOBLE/tundra/internal/tracing"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -19,51 +32,126 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 func (s *Server) RegisterRoutes() http.Handler {
 	r := gin.Default()
 
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type"},
-		AllowCredentials: true,
-	}))
+	// Root span for the whole request - everything else (GORM queries, Cloudinary uploads,
+	// rate-limiter rejections) attaches its spans under this one.
+	r.Use(tracing.Middleware())
+
+	// One access-log line per request, correlated with any audit.Event emitted while
+	// handling it via the X-Request-ID stashed in the request's context.Context.
+	r.Use(accesslog.New(accesslog.WithFormat(accesslog.FormatJSON)))
+
+	// When the frontend is embedded in this same binary (EMBED_FRONTEND=true), requests to
+	// it are same-origin, so the dev CORS origin below would otherwise needlessly reject
+	// bona fide same-origin requests not sent with fetch's default mode.
+	if !frontend.Enabled() {
+		r.Use(cors.New(cors.Config{
+			AllowOrigins:     []string{"http://localhost:5173"},
+			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+			AllowHeaders:     []string{"Accept", "Authorization", "Content-Type"},
+			AllowCredentials: true,
+		}))
+	}
 
 	// Apply global rate limiter to all routes
 	r.Use(ratelimit.GlobalLimiter())
 
+	// Serializes any apierr.APIError a handler attached with c.Error into a consistent
+	// {"error": {code, message, details, request_id}} body. Must run after every handler,
+	// so it's registered last among global middleware.
+	r.Use(apierr.Middleware())
+
 	// Swagger documentation endpoint
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Publishes the public half of every active RS256/ES256 signing key so verifiers
+	// outside this process can validate access tokens; a no-op JWK set under HS256.
+	r.GET("/.well-known/jwks.json", auth.JWKSHandler())
+
 	// Authentication routes with strict rate limiting to prevent brute force attacks
 	authRoutes := r.Group("/auth")
 	authRoutes.Use(ratelimit.AuthLimiter()) // 5 requests per minute per IP
 	{
 		authRoutes.POST("/register", s.signUpHandler)
-		authRoutes.POST("/login", s.loginHandler)
+		authRoutes.POST("/login", auth.LoginRateLimiter(s.redis, auth.DefaultLoginRateLimitConfig()), s.loginHandler)
+		authRoutes.POST("/refresh", s.refreshHandler)
+		authRoutes.POST("/logout", s.logoutHandler)
+		authRoutes.POST("/2fa/challenge", s.twoFactorChallengeHandler)
+		authRoutes.GET("/verify", s.verifyEmailHandler)
+		authRoutes.POST("/forgot-password", s.forgotPasswordHandler)
+		authRoutes.POST("/reset-password", s.resetPasswordHandler)
+		authRoutes.GET("/:provider/login", s.providerLoginHandler)
+		authRoutes.GET("/:provider/callback", s.providerCallbackHandler)
+		authRoutes.POST("/approle/login", s.approleLoginHandler)
+		authRoutes.POST("/logout-all", auth.AuthMiddleware(), s.logoutAllHandler)
+		authRoutes.GET("/sessions", auth.AuthMiddleware(), s.listSessionsHandler)
+		authRoutes.DELETE("/sessions/:id", auth.AuthMiddleware(), s.revokeSessionHandler)
+	}
+
+	// 2FA enrollment/management routes require a fully authenticated session
+	twoFactorRoutes := r.Group("/auth/2fa")
+	twoFactorRoutes.Use(ratelimit.AuthLimiter())
+	twoFactorRoutes.Use(auth.AuthMiddleware())
+	{
+		twoFactorRoutes.POST("/setup", s.twoFactorSetupHandler)
+		twoFactorRoutes.POST("/verify", s.twoFactorVerifyHandler)
+		twoFactorRoutes.POST("/disable", s.twoFactorDisableHandler)
 	}
 
-	// Public product routes with API rate limiting
-	productPublic := r.Group("/products")
-	productPublic.Use(ratelimit.APILimiter()) // 100 requests per minute per IP
+	// The authenticated user's own profile - the login handler already has this same
+	// user row, but until now there was no way for a logged-in user to fetch or update it.
+	meRoutes := r.Group("/me")
+	meRoutes.Use(ratelimit.APILimiter())
+	meRoutes.Use(auth.AuthMiddleware())
 	{
-		productPublic.GET("", s.listProductsHandler)
-		productPublic.GET("/:id", s.getProductHandler)
+		meRoutes.GET("", s.getProfileHandler)
+		meRoutes.PUT("", s.updateProfileHandler)
+		meRoutes.PUT("/password", s.updateProfilePasswordHandler)
+	}
+
+	// Product routes are scoped to a tenant domain: DomainMiddleware (which must run
+	// after AuthMiddleware) resolves :domainID into the caller's domain membership and
+	// rejects non-members with 403, so even listing a domain's products requires it.
+	domainProducts := r.Group("/domains/:domainID/products")
+	domainProducts.Use(ratelimit.APILimiter()) // Rate limiting
+	domainProducts.Use(auth.AuthMiddleware())  // Require authentication
+	domainProducts.Use(auth.DomainMiddleware())
+	{
+		domainProducts.GET("", s.listProductsHandler)
+		domainProducts.GET("/:id", s.getProductHandler)
 	}
 
-	// Protected product routes (require authentication and admin role)
-	productsAdmin := r.Group("/products")
-	productsAdmin.Use(ratelimit.APILimiter()) // Rate limiting
-	productsAdmin.Use(auth.AuthMiddleware())  // Require authentication
-	productsAdmin.Use(auth.AdminMiddleware()) // Require admin role
+	// Mutating product routes additionally require the "admin" domain role - the
+	// domain-scoped replacement for auth.AdminMiddleware on these routes.
+	domainProductsAdmin := r.Group("/domains/:domainID/products")
+	domainProductsAdmin.Use(ratelimit.APILimiter())
+	domainProductsAdmin.Use(auth.AuthMiddleware())
+	domainProductsAdmin.Use(auth.DomainMiddleware())
+	domainProductsAdmin.Use(auth.DomainRoleMiddleware("admin"))
+	{
+		domainProductsAdmin.POST("", s.createProductHandler)
+		domainProductsAdmin.PUT("/:id", s.updateProductHandler)
+		domainProductsAdmin.DELETE("/:id", s.deleteProductHandler)
+		domainProductsAdmin.POST("/:id/image", s.uploadProductImageHandler)
+	}
+
+	// Resumable, chunked image uploads (for clients on flaky networks that can't reliably
+	// push a whole product photo in one request). Gated behind the same permission as the
+	// single-request upload endpoint above.
+	uploadsAdmin := r.Group("/uploads")
+	uploadsAdmin.Use(ratelimit.APILimiter())
+	uploadsAdmin.Use(auth.AuthMiddleware())
+	uploadsAdmin.Use(auth.RequirePermission("products:write"))
 	{
-		productsAdmin.POST("", s.createProductHandler)
-		productsAdmin.PUT("/:id", s.updateProductHandler)
-		productsAdmin.DELETE("/:id", s.deleteProductHandler)
-		productsAdmin.POST("/:id/image", s.uploadProductImageHandler)
+		uploadsAdmin.POST("", s.initUploadHandler)
+		uploadsAdmin.PATCH("/:id", s.uploadChunkHandler)
+		uploadsAdmin.PUT("/:id", s.finalizeUploadHandler)
 	}
 
 	// Protected order routes (require authentication, regular users can access)
@@ -71,25 +159,97 @@ func (s *Server) RegisterRoutes() http.Handler {
 	orders.Use(ratelimit.APILimiter()) // Rate limiting
 	orders.Use(auth.AuthMiddleware())  // Require authentication
 	{
-		orders.POST("", s.createOrderHandler)
+		// idempotencyMiddleware lets a client retry a create-order request (same
+		// Idempotency-Key header + body) without double-charging inventory.
+		orders.POST("", s.idempotencyMiddleware(), s.createOrderHandler)
 		orders.GET("", s.getOrdersHandler)
+		orders.POST("/:id/cancel", s.cancelOrderHandler)
+		// Also guarded by idempotencyMiddleware: retrying a checkout request must not create
+		// a second payment intent with the provider for the same order.
+		orders.POST("/:id/checkout", s.idempotencyMiddleware(), s.checkoutOrderHandler)
+		orders.GET("/:id/reservation", s.getOrderReservationHandler)
+		orders.PATCH("/:id/status", auth.RequireAnyRole("admin"), s.updateOrderStatusHandler)
+	}
+
+	// The payment provider's webhook, authenticated by its own signature header rather
+	// than a bearer token - it's called by Stripe, not a logged-in user.
+	r.POST("/orders/webhook", s.orderWebhookHandler)
+
+	// Background job status, e.g. the productImageJobType jobs uploadProductImageHandler and
+	// createProductHandler enqueue. Job ids are unguessable UUIDs handed only to the caller
+	// who enqueued them, so any authenticated user may poll one.
+	jobRoutes := r.Group("/jobs")
+	jobRoutes.Use(ratelimit.APILimiter())
+	jobRoutes.Use(auth.AuthMiddleware())
+	{
+		jobRoutes.GET("/:id", s.getJobHandler)
+	}
+
+	// Admin-only job queue inspection, for diagnosing stuck or dead-lettered background work.
+	adminJobs := r.Group("/admin/jobs")
+	adminJobs.Use(ratelimit.APILimiter())
+	adminJobs.Use(auth.AuthMiddleware())
+	adminJobs.Use(auth.RequireAnyRole("admin"))
+	{
+		adminJobs.GET("", s.listJobsHandler)
+		adminJobs.GET("/dead-letter", s.listDeadLetterJobsHandler)
+	}
+
+	// Admin-only user/role management
+	adminUsers := r.Group("/admin/users")
+	adminUsers.Use(ratelimit.APILimiter())
+	adminUsers.Use(auth.AuthMiddleware())
+	adminUsers.Use(auth.RequireAnyRole("admin"))
+	{
+		adminUsers.POST("/:id/roles", s.grantRoleHandler)
+		adminUsers.DELETE("/:id/roles/:role", s.revokeRoleHandler)
+	}
+
+	// Admin-only audit log access
+	adminAudit := r.Group("/admin/audit")
+	adminAudit.Use(ratelimit.APILimiter())
+	adminAudit.Use(auth.AuthMiddleware())
+	adminAudit.Use(auth.RequireAnyRole("admin"))
+	{
+		adminAudit.GET("", s.listAuditEventsHandler)
+	}
+
+	// Admin-only order listing across every user, the same keyset pagination/filtering
+	// getOrdersHandler offers a user for their own orders, plus ?user_id= to narrow to one.
+	adminOrders := r.Group("/admin/orders")
+	adminOrders.Use(ratelimit.APILimiter())
+	adminOrders.Use(auth.AuthMiddleware())
+	adminOrders.Use(auth.RequireAnyRole("admin"))
+	{
+		adminOrders.GET("", s.listAllOrdersHandler)
+	}
+
+	// Admin-only AppRole management (machine-to-machine credentials)
+	adminAppRoles := r.Group("/admin/approles")
+	adminAppRoles.Use(ratelimit.APILimiter())
+	adminAppRoles.Use(auth.AuthMiddleware())
+	adminAppRoles.Use(auth.RequireAnyRole("admin"))
+	{
+		adminAppRoles.POST("/:roleID/rotate-secret", s.rotateAppRoleSecretHandler)
+	}
+
+	// Serve the embedded SPA for any path none of the routes above claimed, so the API and
+	// its frontend ship as one binary with nothing else to deploy. Registered last: NoRoute
+	// only sees requests that fell through every route group registered above it.
+	if frontend.Enabled() {
+		frontend.Register(r)
 	}
 
 	return r
 }
 
-// invalidateProductCache clears all product listing cache entries
+// invalidateProductCache clears every product listing cache entry from both cache tiers and
+// broadcasts the evictions so every other API replica's L1 stays coherent too.
 func (s *Server) invalidateProductCache() {
-	if s.redis == nil {
+	if s.productCache == nil {
 		return
 	}
-
-	ctx := context.Background()
-	// Delete all keys matching the pattern "products:*"
-	iter := s.redis.Scan(ctx, 0, "products:*", 0).Iterator()
-	for iter.Next(ctx) {
-		s.redis.Del(ctx, iter.Val())
-	}
+	s.productCache.InvalidatePrefix(context.Background(), "products:")
 }
 
 // @Summary Register a new user
@@ -99,9 +259,9 @@ func (s *Server) invalidateProductCache() {
 // @Produce json
 // @Param request body object{username=string,email=string,password=string} true "Signup Request" example({"username":"john123","email":"john@example.com","password":"Password123!"})
 // @Success 201 {object} object{message=string,user=object{id=string,username=string,email=string,role=string}} "User registered successfully"
-// @Failure 400 {object} object{error=string} "Validation error"
-// @Failure 409 {object} object{error=string} "Username or email already exists"
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Validation error"
+// @Failure 409 {object} object{error=object{code=string,message=string,request_id=string}} "Username or email already exists"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Internal server error"
 // @Router /auth/register [post]
 func (s *Server) signUpHandler(c *gin.Context) {
 	// Sign up request struct
@@ -113,61 +273,79 @@ func (s *Server) signUpHandler(c *gin.Context) {
 
 	// Parse the request body
 	if err := c.ShouldBindJSON(&signUpRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "All fields are required"})
+		c.Error(apierr.BadRequest("invalid_request", "All fields are required"))
 		return
 	}
 
 	// Validate username
 	if err := auth.ValidateUsername(signUpRequest.Username); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierr.BadRequest("invalid_username", err.Error()))
 		return
 	}
 
 	// Validate email format
 	if err := auth.ValidateEmail(signUpRequest.Email); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierr.BadRequest("invalid_email", err.Error()))
 		return
 	}
 
 	// Validate password strength
 	if err := auth.ValidatePassword(signUpRequest.Password); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierr.BadRequest("invalid_password", err.Error()))
 		return
 	}
 
 	// Check if username already exists
 	var existingUser models.User
 	if err := s.db.Where("username = ?", signUpRequest.Username).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username is already taken"})
+		c.Error(apierr.Conflict("username_taken", "Username is already taken"))
 		return
 	}
 
 	// Check if email already exists
 	if err := s.db.Where("email = ?", signUpRequest.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is already registered"})
+		c.Error(apierr.Conflict("email_taken", "Email is already registered"))
 		return
 	}
 
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(signUpRequest.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process registration"})
+		c.Error(apierr.Internal("registration_failed", "Failed to process registration"))
+		return
+	}
+
+	// Generate the email verification token up front so its hash can be saved alongside the
+	// user in a single insert.
+	verificationToken, verificationHash, err := auth.GenerateSecureToken()
+	if err != nil {
+		c.Error(apierr.Internal("registration_failed", "Failed to process registration"))
 		return
 	}
 
 	// Create the user object
 	user := models.User{
-		Username: signUpRequest.Username,
-		Email:    signUpRequest.Email,
-		Password: string(hashedPassword),
+		Username:              signUpRequest.Username,
+		Email:                 signUpRequest.Email,
+		Password:              string(hashedPassword),
+		VerificationTokenHash: verificationHash,
 	}
 
 	// Save the user to the database
 	if err := s.db.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user account"})
+		c.Error(apierr.Internal("user_creation_failed", "Failed to create user account"))
 		return
 	}
 
+	s.sendVerificationEmail(user.Email, verificationToken)
+
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventSignUp,
+		UserID:    &user.ID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
 	// Return success response (without password)
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "User registered successfully",
@@ -186,8 +364,8 @@ func (s *Server) signUpHandler(c *gin.Context) {
 // @Produce json
 // @Param request body object{email=string,password=string} true "Login Request" example({"email":"john@example.com","password":"Password123!"})
 // @Success 200 {object} object{message=string,token=string,user=object{id=string,username=string,email=string,role=string}} "Login successful"
-// @Failure 400 {object} object{error=string} "Validation error"
-// @Failure 401 {object} object{error=string} "Invalid credentials"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Validation error"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "Invalid credentials"
 // @Router /auth/login [post]
 func (s *Server) loginHandler(c *gin.Context) {
 	// Login request struct
@@ -198,41 +376,95 @@ func (s *Server) loginHandler(c *gin.Context) {
 
 	// Parse the request body
 	if err := c.ShouldBindJSON(&loginRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email and password are required"})
+		c.Error(apierr.BadRequest("invalid_request", "Email and password are required"))
 		return
 	}
 
 	// Validate email format
 	if err := auth.ValidateEmail(loginRequest.Email); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		c.Error(apierr.BadRequest("invalid_email", "Invalid email format"))
 		return
 	}
 
 	// Find user by email
 	var user models.User
 	if err := s.db.Where("email = ?", loginRequest.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		audit.Emit(c.Request.Context(), audit.Event{
+			Type:      audit.EventLoginFailed,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Metadata:  map[string]any{"reason": "unknown_email", "email": loginRequest.Email},
+		})
+		c.Error(apierr.Unauthorized("invalid_credentials", "Invalid credentials"))
 		return
 	}
 
 	// Compare password with hashed password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginRequest.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		audit.Emit(c.Request.Context(), audit.Event{
+			Type:      audit.EventLoginFailed,
+			UserID:    &user.ID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Metadata:  map[string]any{"reason": "wrong_password"},
+		})
+		c.Error(apierr.Unauthorized("invalid_credentials", "Invalid credentials"))
+		return
+	}
+
+	// Gated by EMAIL_VERIFICATION_REQUIRED so existing deployments that haven't set up a
+	// mailer can leave verification optional.
+	if emailVerificationRequired() && !user.EmailVerified {
+		c.Error(apierr.Forbidden("email_not_verified", "Please verify your email address before logging in"))
+		return
+	}
+
+	// When 2FA is enabled, password auth alone is not enough: hand back a short-lived
+	// mfa_pending token and require POST /auth/2fa/challenge before issuing a real session
+	if user.TwoFactorEnabled {
+		mfaToken, err := auth.GenerateMFAPendingToken(user.ID, user.Username, user.Email, user.Role)
+		if err != nil {
+			c.Error(apierr.Internal("mfa_challenge_failed", "Failed to start two-factor challenge"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"mfaRequired": true,
+			"mfaToken":    mfaToken,
+		})
+		return
+	}
+
+	// Issue a rotatable refresh token so the client can obtain new access tokens
+	// without re-authenticating until the refresh token itself expires or is revoked
+	refreshToken, sessionID, err := s.refreshTokens.Issue(user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		fmt.Printf("Refresh Token Generation Error: %v\n", err)
+		c.Error(apierr.Internal("refresh_token_failed", "Failed to generate refresh token"))
 		return
 	}
 
-	// Generate JWT for the authenticated user
-	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	// Generate JWT for the authenticated user, tagged with this session so it can be
+	// individually revoked later via DELETE /auth/sessions/:id
+	token, err := auth.GenerateJWTWithSession(user.ID, user.Username, user.Email, user.Role, sessionID)
 	if err != nil {
 		fmt.Printf("JWT Generation Error: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate authentication token"})
+		c.Error(apierr.Internal("token_generation_failed", "Failed to generate authentication token"))
 		return
 	}
 
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventLoginSucceeded,
+		UserID:    &user.ID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
 	// Successful login response with JWT
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
+		"message":      "Login successful",
+		"token":        token,
+		"refreshToken": refreshToken,
 		"user": gin.H{
 			"id":       user.ID,
 			"username": user.Username,
@@ -242,511 +474,1801 @@ func (s *Server) loginHandler(c *gin.Context) {
 	})
 }
 
-// @Summary Create a new product
-// @Description Create a new product in the catalog (Admin only). Supports optional image upload via multipart/form-data.
-// @Tags Products
-// @Accept multipart/form-data
+// @Summary Refresh an access token
+// @Description Exchange a valid, unused refresh token for a new access/refresh token pair. The presented refresh token is revoked and rotated; reusing an already-rotated token revokes the entire session family and requires a fresh login.
+// @Tags Authentication
+// @Accept json
 // @Produce json
-// @Security Bearer
-// @Param name formData string true "Product name"
-// @Param description formData string true "Product description"
-// @Param price formData number true "Product price (must be positive)"
-// @Param stock formData integer true "Product stock (must be non-negative)"
-// @Param category formData string true "Product category"
-// @Param image formData file false "Product image (jpg, jpeg, png, gif, webp)"
-// @Success 201 {object} object{message=string,product=models.Product} "Product created successfully"
-// @Failure 400 {object} object{error=string} "Validation error or invalid image format"
-// @Failure 401 {object} object{error=string} "Unauthorized"
-// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
-// @Failure 500 {object} object{error=string} "Internal server error or image upload failed"
-// @Router /products [post]
-func (s *Server) createProductHandler(c *gin.Context) {
-	// Parse multipart form
-	if err := c.Request.ParseMultipartForm(10 << 20); err != nil { // 10 MB max
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form data"})
+// @Param request body object{refreshToken=string} true "Refresh Request"
+// @Success 200 {object} object{token=string,refreshToken=string} "New access/refresh token pair"
+// @Failure 400 {object} object{error=string} "Missing refresh token"
+// @Failure 401 {object} object{error=string} "Refresh token invalid, expired, reused, or revoked"
+// @Router /auth/refresh [post]
+func (s *Server) refreshHandler(c *gin.Context) {
+	var refreshRequest struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&refreshRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refreshToken is required"})
 		return
 	}
 
-	// Get form values
-	name := c.PostForm("name")
-	description := c.PostForm("description")
-	priceStr := c.PostForm("price")
-	stockStr := c.PostForm("stock")
-	category := c.PostForm("category")
-
-	// Validate required fields
-	if name == "" || description == "" || priceStr == "" || stockStr == "" || category == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "All fields (name, description, price, stock, category) are required"})
+	newRefreshToken, sessionID, userID, err := s.refreshTokens.Rotate(refreshRequest.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Parse and validate price
-	price, err := strconv.ParseFloat(priceStr, 64)
-	if err != nil || price <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Price must be a positive number"})
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User no longer exists"})
 		return
 	}
 
-	// Parse and validate stock
-	stock, err := strconv.ParseInt(stockStr, 10, 64)
-	if err != nil || stock < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Stock must be a non-negative integer"})
+	accessToken, err := auth.GenerateJWTWithSession(user.ID, user.Username, user.Email, user.Role, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
 		return
 	}
 
-	// Get user ID from context (set by AuthMiddleware)
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+	c.JSON(http.StatusOK, gin.H{
+		"token":        accessToken,
+		"refreshToken": newRefreshToken,
+	})
+}
+
+// @Summary Log out
+// @Description Revoke the presented refresh token and, if an access token is also provided, denylist its jti so AuthMiddleware rejects it immediately instead of waiting for natural expiry.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body object{refreshToken=string} true "Logout Request"
+// @Success 200 {object} object{message=string} "Logged out successfully"
+// @Failure 400 {object} object{error=string} "Missing refresh token"
+// @Failure 500 {object} object{error=string} "Failed to revoke refresh token"
+// @Router /auth/logout [post]
+func (s *Server) logoutHandler(c *gin.Context) {
+	var logoutRequest struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&logoutRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refreshToken is required"})
 		return
 	}
 
-	// Parse user ID to UUID
-	userUUID, err := uuid.Parse(userID.(string))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID"})
+	if err := s.refreshTokens.Revoke(logoutRequest.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
 		return
 	}
 
-	// Create product
-	product := models.Product{
-		Name:        name,
-		Description: description,
-		Price:       price,
-		Stock:       stock,
-		Category:    category,
-		UserID:      userUUID,
+	// Best-effort: denylist the current access token's jti for its remaining lifetime
+	var userID *uuid.UUID
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		if parts := strings.Split(authHeader, " "); len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := auth.ValidateJWT(parts[1]); err == nil {
+				auth.DenylistAccessToken(claims.ID, time.Until(claims.ExpiresAt.Time))
+				if id, err := uuid.Parse(claims.UserID()); err == nil {
+					userID = &id
+				}
+			}
+		}
 	}
 
-	// Handle image upload if provided
-	file, header, err := c.Request.FormFile("image")
-	if err == nil && file != nil {
-		defer file.Close()
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventTokenRevoked,
+		UserID:    userID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
 
-		// Check if Cloudinary is available
-		if s.cloudinary == nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Image upload service is not available"})
-			return
-		}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
 
-		// Upload to Cloudinary
-		imageURL, err := s.cloudinary.UploadImage(file, header.Filename, "products")
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
-			return
-		}
+// @Summary Log out of every session
+// @Description Revoke every refresh token belonging to the authenticated user, across every device/login, and denylist the current access token. Requires a fresh login afterward on every session, not just this one.
+// @Tags Auth
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} object{message=string} "Logged out of all sessions successfully"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 500 {object} object{error=string} "Failed to revoke sessions"
+// @Router /auth/logout-all [post]
+func (s *Server) logoutAllHandler(c *gin.Context) {
+	claims, ok := auth.ClaimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
 
-		product.ImageURL = imageURL
+	userID, err := uuid.Parse(claims.UserID())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user id in claims"})
+		return
 	}
 
-	// Save product to database
-	if err := s.db.Create(&product).Error; err != nil {
-		fmt.Printf("Database error creating product: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create product: %v", err)})
+	if err := s.refreshTokens.RevokeAllForUser(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
 		return
 	}
 
-	// Invalidate product listing cache
-	s.invalidateProductCache()
+	auth.DenylistAccessToken(claims.ID, time.Until(claims.ExpiresAt.Time))
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Product created successfully",
-		"product": product,
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventTokenRevoked,
+		UserID:    &userID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"scope": "all_sessions"},
 	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions successfully"})
 }
 
-// @Summary Update a product
-// @Description Update product details (Admin only). All fields are optional.
-// @Tags Products
-// @Accept json
+// @Summary List active sessions
+// @Description List every active (unrevoked, unexpired) refresh-token session belonging to the authenticated user, across every device, so they can audit or remotely sign out of one.
+// @Tags Auth
 // @Produce json
 // @Security Bearer
-// @Param id path string true "Product ID"
-// @Param request body object{name=string,description=string,price=number,stock=integer,category=string} false "Product Update Request" example({"name":"Updated Laptop","price":899.99})
-// @Success 200 {object} object{message=string,product=models.Product} "Product updated successfully"
-// @Failure 400 {object} object{error=string} "Validation error"
+// @Success 200 {object} object{sessions=[]auth.Session} "Active sessions"
 // @Failure 401 {object} object{error=string} "Unauthorized"
-// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
-// @Failure 404 {object} object{error=string} "Product not found"
-// @Failure 500 {object} object{error=string} "Internal server error"
-// @Router /products/{id} [put]
-func (s *Server) updateProductHandler(c *gin.Context) {
-	// Get product ID from URL parameter
-	id := c.Param("id")
-
-	// Find the product by ID
-	var product models.Product
-	if err := s.db.Where("id = ?", id).First(&product).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+// @Failure 500 {object} object{error=string} "Failed to list sessions"
+// @Router /auth/sessions [get]
+func (s *Server) listSessionsHandler(c *gin.Context) {
+	claims, ok := auth.ClaimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
 		return
 	}
 
-	// Update request struct - all fields are optional
-	var updateRequest struct {
-		Name        *string  `json:"name"`
-		Description *string  `json:"description"`
-		Price       *float64 `json:"price"`
-		Stock       *int64   `json:"stock"`
-		Category    *string  `json:"category"`
-	}
-
-	// Parse the request body
-	if err := c.ShouldBindJSON(&updateRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	userID, err := uuid.Parse(claims.UserID())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user id in claims"})
 		return
 	}
 
-	// Validate and update only the fields that were provided
-	if updateRequest.Name != nil {
-		// Name must be a non-empty string
-		if len(*updateRequest.Name) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be a non-empty string"})
-			return
-		}
-		product.Name = *updateRequest.Name
+	sessions, err := s.refreshTokens.ListSessions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
 	}
 
-	if updateRequest.Description != nil {
-		// Description must be a non-empty string
-		if len(*updateRequest.Description) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Description must be a non-empty string"})
-			return
-		}
-		product.Description = *updateRequest.Description
-	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
 
-	if updateRequest.Price != nil {
-		// Price must be a positive number
-		if *updateRequest.Price <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Price must be a positive number"})
-			return
-		}
-		product.Price = *updateRequest.Price
+// @Summary Revoke a session
+// @Description Revoke one of the authenticated user's refresh-token sessions by id (e.g. signing out a lost device), and denylist any access token already minted for it so AuthMiddleware rejects it immediately instead of waiting for its natural expiry.
+// @Tags Auth
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Session ID"
+// @Success 200 {object} object{message=string} "Session revoked"
+// @Failure 400 {object} object{error=string} "Invalid session id"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 404 {object} object{error=string} "Session not found"
+// @Router /auth/sessions/{id} [delete]
+func (s *Server) revokeSessionHandler(c *gin.Context) {
+	claims, ok := auth.ClaimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
 	}
 
-	if updateRequest.Stock != nil {
-		// Stock must be a non-negative integer
-		if *updateRequest.Stock < 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Stock must be a non-negative integer"})
-			return
-		}
-		product.Stock = *updateRequest.Stock
+	userID, err := uuid.Parse(claims.UserID())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user id in claims"})
+		return
 	}
 
-	if updateRequest.Category != nil {
-		// Category must be a non-empty string
-		if len(*updateRequest.Category) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Category must be a non-empty string"})
-			return
-		}
-		product.Category = *updateRequest.Category
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session id"})
+		return
 	}
 
-	// Save the updated product
-	if err := s.db.Save(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+	if err := s.refreshTokens.RevokeSession(userID, sessionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 		return
 	}
+	auth.RevokeSessionToken(sessionID, auth.AccessTokenTTL)
 
-	// Invalidate product listing cache
-	s.invalidateProductCache()
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Product updated successfully",
-		"product": product,
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventTokenRevoked,
+		UserID:    &userID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"scope": "single_session", "session_id": sessionID.String()},
 	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
 }
 
-// @Summary Get list of products
-// @Description Get paginated list of products with optional search. Results are cached in Redis for 5 minutes.
-// @Tags Products
-// @Produce json
-// @Param page query int false "Page number" default(1)
-// @Param pageSize query int false "Items per page" default(10)
-// @Param limit query int false "Items per page (alternative to pageSize)" default(10)
-// @Param search query string false "Search by product name (case-insensitive partial match)"
-// @Success 200 {object} object{currentPage=int,pageSize=int,totalPages=int,totalProducts=int,products=[]models.Product} "List of products"
-// @Failure 500 {object} object{error=string} "Internal server error"
-// @Router /products [get]
-func (s *Server) listProductsHandler(c *gin.Context) {
-	// Get pagination parameters from query string
-	page := 1
-	pageSize := 10
+// @Summary Start an OAuth2/OIDC login
+// @Description Redirect to the named provider's (e.g. google, github, dex) authorization endpoint, starting a PKCE authorization-code flow. The provider must be registered via env config; unknown providers return 404.
+// @Tags Authentication
+// @Param provider path string true "Provider name"
+// @Success 302 "Redirect to the provider's authorization endpoint"
+// @Failure 404 {object} object{error=string} "Unknown or unconfigured provider"
+// @Router /auth/{provider}/login [get]
+func (s *Server) providerLoginHandler(c *gin.Context) {
+	provider, ok := auth.GetProvider(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown auth provider"})
+		return
+	}
 
-	// Parse page parameter
-	if pageParam := c.Query("page"); pageParam != "" {
-		if parsedPage, err := parsePositiveInt(pageParam); err == nil && parsedPage > 0 {
-			page = parsedPage
-		}
+	handler := provider.LoginHandler()
+	if handler == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider does not support login redirects"})
+		return
 	}
+	handler(c)
+}
 
-	// Parse pageSize/limit parameter (support both names)
-	if pageSizeParam := c.Query("pageSize"); pageSizeParam != "" {
-		if parsedSize, err := parsePositiveInt(pageSizeParam); err == nil && parsedSize > 0 {
-			pageSize = parsedSize
-		}
-	} else if limitParam := c.Query("limit"); limitParam != "" {
-		if parsedLimit, err := parsePositiveInt(limitParam); err == nil && parsedLimit > 0 {
-			pageSize = parsedLimit
-		}
+// @Summary Complete an OAuth2/OIDC login
+// @Description Exchange the authorization code returned by the provider, fetch userinfo, and link or create the local user, returning a normal access token.
+// @Tags Authentication
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "Anti-CSRF state from the login redirect"
+// @Success 200 {object} object{token=string} "Access token for the linked user"
+// @Failure 400 {object} object{error=string} "Missing code/state or invalid login session"
+// @Failure 401 {object} object{error=string} "Code exchange or userinfo lookup failed"
+// @Failure 404 {object} object{error=string} "Unknown or unconfigured provider"
+// @Router /auth/{provider}/callback [get]
+func (s *Server) providerCallbackHandler(c *gin.Context) {
+	provider, ok := auth.GetProvider(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown auth provider"})
+		return
 	}
 
-	// Get search parameter
-	searchQuery := c.Query("search")
+	handler := provider.CallbackHandler()
+	if handler == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider does not support callbacks"})
+		return
+	}
+	handler(c)
+}
+
+// @Summary Begin 2FA enrollment
+// @Description Generate a new TOTP secret for the authenticated user and return an otpauth:// URI plus a QR code PNG (base64). The secret is not active until confirmed via POST /auth/2fa/verify.
+// @Tags Authentication
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} object{otpauthUrl=string,qrCodePng=string} "Pending secret generated"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 500 {object} object{error=string} "Failed to generate or persist secret"
+// @Router /auth/2fa/setup [post]
+func (s *Server) twoFactorSetupHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	secret, otpauthURL, err := auth.GenerateTOTPSecret(user.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate 2FA secret"})
+		return
+	}
+
+	encryptedSecret, err := auth.EncryptTOTPSecret(secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to secure 2FA secret"})
+		return
+	}
+
+	user.TwoFactorPendingSecret = encryptedSecret
+	if err := s.db.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to persist 2FA secret"})
+		return
+	}
+
+	qrPNG, err := auth.GenerateQRCodePNG(otpauthURL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"otpauthUrl": otpauthURL,
+		"qrCodePng":  base64.StdEncoding.EncodeToString(qrPNG),
+	})
+}
+
+// @Summary Confirm 2FA enrollment
+// @Description Validate a 6-digit code against the pending secret from /auth/2fa/setup; on success, enables 2FA and returns one-time recovery codes.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body object{code=string} true "Verification Request"
+// @Success 200 {object} object{message=string,recoveryCodes=[]string} "2FA enabled"
+// @Failure 400 {object} object{error=string} "No pending 2FA setup or invalid code"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Router /auth/2fa/verify [post]
+func (s *Server) twoFactorVerifyHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var verifyRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&verifyRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.TwoFactorPendingSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending 2FA setup; call /auth/2fa/setup first"})
+		return
+	}
+
+	secret, err := auth.DecryptTOTPSecret(user.TwoFactorPendingSecret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read pending 2FA secret"})
+		return
+	}
+
+	if !auth.ValidateTOTPCode(secret, verifyRequest.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification code"})
+		return
+	}
+
+	recoveryCodes, hashedCodes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		user.TwoFactorSecret = user.TwoFactorPendingSecret
+		user.TwoFactorPendingSecret = ""
+		user.TwoFactorEnabled = true
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+
+		for _, hash := range hashedCodes {
+			if err := tx.Create(&models.RecoveryCode{UserID: user.ID, CodeHash: hash}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+// @Summary Disable 2FA
+// @Description Disable two-factor authentication and delete the stored secret and recovery codes for the authenticated user.
+// @Tags Authentication
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} object{message=string} "2FA disabled"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Router /auth/2fa/disable [post]
+func (s *Server) twoFactorDisableHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"two_factor_enabled":        false,
+			"two_factor_secret":         "",
+			"two_factor_pending_secret": "",
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// @Summary Complete a 2FA challenge
+// @Description Exchange a mfa_pending token plus a 6-digit TOTP code (or a single-use recovery code) for a full access/refresh token pair.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body object{mfaToken=string,code=string} true "Challenge Request"
+// @Success 200 {object} object{token=string,refreshToken=string} "Full session issued"
+// @Failure 400 {object} object{error=string} "Missing fields"
+// @Failure 401 {object} object{error=string} "Invalid or expired mfa token, or invalid code"
+// @Router /auth/2fa/challenge [post]
+func (s *Server) twoFactorChallengeHandler(c *gin.Context) {
+	var challengeRequest struct {
+		MFAToken string `json:"mfaToken" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&challengeRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfaToken and code are required"})
+		return
+	}
+
+	claims, err := auth.ValidateJWT(challengeRequest.MFAToken)
+	if err != nil || claims.Purpose() != auth.PurposeMFAPending {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired two-factor challenge"})
+		return
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", claims.UserID()).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !s.verifyTwoFactorCode(&user, challengeRequest.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	refreshToken, sessionID, err := s.refreshTokens.Issue(user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate refresh token"})
+		return
+	}
+
+	accessToken, err := auth.GenerateJWTWithSession(user.ID, user.Username, user.Email, user.Role, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        accessToken,
+		"refreshToken": refreshToken,
+	})
+}
+
+// verifyTwoFactorCode accepts either a live TOTP code or an unused recovery code, consuming
+// the recovery code on successful use.
+func (s *Server) verifyTwoFactorCode(user *models.User, code string) bool {
+	if user.TwoFactorSecret != "" {
+		if secret, err := auth.DecryptTOTPSecret(user.TwoFactorSecret); err == nil {
+			if auth.ValidateTOTPCode(secret, code) {
+				// A code that validates but was already consumed in the last
+				// TOTPReplayWindow is a replay (e.g. an intercepted request retried by an
+				// attacker) and must be rejected even though it's still within its step window.
+				return auth.CheckTOTPReplay(user.ID.String(), code)
+			}
+		}
+	}
+
+	var recoveryCodes []models.RecoveryCode
+	if err := s.db.Where("user_id = ? AND used_at IS NULL", user.ID).Find(&recoveryCodes).Error; err != nil {
+		return false
+	}
+
+	for _, rc := range recoveryCodes {
+		if auth.CheckRecoveryCode(rc.CodeHash, code) {
+			s.db.Model(&models.RecoveryCode{}).Where("id = ?", rc.ID).Update("used_at", time.Now())
+			return true
+		}
+	}
+
+	return false
+}
+
+// @Summary Create a new product
+// @Description Create a new product in the catalog (Admin only). Supports optional image upload via multipart/form-data.
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param name formData string true "Product name"
+// @Param description formData string true "Product description"
+// @Param price formData number true "Product price (must be positive)"
+// @Param stock formData integer true "Product stock (must be non-negative)"
+// @Param category formData string true "Product category"
+// @Param image formData file false "Product image (jpg, jpeg, png, gif, webp)"
+// @Success 201 {object} object{message=string,product=models.Product} "Product created successfully"
+// @Failure 400 {object} object{error=string} "Validation error or invalid image format"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
+// @Failure 500 {object} object{error=string} "Internal server error or image upload failed"
+// @Router /domains/{domainID}/products [post]
+func (s *Server) createProductHandler(c *gin.Context) {
+	// Resolved and membership-checked by DomainMiddleware
+	domain, ok := auth.DomainFromContext(c)
+	if !ok {
+		c.Error(apierr.Forbidden("domain_required", "Domain membership required"))
+		return
+	}
+
+	// Parse multipart form
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil { // 10 MB max
+		c.Error(apierr.BadRequest("invalid_form", "Failed to parse form data"))
+		return
+	}
+
+	// Get form values
+	name := c.PostForm("name")
+	description := c.PostForm("description")
+	priceStr := c.PostForm("price")
+	stockStr := c.PostForm("stock")
+	category := c.PostForm("category")
+
+	// Validate required fields
+	if name == "" || description == "" || priceStr == "" || stockStr == "" || category == "" {
+		c.Error(apierr.BadRequest("missing_fields", "All fields (name, description, price, stock, category) are required"))
+		return
+	}
+
+	// Parse and validate price
+	price, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil || price <= 0 {
+		c.Error(apierr.BadRequest("invalid_price", "Price must be a positive number"))
+		return
+	}
+
+	// Parse and validate stock
+	stock, err := strconv.ParseInt(stockStr, 10, 64)
+	if err != nil || stock < 0 {
+		c.Error(apierr.BadRequest("invalid_stock", "Stock must be a non-negative integer"))
+		return
+	}
+
+	// Get user ID from context (set by AuthMiddleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Error(apierr.Unauthorized("authentication_required", "User authentication required"))
+		return
+	}
+
+	// Parse user ID to UUID
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.Error(apierr.Internal("invalid_user_id", "Invalid user ID"))
+		return
+	}
+
+	// Create product
+	product := models.Product{
+		Name:        name,
+		Description: description,
+		Price:       price,
+		Stock:       stock,
+		Category:    category,
+		UserID:      userUUID,
+		DomainID:    domain.DomainID,
+	}
+
+	// Save product to database first, so it has an ID to attach an image job to. The image
+	// itself (if provided) is attached asynchronously below.
+	if err := s.db.Create(&product).Error; err != nil {
+		fmt.Printf("Database error creating product: %v\n", err)
+		c.Error(apierr.Internal("product_creation_failed", fmt.Sprintf("Failed to create product: %v", err)))
+		return
+	}
+
+	// Handle image upload if provided: buffer it to disk and hand it off to a background
+	// job instead of uploading to Cloudinary inline, the same as uploadProductImageHandler.
+	var imageJobID string
+	file, header, err := c.Request.FormFile("image")
+	if err == nil && file != nil {
+		defer file.Close()
+
+		if s.cloudinary == nil {
+			c.Error(apierr.Internal("image_service_unavailable", "Image upload service is not available"))
+			return
+		}
+
+		imageJobID, err = s.enqueueProductImageJob(c.Request.Context(), product.ID, file, header.Filename, "")
+		if err != nil {
+			c.Error(apierr.Internal("image_job_enqueue_failed", fmt.Sprintf("Failed to queue image upload: %v", err)))
+			return
+		}
+	}
+
+	// Invalidate product listing cache
+	s.invalidateProductCache()
+
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventAdminAction,
+		UserID:    &userUUID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"action": "create_product", "product_id": product.ID},
+	})
+
+	response := gin.H{
+		"message": "Product created successfully",
+		"product": product,
+	}
+	if imageJobID != "" {
+		response["imageJobId"] = imageJobID
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary Update a product
+// @Description Update product details (Admin only). All fields are optional.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Product ID"
+// @Param request body object{name=string,description=string,price=number,stock=integer,category=string} false "Product Update Request" example({"name":"Updated Laptop","price":899.99})
+// @Success 200 {object} object{message=string,product=models.Product} "Product updated successfully"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Validation error"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "Unauthorized"
+// @Failure 403 {object} object{error=object{code=string,message=string,request_id=string}} "Forbidden - Admin only"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Product not found"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Internal server error"
+// @Router /domains/{domainID}/products/{id} [put]
+func (s *Server) updateProductHandler(c *gin.Context) {
+	domain, ok := auth.DomainFromContext(c)
+	if !ok {
+		c.Error(apierr.Forbidden("domain_required", "Domain membership required"))
+		return
+	}
+
+	// Get product ID from URL parameter
+	id := c.Param("id")
+
+	// Find the product by ID first, independent of domain, so a cross-domain ID is
+	// reported as 403 rather than a 404 that would also match a nonexistent ID.
+	var product models.Product
+	if err := s.db.Where("id = ?", id).First(&product).Error; err != nil {
+		c.Error(apierr.NotFound("product_not_found", "Product not found"))
+		return
+	}
+	if product.DomainID != domain.DomainID {
+		c.Error(apierr.Forbidden("cross_domain_product", "Product belongs to a different domain"))
+		return
+	}
+
+	// Update request struct - all fields are optional
+	var updateRequest struct {
+		Name        *string  `json:"name"`
+		Description *string  `json:"description"`
+		Price       *float64 `json:"price"`
+		Stock       *int64   `json:"stock"`
+		Category    *string  `json:"category"`
+	}
+
+	// Parse the request body
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		c.Error(apierr.BadRequest("invalid_request", err.Error()))
+		return
+	}
+
+	// Validate and update only the fields that were provided
+	if updateRequest.Name != nil {
+		// Name must be a non-empty string
+		if len(*updateRequest.Name) == 0 {
+			c.Error(apierr.BadRequest("invalid_name", "Name must be a non-empty string"))
+			return
+		}
+		product.Name = *updateRequest.Name
+	}
+
+	if updateRequest.Description != nil {
+		// Description must be a non-empty string
+		if len(*updateRequest.Description) == 0 {
+			c.Error(apierr.BadRequest("invalid_description", "Description must be a non-empty string"))
+			return
+		}
+		product.Description = *updateRequest.Description
+	}
+
+	if updateRequest.Price != nil {
+		// Price must be a positive number
+		if *updateRequest.Price <= 0 {
+			c.Error(apierr.BadRequest("invalid_price", "Price must be a positive number"))
+			return
+		}
+		product.Price = *updateRequest.Price
+	}
+
+	if updateRequest.Stock != nil {
+		// Stock must be a non-negative integer
+		if *updateRequest.Stock < 0 {
+			c.Error(apierr.BadRequest("invalid_stock", "Stock must be a non-negative integer"))
+			return
+		}
+		product.Stock = *updateRequest.Stock
+	}
+
+	// Price and stock changes can shift which rows land on which page (sort=price_asc/desc,
+	// in_stock=true in listProductsHandler), so they invalidate every listing page rather
+	// than just this product's own category tag.
+	priceOrStockChanged := updateRequest.Price != nil || updateRequest.Stock != nil
+
+	oldCategory := product.Category
+
+	if updateRequest.Category != nil {
+		// Category must be a non-empty string
+		if len(*updateRequest.Category) == 0 {
+			c.Error(apierr.BadRequest("invalid_category", "Category must be a non-empty string"))
+			return
+		}
+		product.Category = *updateRequest.Category
+	}
+
+	// Save the updated product
+	if err := s.db.Save(&product).Error; err != nil {
+		c.Error(apierr.Internal("product_update_failed", "Failed to update product"))
+		return
+	}
+
+	// Invalidate only the cached pages this product could appear on, instead of the whole
+	// products:* namespace: its own product tag, plus its category tag and, if the update
+	// moved it to a new category, the category tag it left behind too. A price or stock
+	// change additionally invalidates products:all, since it can change this product's
+	// position or presence on every listing page, not just its own category's.
+	ctx := context.Background()
+	domainPrefix := "domain:" + product.DomainID.String() + ":"
+	s.productTags.InvalidateTag(ctx, "product:"+product.ID.String())
+	s.productTags.InvalidateTag(ctx, domainPrefix+"category:"+product.Category)
+	if product.Category != oldCategory {
+		s.productTags.InvalidateTag(ctx, domainPrefix+"category:"+oldCategory)
+	}
+	if priceOrStockChanged {
+		s.productTags.InvalidateTag(ctx, domainPrefix+"products:all")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product updated successfully",
+		"product": product,
+	})
+}
+
+// allowedProductSorts maps the listProductsHandler "sort" query param to its ORDER BY
+// clause, used only in offset-pagination mode; cursor mode always walks created_at/id since
+// the keyset predicate is defined in terms of that tuple.
+var allowedProductSorts = map[string]string{
+	"price_asc":  "price ASC",
+	"price_desc": "price DESC",
+	"newest":     "created_at DESC",
+}
+
+// productListFilters is every cacheable, non-pagination query parameter listProductsHandler
+// accepts: a name search plus category/price-range/stock-availability filters and a sort
+// order. Normalizing these once (rather than reading c.Query ad hoc throughout the handler)
+// is what lets canonicalCacheSuffix hash the same way regardless of incidental differences
+// (case, param order) between two requests for the same logical page.
+type productListFilters struct {
+	Search   string
+	Category string
+	MinPrice float64
+	MaxPrice float64
+	InStock  bool
+	Sort     string
+}
+
+// parseProductListFilters reads and normalizes productListFilters from the request's query
+// string. An unrecognized sort value falls back to "newest" rather than erroring, matching
+// how an unrecognized search/category value degrades to "no match" instead of a 400.
+func parseProductListFilters(c *gin.Context) productListFilters {
+	filters := productListFilters{
+		Search:   strings.TrimSpace(c.Query("search")),
+		Category: strings.TrimSpace(c.Query("category")),
+		Sort:     c.Query("sort"),
+		InStock:  c.Query("in_stock") == "true",
+	}
+	if minPrice, err := strconv.ParseFloat(c.Query("min_price"), 64); err == nil {
+		filters.MinPrice = minPrice
+	}
+	if maxPrice, err := strconv.ParseFloat(c.Query("max_price"), 64); err == nil {
+		filters.MaxPrice = maxPrice
+	}
+	if _, ok := allowedProductSorts[filters.Sort]; !ok {
+		filters.Sort = "newest"
+	}
+	return filters
+}
+
+// applyProductListFilters adds filters' WHERE clauses to query, which must already be scoped
+// to a single domain.
+func applyProductListFilters(query *gorm.DB, filters productListFilters) *gorm.DB {
+	if filters.Search != "" {
+		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+filters.Search+"%")
+	}
+	if filters.Category != "" {
+		query = query.Where("category = ?", filters.Category)
+	}
+	if filters.MinPrice > 0 {
+		query = query.Where("price >= ?", filters.MinPrice)
+	}
+	if filters.MaxPrice > 0 {
+		query = query.Where("price <= ?", filters.MaxPrice)
+	}
+	if filters.InStock {
+		query = query.Where("stock > 0")
+	}
+	return query
+}
+
+// canonicalCacheSuffix hashes filters' fields in a fixed order plus the pagination
+// parameters into a short hex digest, so the cache key stays bounded in length no matter how
+// many filter params a future request adds, while two requests for the same logical page
+// (same filters, same pagination) always produce the same key regardless of query-string
+// param order.
+func canonicalCacheSuffix(filters productListFilters, page, pageSize int, cursor string) string {
+	canonical := fmt.Sprintf(
+		"search=%s&category=%s&min_price=%g&max_price=%g&in_stock=%t&sort=%s&page=%d&size=%d&cursor=%s",
+		strings.ToLower(filters.Search), strings.ToLower(filters.Category),
+		filters.MinPrice, filters.MaxPrice, filters.InStock, filters.Sort,
+		page, pageSize, cursor,
+	)
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:12])
+}
+
+// productCursor is the decoded form of the opaque, base64 pagination cursor
+// listProductsHandler accepts as ?cursor=... and returns as nextCursor/prevCursor: the
+// (created_at, id) of the boundary row, compared against the keyset predicate's tuple so
+// rows inserted after a page was fetched never shift a later page's results the way OFFSET
+// would. Dir records which direction that boundary continues in - "n" to keep walking
+// forward from nextCursor, "p" to walk backward from prevCursor - so decodeProductCursor
+// doesn't need a separate query param to know which predicate/order to use.
+type productCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uuid.UUID `json:"i"`
+	Dir       string    `json:"d"`
+}
+
+func encodeProductCursor(cursor productCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeProductCursor(s string) (productCursor, error) {
+	var cursor productCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return cursor, err
+	}
+	return cursor, nil
+}
+
+// @Summary Get list of products
+// @Description Get a page of products with optional search and filtering. Supports two pagination modes: offset (page/pageSize, the default) or keyset (pass a cursor previously returned as nextCursor/prevCursor). Results are cached (in-process L1, Redis L2) for 5 minutes.
+// @Tags Products
+// @Produce json
+// @Param page query int false "Page number (offset mode)" default(1)
+// @Param pageSize query int false "Items per page" default(10)
+// @Param limit query int false "Items per page (alternative to pageSize)" default(10)
+// @Param cursor query string false "Opaque pagination cursor from a previous response's nextCursor/prevCursor; switches to keyset mode"
+// @Param search query string false "Search by product name (case-insensitive partial match)"
+// @Param category query string false "Filter by exact category"
+// @Param min_price query number false "Filter by minimum price (inclusive)"
+// @Param max_price query number false "Filter by maximum price (inclusive)"
+// @Param in_stock query bool false "Filter to products with stock > 0"
+// @Param sort query string false "price_asc, price_desc, or newest (default, offset mode only)"
+// @Success 200 {object} object{currentPage=int,pageSize=int,totalPages=int,totalProducts=int,products=[]models.Product,nextCursor=string,prevCursor=string} "Page of products"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Internal server error"
+// @Router /domains/{domainID}/products [get]
+func (s *Server) listProductsHandler(c *gin.Context) {
+	domain, ok := auth.DomainFromContext(c)
+	if !ok {
+		c.Error(apierr.Forbidden("domain_required", "Domain membership required"))
+		return
+	}
+
+	// Get pagination parameters from query string
+	page := 1
+	pageSize := 10
+
+	// Parse page parameter
+	if pageParam := c.Query("page"); pageParam != "" {
+		if parsedPage, err := parsePositiveInt(pageParam); err == nil && parsedPage > 0 {
+			page = parsedPage
+		}
+	}
+
+	// Parse pageSize/limit parameter (support both names)
+	if pageSizeParam := c.Query("pageSize"); pageSizeParam != "" {
+		if parsedSize, err := parsePositiveInt(pageSizeParam); err == nil && parsedSize > 0 {
+			pageSize = parsedSize
+		}
+	} else if limitParam := c.Query("limit"); limitParam != "" {
+		if parsedLimit, err := parsePositiveInt(limitParam); err == nil && parsedLimit > 0 {
+			pageSize = parsedLimit
+		}
+	}
+
+	cursorParam := c.Query("cursor")
+	filters := parseProductListFilters(c)
+
+	// Look up this route's cache policy at request time (not once at startup) so toggling
+	// it - e.g. CACHE_PRODUCTS_LIST_ENABLED=false - takes effect on the very next request.
+	policy, ok := s.cachePolicies.Get("products:list")
+	if !ok {
+		policy = cache.DefaultProductsListPolicy()
+	}
+
+	// The cache key is the domain plus a canonical hash of every filter/pagination
+	// parameter, so it stays short and order-independent no matter how many query params
+	// this handler grows to accept. Anything the policy says this route's cache should
+	// additionally vary by (e.g. a future "sort") is folded into the hashed suffix too.
+	varyBy := ""
+	for _, param := range policy.VaryBy {
+		varyBy += fmt.Sprintf("&%s=%s", param, c.Query(param))
+	}
+	cacheKey := fmt.Sprintf("products:domain:%s:%s%s", domain.DomainID, canonicalCacheSuffix(filters, page, pageSize, cursorParam), varyBy)
+
+	if !policy.Enabled || s.productCache == nil {
+		// Caching disabled for this route (or unavailable): behave exactly as if Redis
+		// didn't exist, with no cache reads, writes, or ETag bookkeeping at all.
+		response, _, err := s.fetchProductListPage(domain.DomainID, page, pageSize, cursorParam, filters)
+		if err != nil {
+			c.Error(apierr.Internal("product_list_failed", err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	ctx := context.Background()
+	if cachedData, _, ok := s.productCache.Get(ctx, cacheKey); ok {
+		var entry productListCacheEntry
+		if err := json.Unmarshal(cachedData, &entry); err == nil {
+			if time.Now().Before(entry.ExpiresAt) {
+				s.writeProductListResponse(c, entry)
+				return
+			}
+
+			// Soft-expired but still inside the stale-while-revalidate window: serve the
+			// stale bytes immediately and refresh the entry in the background. Concurrent
+			// stale hits for the same key coalesce onto a single refresh via
+			// productListGroup, same as a cold cache-miss does.
+			go func() {
+				s.productListGroup.Do(cacheKey, func() (interface{}, error) {
+					return s.buildProductListEntry(cacheKey, domain.DomainID, page, pageSize, cursorParam, filters, policy)
+				})
+			}()
+			s.writeProductListResponse(c, entry)
+			return
+		}
+	}
+
+	// Cache miss - fetch from database. Every concurrent request for the same cacheKey
+	// coalesces onto a single in-flight query via singleflight, so a cold cache entry
+	// doesn't let a burst of requests stampede the database.
+	result, shared, err := s.productListGroup.Do(cacheKey, func() (interface{}, error) {
+		return s.buildProductListEntry(cacheKey, domain.DomainID, page, pageSize, cursorParam, filters, policy)
+	})
+
+	if shared {
+		s.singleflightShared.Add(1)
+	}
+
+	if err != nil {
+		c.Error(apierr.Internal("product_list_failed", err.Error()))
+		return
+	}
+
+	s.writeProductListResponse(c, result.(productListCacheEntry))
+}
+
+// fetchProductListPage runs the filtered product query directly against the database, with
+// no caching involved, returning both the JSON-ready response and the raw product rows (the
+// latter needed by callers that tag cache entries per product). If cursorParam is empty it
+// paginates by page/pageSize (the default, offset-based, API-compatible mode); otherwise it
+// decodes cursorParam as a keyset cursor and pages with a "WHERE (created_at, id) <op> (?, ?)"
+// predicate instead, immune to the "page 2 skips/repeats a row" problem OFFSET has under
+// concurrent writes.
+func (s *Server) fetchProductListPage(domainID uuid.UUID, page, pageSize int, cursorParam string, filters productListFilters) (gin.H, []models.Product, error) {
+	baseQuery := applyProductListFilters(s.db.Model(&models.Product{}).Where("domain_id = ?", domainID), filters)
+
+	var totalProducts int64
+	if err := baseQuery.Count(&totalProducts).Error; err != nil {
+		return nil, nil, errors.New("Failed to count products")
+	}
+
+	if cursorParam != "" {
+		return s.fetchProductListPageByCursor(baseQuery, cursorParam, pageSize, totalProducts)
+	}
+
+	offset := (page - 1) * pageSize
+	totalPages := int(totalProducts) / pageSize
+	if int(totalProducts)%pageSize != 0 {
+		totalPages++
+	}
+	if totalProducts == 0 {
+		totalPages = 0
+	}
+
+	var products []models.Product
+	if err := baseQuery.Order(allowedProductSorts[filters.Sort]).Offset(offset).Limit(pageSize).Find(&products).Error; err != nil {
+		return nil, nil, errors.New("Failed to fetch products")
+	}
+
+	response := gin.H{
+		"currentPage":   page,
+		"pageSize":      len(products),
+		"totalPages":    totalPages,
+		"totalProducts": totalProducts,
+		"products":      products,
+	}
+	return response, products, nil
+}
+
+// fetchProductListPageByCursor applies cursorParam's keyset predicate to baseQuery (already
+// scoped to a domain and filtered) and returns one page: "n" cursors keep walking forward
+// with "< (created_at, id)" in descending order, "p" cursors walk backward with
+// "> (created_at, id)" in ascending order (then the result is reversed back to the usual
+// newest-first display order). nextCursor/prevCursor are only populated when there's
+// actually somewhere further to go in that direction.
+func (s *Server) fetchProductListPageByCursor(baseQuery *gorm.DB, cursorParam string, pageSize int, totalProducts int64) (gin.H, []models.Product, error) {
+	cursor, err := decodeProductCursor(cursorParam)
+	if err != nil {
+		return nil, nil, errors.New("Invalid cursor")
+	}
+
+	backward := cursor.Dir == "p"
+	query := baseQuery
+	orderBy := "created_at DESC, id DESC"
+	if backward {
+		query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+		orderBy = "created_at ASC, id ASC"
+	} else {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	// Fetch one extra row to learn whether another page exists in this direction without a
+	// second query.
+	var products []models.Product
+	if err := query.Order(orderBy).Limit(pageSize + 1).Find(&products).Error; err != nil {
+		return nil, nil, errors.New("Failed to fetch products")
+	}
+
+	hasMore := len(products) > pageSize
+	if hasMore {
+		products = products[:pageSize]
+	}
+	if backward {
+		for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+			products[i], products[j] = products[j], products[i]
+		}
+	}
+
+	// Walking backward always leaves at least one page ahead (the one just left); walking
+	// forward (or the very first page) only has a next page if the extra row was fetched.
+	hasNext := backward || hasMore
+	hasPrev := (backward && hasMore) || !backward
+
+	var nextCursor, prevCursor string
+	if hasNext && len(products) > 0 {
+		last := products[len(products)-1]
+		nextCursor = encodeProductCursor(productCursor{CreatedAt: last.CreatedAt, ID: last.ID, Dir: "n"})
+	}
+	if hasPrev && len(products) > 0 {
+		first := products[0]
+		prevCursor = encodeProductCursor(productCursor{CreatedAt: first.CreatedAt, ID: first.ID, Dir: "p"})
+	}
+
+	response := gin.H{
+		"pageSize":      len(products),
+		"totalProducts": totalProducts,
+		"products":      products,
+		"nextCursor":    nextCursor,
+		"prevCursor":    prevCursor,
+	}
+	return response, products, nil
+}
+
+// buildProductListEntry fetches a product listing page, wraps it in a productListCacheEntry
+// (with an ETag and a logical expiry derived from policy.TTL), writes it to the cache, and
+// tags it so a later product/category write can invalidate it precisely.
+func (s *Server) buildProductListEntry(cacheKey string, domainID uuid.UUID, page, pageSize int, cursorParam string, filters productListFilters, policy cache.Policy) (productListCacheEntry, error) {
+	response, products, err := s.fetchProductListPage(domainID, page, pageSize, cursorParam, filters)
+	if err != nil {
+		return productListCacheEntry{}, err
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return productListCacheEntry{}, errors.New("Failed to encode response")
+	}
 
-	// Generate cache key based on query parameters
-	cacheKey := fmt.Sprintf("products:page:%d:size:%d:search:%s", page, pageSize, searchQuery)
+	entry := productListCacheEntry{
+		Body:      responseJSON,
+		ETag:      computeETag(responseJSON),
+		ExpiresAt: time.Now().Add(policy.TTL),
+	}
 
-	// Try to get from cache if Redis is available
-	if s.redis != nil {
+	if s.productCache != nil {
 		ctx := context.Background()
-		cachedData, err := s.redis.Get(ctx, cacheKey).Result()
-		if err == nil && cachedData != "" {
-			// Cache hit - return cached data
-			var cachedResponse map[string]interface{}
-			if err := json.Unmarshal([]byte(cachedData), &cachedResponse); err == nil {
-				c.JSON(http.StatusOK, cachedResponse)
-				return
+		if entryJSON, err := json.Marshal(entry); err == nil {
+			// Keep the physical copy alive through the stale-while-revalidate window too,
+			// so a request landing just after the soft TTL still finds something to serve
+			// while the background refresh above is in flight.
+			s.productCache.Set(ctx, cacheKey, entryJSON, policy.TTL+policy.StaleWhileRevalidate)
+
+			// Tag this page with everything it was derived from, so a write to any one of
+			// these can invalidate just this page instead of the whole products:*
+			// namespace. "domain:<id>:products:all" covers changes (like a new product)
+			// that could shift which rows land on which page regardless of category or
+			// search term, scoped to this domain so a write in one tenant's catalog never
+			// invalidates another's cached pages.
+			domainPrefix := "domain:" + domainID.String() + ":"
+			tags := []string{domainPrefix + "products:all"}
+			if filters.Search != "" {
+				tags = append(tags, domainPrefix+"search:"+filters.Search)
+			}
+			if filters.Category != "" {
+				tags = append(tags, domainPrefix+"category:"+filters.Category)
+			}
+			seenCategory := make(map[string]bool)
+			for _, p := range products {
+				tags = append(tags, "product:"+p.ID.String())
+				if !seenCategory[p.Category] {
+					seenCategory[p.Category] = true
+					tags = append(tags, domainPrefix+"category:"+p.Category)
+				}
 			}
+			s.productTags.Tag(ctx, cacheKey, tags...)
 		}
 	}
 
-	// Cache miss or Redis unavailable - fetch from database
-	// Calculate offset for pagination
-	offset := (page - 1) * pageSize
+	return entry, nil
+}
 
-	// Build query with optional search filter
-	query := s.db.Model(&models.Product{})
-	if searchQuery != "" {
-		// Case-insensitive partial match search on product name
-		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+searchQuery+"%")
+// writeProductListResponse writes entry's ETag and Cache-Control headers, answering a
+// matching If-None-Match with a bare 304, and otherwise writing the cached JSON body
+// directly without re-marshaling it.
+func (s *Server) writeProductListResponse(c *gin.Context, entry productListCacheEntry) {
+	maxAge := int(time.Until(entry.ExpiresAt).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
 	}
+	c.Header("ETag", entry.ETag)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
 
-	// Get total count of products (with search filter if applicable)
-	var totalProducts int64
-	if err := query.Count(&totalProducts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count products"})
+	if inm := c.GetHeader("If-None-Match"); inm != "" && inm == entry.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", entry.Body)
+}
+
+// productListCacheEntry is what's actually stored in the product listing cache: the
+// serialized response, a strong ETag of it, and the logical deadline at which it turns
+// stale - independent of how long the physical Redis key survives, which is extended past
+// ExpiresAt by the policy's stale-while-revalidate window.
+type productListCacheEntry struct {
+	Body      []byte    `json:"body"`
+	ETag      string    `json:"etag"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// computeETag derives a strong ETag from body: a truncated SHA-256 digest, quoted per
+// RFC 9110. Truncating keeps the header short while still making accidental collisions
+// between two different cached pages effectively impossible.
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%x"`, sum[:16])
+}
+
+// @Summary Get product by ID
+// @Description Get detailed information about a specific product
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Success 200 {object} models.Product "Product details"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Product not found"
+// @Router /domains/{domainID}/products/{id} [get]
+func (s *Server) getProductHandler(c *gin.Context) {
+	domain, ok := auth.DomainFromContext(c)
+	if !ok {
+		c.Error(apierr.Forbidden("domain_required", "Domain membership required"))
+		return
+	}
+
+	// Get product ID from URL parameter
+	productID := c.Param("id")
+
+	// Find product by ID, scoped to the caller's domain - a product belonging to
+	// another domain is indistinguishable from one that doesn't exist at all.
+	var product models.Product
+	if err := s.db.Where("id = ? AND domain_id = ?", productID, domain.DomainID).First(&product).Error; err != nil {
+		c.Error(apierr.NotFound("product_not_found", "Product not found"))
+		return
+	}
+
+	// Return product details
+	c.JSON(http.StatusOK, product)
+}
+
+// @Summary Delete a product
+// @Description Delete a product from the catalog (Admin only). Invalidates product listing cache.
+// @Tags Products
+// @Security Bearer
+// @Param id path string true "Product ID (UUID)"
+// @Success 200 {object} object{message=string} "Product deleted successfully"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "Unauthorized"
+// @Failure 403 {object} object{error=object{code=string,message=string,request_id=string}} "Forbidden - Admin only"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Product not found"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Internal server error"
+// @Router /domains/{domainID}/products/{id} [delete]
+func (s *Server) deleteProductHandler(c *gin.Context) {
+	domain, ok := auth.DomainFromContext(c)
+	if !ok {
+		c.Error(apierr.Forbidden("domain_required", "Domain membership required"))
+		return
+	}
+
+	// Get product ID from URL parameter
+	productID := c.Param("id")
+
+	// Find product by ID first to check if it exists, independent of domain, so a
+	// cross-domain ID is reported as 403 rather than a 404.
+	var product models.Product
+	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
+		c.Error(apierr.NotFound("product_not_found", "Product not found"))
+		return
+	}
+	if product.DomainID != domain.DomainID {
+		c.Error(apierr.Forbidden("cross_domain_product", "Product belongs to a different domain"))
+		return
+	}
+
+	// Delete image from Cloudinary if it exists
+	if product.ImageURL != "" && s.cloudinary != nil {
+		publicID := cldinary.ExtractPublicID(product.ImageURL)
+		if publicID != "" {
+			// Delete from Cloudinary (don't fail if this fails)
+			_ = s.cloudinary.DeleteImage(c.Request.Context(), publicID)
+		}
+	}
+
+	// Delete the product
+	if err := s.db.Delete(&product).Error; err != nil {
+		c.Error(apierr.Internal("product_delete_failed", "Failed to delete product"))
+		return
+	}
+
+	// Invalidate only the cached pages that could have listed this product.
+	ctx := context.Background()
+	s.productTags.InvalidateTag(ctx, "product:"+product.ID.String())
+	s.productTags.InvalidateTag(ctx, "domain:"+product.DomainID.String()+":products:all")
+
+	// Return success message
+	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
+}
+
+// @Summary Upload product image
+// @Description Queue an upload or update of a product's image (Admin only). Accepts image files in jpg, jpeg, png, gif, or webp format. The upload runs asynchronously - poll GET /jobs/{jobId} for the result.
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Product ID"
+// @Param image formData file true "Product image file (jpg, jpeg, png, gif, webp, max 10MB)"
+// @Success 202 {object} object{message=string,jobId=string} "Image upload queued"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Invalid file format or upload error"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "Unauthorized"
+// @Failure 403 {object} object{error=object{code=string,message=string,request_id=string}} "Forbidden - Admin only"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Product not found"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Image upload service unavailable"
+// @Router /domains/{domainID}/products/{id}/image [post]
+func (s *Server) uploadProductImageHandler(c *gin.Context) {
+	domain, ok := auth.DomainFromContext(c)
+	if !ok {
+		c.Error(apierr.Forbidden("domain_required", "Domain membership required"))
 		return
 	}
 
-	// Calculate total pages
-	totalPages := int(totalProducts) / pageSize
-	if int(totalProducts)%pageSize != 0 {
-		totalPages++
+	// Get product ID from URL
+	productID := c.Param("id")
+
+	// Find product, independent of domain, so a cross-domain ID is reported as 403
+	// rather than a 404.
+	var product models.Product
+	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
+		c.Error(apierr.NotFound("product_not_found", "Product not found"))
+		return
+	}
+	if product.DomainID != domain.DomainID {
+		c.Error(apierr.Forbidden("cross_domain_product", "Product belongs to a different domain"))
+		return
+	}
+
+	// Check if Cloudinary is available
+	if s.cloudinary == nil {
+		c.Error(apierr.Internal("image_service_unavailable", "Image upload service is not available"))
+		return
+	}
+
+	// Get the uploaded file
+	file, header, err := c.Request.FormFile("image")
+	if err != nil {
+		c.Error(apierr.BadRequest("missing_image", "No image file provided"))
+		return
+	}
+	defer file.Close()
+
+	// Buffer the upload to disk and hand off the Cloudinary upload/delete to a background
+	// job instead of doing it inline; the job updates product.ImageURL once it completes.
+	jobID, err := s.enqueueProductImageJob(c.Request.Context(), product.ID, file, header.Filename, product.ImageURL)
+	if err != nil {
+		c.Error(apierr.Internal("image_job_enqueue_failed", fmt.Sprintf("Failed to queue image upload: %v", err)))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Image upload queued",
+		"jobId":   jobID,
+	})
+}
+
+// @Summary Create a new order
+// @Description Create a new order for the authenticated user with one or more products. This endpoint validates product availability, checks stock levels, and updates inventory atomically. All operations are performed within a database transaction to ensure data consistency.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param order body object{items=[]object{productId=string,quantity=int}} true "Order items with product IDs and quantities"
+// @Success 201 {object} models.Order "Order created successfully with full details including order products"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Invalid request body or empty order"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "User not authenticated"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "One or more products not found"
+// @Failure 409 {object} object{error=object{code=string,message=string,request_id=string}} "Insufficient stock for one or more items"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Failed to create order or update stock"
+// @Router /orders [post]
+func (s *Server) createOrderHandler(c *gin.Context) {
+	// Get user ID from context (set by AuthMiddleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
+		return
+	}
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
+		return
+	}
+
+	// Parse request body
+	var orderItems []struct {
+		ProductID string `json:"productId" binding:"required"`
+		Quantity  int    `json:"quantity" binding:"required,gt=0"`
+	}
+
+	if err := c.ShouldBindJSON(&orderItems); err != nil {
+		c.Error(apierr.BadRequest("invalid_request", "Invalid request body"))
+		return
+	}
+
+	items := make([]orders.Item, len(orderItems))
+	for i, item := range orderItems {
+		items[i] = orders.Item{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	// The transactional/row-locking work itself lives in orders.Service.Create, shared with
+	// the gRPC OrderService so both APIs place an order the exact same way.
+	order, err := s.orders.Create(c.Request.Context(), userUUID, items)
+	if err != nil {
+		var notFound *orders.ProductNotFoundError
+		var insufficientStock *orders.InsufficientStockError
+		switch {
+		case errors.Is(err, orders.ErrEmptyOrder):
+			c.Error(apierr.BadRequest("empty_order", err.Error()))
+		case errors.As(err, &notFound):
+			c.Error(apierr.NotFound("product_not_found", err.Error()))
+		case errors.As(err, &insufficientStock):
+			// 409 (not 400) since the request itself is well-formed - it's the current
+			// state of the product that makes it unsatisfiable.
+			c.Error(apierr.Conflict("insufficient_stock", err.Error()))
+		default:
+			c.Error(apierr.Internal("order_creation_failed", "Failed to create order"))
+		}
+		return
+	}
+
+	// Load order products with product details for response
+	var createdOrder models.Order
+	if err := s.db.Preload("OrderProducts.Product").First(&createdOrder, order.ID).Error; err != nil {
+		// Order was created successfully, but we couldn't load it
+		// Return basic order info
+		c.JSON(http.StatusCreated, order)
+		return
+	}
+
+	// Return created order with full details
+	c.JSON(http.StatusCreated, createdOrder)
+}
+
+// defaultOrdersPageLimit and maxOrdersPageLimit bound the "limit" query parameter
+// getOrdersHandler accepts - unbounded page sizes would defeat the point of keyset
+// pagination by letting a client still pull every order in one request.
+const (
+	defaultOrdersPageLimit = 20
+	maxOrdersPageLimit     = 100
+)
+
+// ordersCursor is the decoded form of the opaque "cursor" query parameter getOrdersHandler
+// accepts: the (created_at, id) of the last row the client has already seen, which keyset
+// pagination resumes strictly after. Encoding both fields (rather than just an offset) keeps
+// ordering stable even if orders are inserted concurrently between pages.
+type ordersCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// encodeOrdersCursor packs an order's (created_at, id) into the opaque cursor string
+// getOrdersHandler hands back as next_cursor.
+func encodeOrdersCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := fmt.Sprintf("%s|%s", createdAt.UTC().Format(time.RFC3339Nano), id.String())
+	return base64.URLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeOrdersCursor reverses encodeOrdersCursor, rejecting anything that isn't a cursor
+// this handler itself produced - a hand-crafted or corrupted value fails here rather than
+// being fed into the keyset WHERE clause.
+func decodeOrdersCursor(cursor string) (ordersCursor, error) {
+	raw, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ordersCursor{}, fmt.Errorf("invalid cursor encoding")
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return ordersCursor{}, fmt.Errorf("invalid cursor format")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return ordersCursor{}, fmt.Errorf("invalid cursor timestamp")
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return ordersCursor{}, fmt.Errorf("invalid cursor id")
+	}
+
+	return ordersCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// @Summary Get user's orders
+// @Description Retrieve the authenticated user's orders, newest first, using keyset (cursor-based) pagination rather than OFFSET so deep pages stay cheap. Pass the response's next_cursor back as the cursor parameter to fetch the next page; has_more is false once there's nothing left. Returns an empty array if the user has no orders.
+// @Tags Orders
+// @Produce json
+// @Security Bearer
+// @Param limit query int false "Page size (1-100)" default(20)
+// @Param cursor query string false "Opaque cursor from a previous response's next_cursor"
+// @Param status query string false "Comma-separated list of statuses to filter by, e.g. pending,completed"
+// @Param from query string false "Only orders created at or after this RFC3339 timestamp"
+// @Param to query string false "Only orders created at or before this RFC3339 timestamp"
+// @Success 200 {object} object{orders=[]models.Order,next_cursor=string,has_more=bool} "Page of the user's orders (orders may be empty)"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Invalid limit, cursor, status, from, or to parameter"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "User not authenticated"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Failed to retrieve orders from database"
+// @Router /orders [get]
+func (s *Server) getOrdersHandler(c *gin.Context) {
+	// Get user ID from context (set by AuthMiddleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
+		return
+	}
+
+	// user_id scopes every clause below, including the keyset comparison, so even a
+	// hand-crafted cursor built from another user's order can only resume within this
+	// user's own result set.
+	s.respondOrdersPage(c, s.db.Where("user_id = ?", userID))
+}
+
+// @Summary List every user's orders (admin)
+// @Description Admin counterpart to GET /orders: the same keyset-paginated, filterable listing, but across every user, optionally narrowed to one with user_id.
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param limit query int false "Page size (1-100)" default(20)
+// @Param cursor query string false "Opaque cursor from a previous response's next_cursor"
+// @Param status query string false "Comma-separated list of statuses to filter by, e.g. pending,completed"
+// @Param from query string false "Only orders created at or after this RFC3339 timestamp"
+// @Param to query string false "Only orders created at or before this RFC3339 timestamp"
+// @Param user_id query string false "Restrict results to one user's orders"
+// @Success 200 {object} object{orders=[]models.Order,next_cursor=string,has_more=bool} "Page of orders (orders may be empty)"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Invalid limit, cursor, status, from, to, or user_id parameter"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "User not authenticated"
+// @Failure 403 {object} object{error=object{code=string,message=string,request_id=string}} "Caller is not an admin"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Failed to retrieve orders from database"
+// @Router /admin/orders [get]
+func (s *Server) listAllOrdersHandler(c *gin.Context) {
+	query := s.db
+
+	if userIDParam := c.Query("user_id"); userIDParam != "" {
+		targetUserID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			c.Error(apierr.BadRequest("invalid_user_id", "user_id must be a valid UUID"))
+			return
+		}
+		query = query.Where("user_id = ?", targetUserID)
+	}
+
+	s.respondOrdersPage(c, query)
+}
+
+// respondOrdersPage applies getOrdersHandler/listAllOrdersHandler's shared limit/status/from/
+// to/cursor query parameters onto query (already scoped to whichever user(s) the caller may
+// see), executes it, and writes the keyset-paginated response both handlers share.
+func (s *Server) respondOrdersPage(c *gin.Context, query *gorm.DB) {
+	limit := defaultOrdersPageLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsedLimit, err := parsePositiveInt(limitParam)
+		if err != nil || parsedLimit < 1 || parsedLimit > maxOrdersPageLimit {
+			c.Error(apierr.BadRequest("invalid_limit", fmt.Sprintf("limit must be between 1 and %d", maxOrdersPageLimit)))
+			return
+		}
+		limit = parsedLimit
+	}
+
+	if statusParam := c.Query("status"); statusParam != "" {
+		query = query.Where("status IN ?", strings.Split(statusParam, ","))
+	}
+
+	if fromParam := c.Query("from"); fromParam != "" {
+		from, err := time.Parse(time.RFC3339, fromParam)
+		if err != nil {
+			c.Error(apierr.BadRequest("invalid_from", "from must be an RFC3339 timestamp"))
+			return
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+
+	if toParam := c.Query("to"); toParam != "" {
+		to, err := time.Parse(time.RFC3339, toParam)
+		if err != nil {
+			c.Error(apierr.BadRequest("invalid_to", "to must be an RFC3339 timestamp"))
+			return
+		}
+		query = query.Where("created_at <= ?", to)
 	}
 
-	// If total is 0, totalPages should be 0
-	if totalProducts == 0 {
-		totalPages = 0
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		cursor, err := decodeOrdersCursor(cursorParam)
+		if err != nil {
+			c.Error(apierr.BadRequest("invalid_cursor", "invalid cursor"))
+			return
+		}
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
 	}
 
-	// Get products for current page (with search filter if applicable)
-	var products []models.Product
-	if err := query.Offset(offset).Limit(pageSize).Find(&products).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+	// Fetch one row past limit so has_more/next_cursor can be derived without a second
+	// COUNT query - the classic keyset-pagination trick for avoiding OFFSET's cost of
+	// scanning and discarding every preceding row.
+	var orders []models.Order
+	if err := query.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&orders).Error; err != nil {
+		c.Error(apierr.Internal("orders_fetch_failed", "Failed to retrieve orders"))
 		return
 	}
 
-	// Prepare response
-	response := gin.H{
-		"currentPage":   page,
-		"pageSize":      len(products),
-		"totalPages":    totalPages,
-		"totalProducts": totalProducts,
-		"products":      products,
+	hasMore := len(orders) > limit
+	if hasMore {
+		orders = orders[:limit]
 	}
 
-	// Cache the response if Redis is available
-	if s.redis != nil {
-		ctx := context.Background()
-		responseJSON, err := json.Marshal(response)
-		if err == nil {
-			// Cache for 5 minutes
-			s.redis.Set(ctx, cacheKey, responseJSON, 5*time.Minute)
-		}
+	nextCursor := ""
+	if hasMore {
+		last := orders[len(orders)-1]
+		nextCursor = encodeOrdersCursor(last.CreatedAt, last.ID)
 	}
 
-	// Return response
-	c.JSON(http.StatusOK, response)
+	// Mirrors next_cursor in a header too, so a client that only cares about pagination
+	// doesn't need to parse the body to keep paging.
+	c.Header("X-Next-Cursor", nextCursor)
+
+	c.JSON(http.StatusOK, gin.H{
+		"orders":      orders,
+		"next_cursor": nextCursor,
+		"has_more":    hasMore,
+	})
 }
 
-// @Summary Get product by ID
-// @Description Get detailed information about a specific product
-// @Tags Products
-// @Produce json
-// @Param id path string true "Product ID (UUID)"
-// @Success 200 {object} models.Product "Product details"
-// @Failure 404 {object} object{error=string} "Product not found"
-// @Router /products/{id} [get]
-func (s *Server) getProductHandler(c *gin.Context) {
-	// Get product ID from URL parameter
-	productID := c.Param("id")
+// allowedOrderTransitions enumerates which OrderStatus an order may move to from each
+// current status. Any transition not listed here - including a status transitioning to
+// itself - is rejected by isValidOrderTransition.
+var allowedOrderTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderStatusPending:         {models.OrderStatusAwaitingPayment, models.OrderStatusCancelled, models.OrderStatusExpired},
+	models.OrderStatusAwaitingPayment: {models.OrderStatusPaid, models.OrderStatusFailed, models.OrderStatusCancelled, models.OrderStatusExpired},
+	models.OrderStatusPaid:            {models.OrderStatusFulfilled, models.OrderStatusCancelled, models.OrderStatusRefunded},
+	models.OrderStatusFulfilled:       {models.OrderStatusRefunded},
+}
 
-	// Find product by ID
-	var product models.Product
-	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
-		return
+func isValidOrderTransition(from, to models.OrderStatus) bool {
+	for _, candidate := range allowedOrderTransitions[from] {
+		if candidate == to {
+			return true
+		}
 	}
+	return false
+}
 
-	// Return product details
-	c.JSON(http.StatusOK, product)
+// knownOrderStatuses are every OrderStatus value updateOrderStatusHandler will accept,
+// regardless of whether allowedOrderTransitions permits reaching it from the order's current
+// status - that's checked separately, by isValidOrderTransition.
+var knownOrderStatuses = map[models.OrderStatus]bool{
+	models.OrderStatusPending:         true,
+	models.OrderStatusAwaitingPayment: true,
+	models.OrderStatusPaid:            true,
+	models.OrderStatusFailed:          true,
+	models.OrderStatusFulfilled:       true,
+	models.OrderStatusCancelled:       true,
+	models.OrderStatusRefunded:        true,
+	models.OrderStatusExpired:         true,
 }
 
-// @Summary Delete a product
-// @Description Delete a product from the catalog (Admin only). Invalidates product listing cache.
-// @Tags Products
-// @Security Bearer
-// @Param id path string true "Product ID (UUID)"
-// @Success 200 {object} object{message=string} "Product deleted successfully"
-// @Failure 401 {object} object{error=string} "Unauthorized"
-// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
-// @Failure 404 {object} object{error=string} "Product not found"
-// @Failure 500 {object} object{error=string} "Internal server error"
-// @Router /products/{id} [delete]
-func (s *Server) deleteProductHandler(c *gin.Context) {
-	// Get product ID from URL parameter
-	productID := c.Param("id")
+func isKnownOrderStatus(status models.OrderStatus) bool {
+	return knownOrderStatuses[status]
+}
 
-	// Find product by ID first to check if it exists
-	var product models.Product
-	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
-		return
+// restoreOrderStock adds each of order's OrderProducts' quantities back onto its product's
+// Stock, row-locked the same way createOrderHandler locks them when decrementing. Called
+// within the same transaction that moves an order to OrderStatusCancelled, so a cancel either
+// fully restores stock and commits, or touches nothing.
+func restoreOrderStock(tx *gorm.DB, orderID uuid.UUID) error {
+	var orderProducts []models.OrderProduct
+	if err := tx.Where("order_id = ?", orderID).Find(&orderProducts).Error; err != nil {
+		return err
 	}
 
-	// Delete image from Cloudinary if it exists
-	if product.ImageURL != "" && s.cloudinary != nil {
-		publicID := cldinary.ExtractPublicID(product.ImageURL)
-		if publicID != "" {
-			// Delete from Cloudinary (don't fail if this fails)
-			_ = s.cloudinary.DeleteImage(publicID)
+	for _, op := range orderProducts {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", op.ProductID).First(&product).Error; err != nil {
+			return err
+		}
+		product.Stock += int64(op.Quantity)
+		if err := tx.Save(&product).Error; err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// Delete the product
-	if err := s.db.Delete(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product"})
-		return
-	}
-
-	// Invalidate product listing cache
-	s.invalidateProductCache()
-
-	// Return success message
-	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
+type updateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
 }
 
-// @Summary Upload product image
-// @Description Upload or update a product's image (Admin only). Accepts image files in jpg, jpeg, png, gif, or webp format.
-// @Tags Products
-// @Accept multipart/form-data
+// @Summary Update an order's status
+// @Description Transition an order to a new status (Admin only). Only the transitions in allowedOrderTransitions are permitted; cancelling an order restores the stock it reserved.
+// @Tags Orders
+// @Accept json
 // @Produce json
 // @Security Bearer
-// @Param id path string true "Product ID"
-// @Param image formData file true "Product image file (jpg, jpeg, png, gif, webp, max 10MB)"
-// @Success 200 {object} object{message=string,imageUrl=string} "Image uploaded successfully"
-// @Failure 400 {object} object{error=string} "Invalid file format or upload error"
-// @Failure 401 {object} object{error=string} "Unauthorized"
-// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
-// @Failure 404 {object} object{error=string} "Product not found"
-// @Failure 500 {object} object{error=string} "Image upload service unavailable"
-// @Router /products/{id}/image [post]
-func (s *Server) uploadProductImageHandler(c *gin.Context) {
-	// Get product ID from URL
-	productID := c.Param("id")
-
-	// Find product
-	var product models.Product
-	if err := s.db.Where("id = ?", productID).First(&product).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+// @Param id path string true "Order ID"
+// @Param request body updateOrderStatusRequest true "New order status"
+// @Success 200 {object} models.Order "Order updated successfully"
+// @Failure 400 {object} object{error=object{code=string,message=string,request_id=string}} "Invalid request body or unknown status"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "Unauthorized"
+// @Failure 403 {object} object{error=object{code=string,message=string,request_id=string}} "Forbidden - Admin only"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Order not found"
+// @Failure 409 {object} object{error=object{code=string,message=string,request_id=string}} "Status transition not allowed from the order's current status"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Failed to update order"
+// @Router /orders/{id}/status [patch]
+func (s *Server) updateOrderStatusHandler(c *gin.Context) {
+	var req updateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierr.BadRequest("invalid_request", "Invalid request body"))
+		return
+	}
+	newStatus := models.OrderStatus(req.Status)
+	if !isKnownOrderStatus(newStatus) {
+		c.Error(apierr.BadRequest("unknown_status", "Unknown order status"))
 		return
 	}
 
-	// Check if Cloudinary is available
-	if s.cloudinary == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Image upload service is not available"})
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", c.Param("id")).First(&order).Error; err != nil {
+		tx.Rollback()
+		c.Error(apierr.NotFound("order_not_found", "Order not found"))
 		return
 	}
 
-	// Get the uploaded file
-	file, header, err := c.Request.FormFile("image")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
+	if !isValidOrderTransition(order.Status, newStatus) {
+		tx.Rollback()
+		c.Error(apierr.Conflict("invalid_transition", fmt.Sprintf("Cannot transition order from %s to %s", order.Status, newStatus)))
 		return
 	}
-	defer file.Close()
 
-	// Delete old image if it exists
-	if product.ImageURL != "" {
-		publicID := cldinary.ExtractPublicID(product.ImageURL)
-		if publicID != "" {
-			// Don't fail if deletion fails
-			_ = s.cloudinary.DeleteImage(publicID)
+	if newStatus == models.OrderStatusCancelled || newStatus == models.OrderStatusFailed || newStatus == models.OrderStatusExpired {
+		if err := restoreOrderStock(tx, order.ID); err != nil {
+			tx.Rollback()
+			c.Error(apierr.Internal("stock_restore_failed", "Failed to restore stock"))
+			return
 		}
 	}
 
-	// Upload new image
-	imageURL, err := s.cloudinary.UploadImage(file, header.Filename, "products")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to upload image: %v", err)})
+	order.Status = newStatus
+	if err := tx.Save(&order).Error; err != nil {
+		tx.Rollback()
+		c.Error(apierr.Internal("order_update_failed", "Failed to update order"))
 		return
 	}
 
-	// Update product with new image URL
-	product.ImageURL = imageURL
-	if err := s.db.Save(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product with image URL"})
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apierr.Internal("transaction_commit_failed", "Failed to commit transaction"))
 		return
 	}
 
-	// Invalidate product cache
-	s.invalidateProductCache()
+	if newStatus == models.OrderStatusCancelled || newStatus == models.OrderStatusFailed || newStatus == models.OrderStatusExpired {
+		s.invalidateProductCache()
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":  "Image uploaded successfully",
-		"imageUrl": imageURL,
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventAdminAction,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"action": "update_order_status", "order_id": order.ID, "status": newStatus},
 	})
+
+	c.JSON(http.StatusOK, order)
 }
 
-// @Summary Create a new order
-// @Description Create a new order for the authenticated user with one or more products. This endpoint validates product availability, checks stock levels, and updates inventory atomically. All operations are performed within a database transaction to ensure data consistency.
+// @Summary Cancel an order
+// @Description Cancel an order you own, restoring the stock it reserved. Only allowed while the order is in a cancellable status (see allowedOrderTransitions).
 // @Tags Orders
-// @Accept json
 // @Produce json
 // @Security Bearer
-// @Param order body object{items=[]object{productId=string,quantity=int}} true "Order items with product IDs and quantities"
-// @Success 201 {object} models.Order "Order created successfully with full details including order products"
-// @Failure 400 {object} object{error=string} "Invalid request body, empty order, or insufficient stock"
-// @Failure 401 {object} object{error=string} "User not authenticated"
-// @Failure 404 {object} object{error=string} "One or more products not found"
-// @Failure 500 {object} object{error=string} "Failed to create order or update stock"
-// @Router /orders [post]
-func (s *Server) createOrderHandler(c *gin.Context) {
-	// Get user ID from context (set by AuthMiddleware)
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.Order "Order cancelled successfully"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "User not authenticated"
+// @Failure 403 {object} object{error=object{code=string,message=string,request_id=string}} "Order belongs to a different user"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Order not found"
+// @Failure 409 {object} object{error=object{code=string,message=string,request_id=string}} "Order cannot be cancelled from its current status"
+// @Failure 500 {object} object{error=object{code=string,message=string,request_id=string}} "Failed to cancel order"
+// @Router /orders/{id}/cancel [post]
+func (s *Server) cancelOrderHandler(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-
-	// Parse request body
-	var orderItems []struct {
-		ProductID string `json:"productId" binding:"required"`
-		Quantity  int    `json:"quantity" binding:"required,gt=0"`
-	}
-
-	if err := c.ShouldBindJSON(&orderItems); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
 		return
 	}
-
-	if len(orderItems) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order must contain at least one item"})
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
 		return
 	}
 
-	// Start database transaction
 	tx := s.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -754,127 +2276,224 @@ func (s *Server) createOrderHandler(c *gin.Context) {
 		}
 	}()
 
-	if tx.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", c.Param("id")).First(&order).Error; err != nil {
+		tx.Rollback()
+		c.Error(apierr.NotFound("order_not_found", "Order not found"))
+		return
+	}
+	if order.UserID != userUUID {
+		tx.Rollback()
+		c.Error(apierr.Forbidden("cross_user_order", "Order belongs to a different user"))
 		return
 	}
 
-	// Validate products and check stock
-	var totalPrice float64
-	var orderProducts []models.OrderProduct
+	if !isValidOrderTransition(order.Status, models.OrderStatusCancelled) {
+		tx.Rollback()
+		c.Error(apierr.Conflict("invalid_transition", fmt.Sprintf("Order cannot be cancelled from status %s", order.Status)))
+		return
+	}
 
-	for _, item := range orderItems {
-		var product models.Product
+	if err := restoreOrderStock(tx, order.ID); err != nil {
+		tx.Rollback()
+		c.Error(apierr.Internal("stock_restore_failed", "Failed to restore stock"))
+		return
+	}
 
-		// Find product and lock row for update to prevent race conditions
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", item.ProductID).First(&product).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Product with ID %s not found", item.ProductID)})
-			return
-		}
+	order.Status = models.OrderStatusCancelled
+	if err := tx.Save(&order).Error; err != nil {
+		tx.Rollback()
+		c.Error(apierr.Internal("order_cancel_failed", "Failed to cancel order"))
+		return
+	}
 
-		// Check stock availability
-		if product.Stock < int64(item.Quantity) {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Insufficient stock for product: %s (available: %d, requested: %d)", product.Name, product.Stock, item.Quantity)})
-			return
-		}
+	if err := outbox.Write(tx, order.ID.String(), outbox.EventOrderCancelled, map[string]any{"order_id": order.ID}); err != nil {
+		tx.Rollback()
+		c.Error(apierr.Internal("transaction_commit_failed", "Failed to record order.cancelled event"))
+		return
+	}
 
-		// Calculate item total and add to order total
-		itemTotal := product.Price * float64(item.Quantity)
-		totalPrice += itemTotal
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apierr.Internal("transaction_commit_failed", "Failed to commit transaction"))
+		return
+	}
 
-		// Update product stock
-		product.Stock -= int64(item.Quantity)
-		if err := tx.Save(&product).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product stock"})
-			return
-		}
+	s.invalidateProductCache()
 
-		// Store order product info for later creation
-		orderProducts = append(orderProducts, models.OrderProduct{
-			ProductID: product.ID,
-			Quantity:  item.Quantity,
-			Price:     product.Price, // Store price at time of order
-		})
+	c.JSON(http.StatusOK, order)
+}
+
+// Helper function to parse positive integers from string
+func parsePositiveInt(s string) (int, error) {
+	var result int
+	_, err := fmt.Sscanf(s, "%d", &result)
+	return result, err
+}
+
+type grantRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// @Summary Grant a role to a user
+// @Description Grant an RBAC role to a user by ID (Admin only).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "User ID (UUID)"
+// @Param request body grantRoleRequest true "Role to grant"
+// @Success 200 {object} object{message=string} "Role granted successfully"
+// @Failure 400 {object} object{error=string} "Invalid request body or user ID"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
+// @Failure 500 {object} object{error=string} "Failed to grant role"
+// @Router /admin/users/{id}/roles [post]
+func (s *Server) grantRoleHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
 	}
 
-	// Create order
-	order := models.Order{
-		UserID:      userID.(uuid.UUID),
-		Description: fmt.Sprintf("Order with %d item(s)", len(orderItems)),
-		TotalPrice:  totalPrice,
-		Status:      "pending",
+	var req grantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role is required"})
+		return
 	}
 
-	if err := tx.Create(&order).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+	if err := s.rbac.GrantRole(userID, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant role: " + err.Error()})
 		return
 	}
 
-	// Create order products (join table entries)
-	for i := range orderProducts {
-		orderProducts[i].OrderID = order.ID
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventAdminAction,
+		UserID:    &userID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"action": "grant_role", "role": req.Role},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role granted successfully"})
+}
+
+// @Summary Revoke a role from a user
+// @Description Revoke an RBAC role from a user by ID (Admin only).
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param id path string true "User ID (UUID)"
+// @Param role path string true "Role name"
+// @Success 200 {object} object{message=string} "Role revoked successfully"
+// @Failure 400 {object} object{error=string} "Invalid user ID"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
+// @Failure 500 {object} object{error=string} "Failed to revoke role"
+// @Router /admin/users/{id}/roles/{role} [delete]
+func (s *Server) revokeRoleHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
 	}
 
-	if err := tx.Create(&orderProducts).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order items"})
+	if err := s.rbac.RevokeRole(userID, c.Param("role")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke role: " + err.Error()})
 		return
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventAdminAction,
+		UserID:    &userID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"action": "revoke_role", "role": c.Param("role")},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role revoked successfully"})
+}
+
+type approleLoginRequest struct {
+	RoleID   string `json:"role_id" binding:"required"`
+	SecretID string `json:"secret_id" binding:"required"`
+}
+
+// @Summary Log in as an AppRole
+// @Description Exchange an AppRole's RoleID/SecretID pair for a short-lived, scoped access token - the machine-to-machine equivalent of POST /auth/login.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body approleLoginRequest true "RoleID/SecretID pair"
+// @Success 200 {object} object{token=string} "Access token"
+// @Failure 400 {object} object{error=string} "Invalid request body"
+// @Failure 401 {object} object{error=string} "Invalid role_id or secret_id"
+// @Failure 500 {object} object{error=string} "Failed to generate authentication token"
+// @Router /auth/approle/login [post]
+func (s *Server) approleLoginHandler(c *gin.Context) {
+	var req approleLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role_id and secret_id are required"})
 		return
 	}
 
-	// Load order products with product details for response
-	var createdOrder models.Order
-	if err := s.db.Preload("OrderProducts.Product").First(&createdOrder, order.ID).Error; err != nil {
-		// Order was created successfully, but we couldn't load it
-		// Return basic order info
-		c.JSON(http.StatusCreated, order)
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid role_id or secret_id"})
 		return
 	}
 
-	// Return created order with full details
-	c.JSON(http.StatusCreated, createdOrder)
+	role, err := s.appRoles.Login(roleID, req.SecretID)
+	if err != nil {
+		audit.Emit(c.Request.Context(), audit.Event{
+			Type:      audit.EventLoginFailed,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Metadata:  map[string]any{"reason": "approle_mismatch", "role_id": req.RoleID},
+		})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid role_id or secret_id"})
+		return
+	}
+
+	token, err := auth.GenerateAppRoleJWT(role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate authentication token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token})
 }
 
-// @Summary Get user's orders
-// @Description Retrieve all orders for the authenticated user, ordered by creation date (newest first). Returns an empty array if the user has no orders.
-// @Tags Orders
+// @Summary Rotate an AppRole's SecretID
+// @Description Issue a new SecretID for an AppRole (Admin only). The previous SecretID keeps working for a grace window so an already-deployed caller has time to switch over.
+// @Tags Admin
 // @Produce json
 // @Security Bearer
-// @Success 200 {array} models.Order "List of user's orders (may be empty)"
-// @Failure 401 {object} object{error=string} "User not authenticated"
-// @Failure 500 {object} object{error=string} "Failed to retrieve orders from database"
-// @Router /orders [get]
-func (s *Server) getOrdersHandler(c *gin.Context) {
-	// Get user ID from context (set by AuthMiddleware)
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+// @Param roleID path string true "AppRole RoleID (UUID)"
+// @Success 200 {object} object{secret_id=string} "Newly issued SecretID - shown exactly once"
+// @Failure 400 {object} object{error=string} "Invalid role ID"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden - Admin only"
+// @Failure 500 {object} object{error=string} "Failed to rotate secret"
+// @Router /admin/approles/{roleID}/rotate-secret [post]
+func (s *Server) rotateAppRoleSecretHandler(c *gin.Context) {
+	roleID, err := uuid.Parse(c.Param("roleID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
 		return
 	}
 
-	// Query orders for the authenticated user
-	var orders []models.Order
-	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&orders).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve orders"})
+	secretID, err := s.appRoles.RotateSecret(roleID, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate secret: " + err.Error()})
 		return
 	}
 
-	// Return orders (empty array if no orders found)
-	c.JSON(http.StatusOK, orders)
-}
+	audit.Emit(c.Request.Context(), audit.Event{
+		Type:      audit.EventAdminAction,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Metadata:  map[string]any{"action": "rotate_approle_secret", "role_id": roleID.String()},
+	})
 
-// Helper function to parse positive integers from string
-func parsePositiveInt(s string) (int, error) {
-	var result int
-	_, err := fmt.Sscanf(s, "%d", &result)
-	return result, err
+	c.JSON(http.StatusOK, gin.H{"secret_id": secretID})
 }
@@ -11,8 +11,15 @@ import (
 	"os"
 	"testing"
 	"time"
+	"tundra/internal/apierr"
+	"tundra/internal/audit"
 	"tundra/internal/auth"
+	"tundra/internal/cache"
 	"tundra/internal/database/models"
+	"tundra/internal/jobs"
+	"tundra/internal/mailer"
+	"tundra/internal/orders"
+	"tundra/internal/payment"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -103,7 +110,7 @@ func setupTestDatabase(t *testing.T) *gorm.DB {
 	require.NoError(t, err)
 
 	// Run migrations
-	err = db.AutoMigrate(&models.User{}, &models.Product{}, &models.Order{}, &models.OrderProduct{})
+	err = db.AutoMigrate(&models.User{}, &models.Product{}, &models.Order{}, &models.OrderProduct{}, &models.AuditEvent{}, &models.IdempotencyKey{}, &models.StockReservation{}, &models.OutboxEvent{})
 	require.NoError(t, err)
 
 	// Store container for cleanup
@@ -129,16 +136,33 @@ func setupTestServer(t *testing.T) (*Server, *gin.Engine) {
 
 	// Set up test JWT secret
 	os.Setenv("JWT_SECRET", "test-secret-key-for-testing-only")
+	// Registration tests hash and validate real passwords; skip the live HIBP lookup so
+	// this package's tests don't depend on network access (see auth.ValidatePassword).
+	os.Setenv("SKIP_BREACH_CHECK", "true")
 
 	db := setupTestDatabase(t)
+	audit.SetEmitters(audit.NewGORMEmitter(db))
+	productCache, err := cache.NewLayered(nil, 1<<20)
+	require.NoError(t, err)
+
+	cachePolicies := cache.NewPolicyRegistry()
+	cachePolicies.Set("products:list", cache.DefaultProductsListPolicy())
 
 	server := &Server{
-		db:    db,
-		redis: nil, // No Redis by default for auth tests
+		db:              db,
+		redis:           nil, // No Redis by default for auth tests
+		mailer:          mailer.NewFakeMailer(),
+		productCache:    productCache,
+		productTags:     cache.NewTagIndex(nil),
+		cachePolicies:   cachePolicies,
+		jobQueue:        jobs.NewMemoryQueue(),
+		paymentProvider: payment.NewFakeProvider(),
+		orders:          orders.NewService(db),
 	}
 
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(apierr.Middleware())
 
 	return server, router
 }
@@ -149,17 +173,34 @@ func setupTestServerWithRedis(t *testing.T) (*Server, *gin.Engine) {
 
 	// Set up test JWT secret
 	os.Setenv("JWT_SECRET", "test-secret-key-for-testing-only")
+	// Registration tests hash and validate real passwords; skip the live HIBP lookup so
+	// this package's tests don't depend on network access (see auth.ValidatePassword).
+	os.Setenv("SKIP_BREACH_CHECK", "true")
 
 	db := setupTestDatabase(t)
 	redisClient := setupTestRedis(t)
+	audit.SetEmitters(audit.NewGORMEmitter(db))
+	productCache, err := cache.NewLayered(redisClient, 1<<20)
+	require.NoError(t, err)
+
+	cachePolicies := cache.NewPolicyRegistry()
+	cachePolicies.Set("products:list", cache.DefaultProductsListPolicy())
 
 	server := &Server{
-		db:    db,
-		redis: redisClient,
+		db:              db,
+		redis:           redisClient,
+		mailer:          mailer.NewFakeMailer(),
+		productCache:    productCache,
+		productTags:     cache.NewTagIndex(redisClient),
+		cachePolicies:   cachePolicies,
+		jobQueue:        jobs.NewRedisQueue(redisClient),
+		paymentProvider: payment.NewFakeProvider(),
+		orders:          orders.NewService(db),
 	}
 
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(apierr.Middleware())
 
 	return server, router
 }
@@ -278,10 +319,14 @@ func TestSignUpHandler_InvalidUsername(t *testing.T) {
 
 			assert.Equal(t, http.StatusBadRequest, resp.Code)
 
-			var response map[string]string
+			var response struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
 			err := json.Unmarshal(resp.Body.Bytes(), &response)
 			require.NoError(t, err)
-			assert.Contains(t, response["error"], tt.wantErr)
+			assert.Contains(t, response.Error.Message, tt.wantErr)
 		})
 	}
 }
@@ -319,10 +364,14 @@ func TestSignUpHandler_InvalidEmail(t *testing.T) {
 
 			assert.Equal(t, http.StatusBadRequest, resp.Code)
 
-			var response map[string]string
+			var response struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
 			err := json.Unmarshal(resp.Body.Bytes(), &response)
 			require.NoError(t, err)
-			assert.Contains(t, response["error"], "email must be a valid email address")
+			assert.Contains(t, response.Error.Message, "email must be a valid email address")
 		})
 	}
 }
@@ -382,10 +431,14 @@ func TestSignUpHandler_WeakPassword(t *testing.T) {
 
 			assert.Equal(t, http.StatusBadRequest, resp.Code)
 
-			var response map[string]string
+			var response struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
 			err := json.Unmarshal(resp.Body.Bytes(), &response)
 			require.NoError(t, err)
-			assert.Contains(t, response["error"], tt.wantErr)
+			assert.Contains(t, response.Error.Message, tt.wantErr)
 		})
 	}
 }
@@ -412,12 +465,16 @@ func TestSignUpHandler_DuplicateUsername(t *testing.T) {
 
 	router.ServeHTTP(resp, req)
 
-	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, http.StatusConflict, resp.Code)
 
-	var response map[string]string
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
 	err := json.Unmarshal(resp.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Contains(t, response["error"], "Username is already taken")
+	assert.Contains(t, response.Error.Message, "Username is already taken")
 }
 
 func TestSignUpHandler_DuplicateEmail(t *testing.T) {
@@ -442,12 +499,16 @@ func TestSignUpHandler_DuplicateEmail(t *testing.T) {
 
 	router.ServeHTTP(resp, req)
 
-	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, http.StatusConflict, resp.Code)
 
-	var response map[string]string
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
 	err := json.Unmarshal(resp.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Contains(t, response["error"], "Email is already registered")
+	assert.Contains(t, response.Error.Message, "Email is already registered")
 }
 
 func TestLoginHandler_Success(t *testing.T) {
@@ -486,8 +547,8 @@ func TestLoginHandler_Success(t *testing.T) {
 
 	claims, err := auth.ValidateJWT(token)
 	require.NoError(t, err)
-	assert.Equal(t, "test@example.com", claims.Email)
-	assert.Equal(t, "testuser", claims.Username)
+	assert.Equal(t, "test@example.com", claims.Email())
+	assert.Equal(t, "testuser", claims.Username())
 }
 
 func TestLoginHandler_InvalidEmail(t *testing.T) {
@@ -510,10 +571,14 @@ func TestLoginHandler_InvalidEmail(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 
-	var response map[string]string
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
 	err := json.Unmarshal(resp.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Contains(t, response["error"], "Invalid email format")
+	assert.Contains(t, response.Error.Message, "Invalid email format")
 }
 
 func TestLoginHandler_UserNotFound(t *testing.T) {
@@ -536,10 +601,14 @@ func TestLoginHandler_UserNotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, resp.Code)
 
-	var response map[string]string
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
 	err := json.Unmarshal(resp.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Equal(t, "Invalid credentials", response["error"])
+	assert.Equal(t, "Invalid credentials", response.Error.Message)
 }
 
 func TestLoginHandler_WrongPassword(t *testing.T) {
@@ -565,10 +634,14 @@ func TestLoginHandler_WrongPassword(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, resp.Code)
 
-	var response map[string]string
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
 	err := json.Unmarshal(resp.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Equal(t, "Invalid credentials", response["error"])
+	assert.Equal(t, "Invalid credentials", response.Error.Message)
 }
 
 func TestLoginHandler_MissingFields(t *testing.T) {
@@ -686,10 +759,10 @@ func TestJWTTokenGeneration(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify claims
-	assert.Equal(t, user.ID.String(), claims.UserID)
-	assert.Equal(t, user.Username, claims.Username)
-	assert.Equal(t, user.Email, claims.Email)
-	assert.Equal(t, user.Role, claims.Role)
+	assert.Equal(t, user.ID.String(), claims.UserID())
+	assert.Equal(t, user.Username, claims.Username())
+	assert.Equal(t, user.Email, claims.Email())
+	assert.Equal(t, user.Role, claims.Role())
 }
 
 // ==================== Redis Caching Tests ====================
@@ -1050,16 +1123,79 @@ func TestProductListCaching_CacheExpiration(t *testing.T) {
 	router.ServeHTTP(resp1, req1)
 	require.Equal(t, http.StatusOK, resp1.Code)
 
-	// Verify cache key exists
+	// Verify cache key exists. The default "products:list" policy varies by "sort" too, so
+	// the key carries a (here empty) sort segment.
 	ctx := context.Background()
-	cacheKey := "products:page:1:size:10:search:"
+	cacheKey := "products:page:1:size:10:search::sort:"
 	exists, err := server.redis.Exists(ctx, cacheKey).Result()
 	require.NoError(t, err)
 	assert.Equal(t, int64(1), exists)
 
-	// Check TTL is set (should be around 5 minutes = 300 seconds)
+	// The physical Redis entry stays alive through TTL + stale-while-revalidate, so a
+	// request arriving just after the policy's soft TTL still has something to serve.
+	policy, ok := server.cachePolicies.Get("products:list")
+	require.True(t, ok)
 	ttl, err := server.redis.TTL(ctx, cacheKey).Result()
 	require.NoError(t, err)
 	assert.Greater(t, ttl.Seconds(), float64(0))
-	assert.LessOrEqual(t, ttl.Seconds(), float64(300))
+	assert.LessOrEqual(t, ttl.Seconds(), (policy.TTL + policy.StaleWhileRevalidate).Seconds())
+}
+
+func TestProductListCaching_ETagConditionalGET(t *testing.T) {
+	server, router := setupTestServerWithRedis(t)
+	defer cleanupTestDatabase(t)
+	defer cleanupTestRedis(t)
+
+	router.GET("/products", server.listProductsHandler)
+	router.PUT("/products/:id", auth.AuthMiddleware(), auth.AdminMiddleware(), server.updateProductHandler)
+
+	adminUser := createTestUser(t, server.db, "admin", "admin@test.com", "Password123!")
+	adminUser.Role = "admin"
+	require.NoError(t, server.db.Save(adminUser).Error)
+	token, err := auth.GenerateJWT(adminUser.ID, adminUser.Username, adminUser.Email, adminUser.Role)
+	require.NoError(t, err)
+
+	product := models.Product{
+		Name:        "Original Product",
+		Description: "Original Description",
+		Price:       100.0,
+		Stock:       10,
+		Category:    "Original",
+	}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	// First GET returns 200 with an ETag.
+	req1, _ := http.NewRequest("GET", "/products", nil)
+	resp1 := httptest.NewRecorder()
+	router.ServeHTTP(resp1, req1)
+	require.Equal(t, http.StatusOK, resp1.Code)
+	etag := resp1.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Second GET with a matching If-None-Match returns 304 with an empty body.
+	req2, _ := http.NewRequest("GET", "/products", nil)
+	req2.Header.Set("If-None-Match", etag)
+	resp2 := httptest.NewRecorder()
+	router.ServeHTTP(resp2, req2)
+	require.Equal(t, http.StatusNotModified, resp2.Code)
+	assert.Empty(t, resp2.Body.Bytes())
+
+	// Updating the product rotates the cache entry, so the same If-None-Match no longer
+	// matches and the client gets a fresh 200 with a new ETag.
+	updateJSON, _ := json.Marshal(map[string]interface{}{"name": "Updated Product"})
+	updateReq, _ := http.NewRequest("PUT", "/products/"+product.ID.String(), bytes.NewBuffer(updateJSON))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateReq.Header.Set("Authorization", "Bearer "+token)
+	updateResp := httptest.NewRecorder()
+	router.ServeHTTP(updateResp, updateReq)
+	require.Equal(t, http.StatusOK, updateResp.Code)
+
+	req3, _ := http.NewRequest("GET", "/products", nil)
+	req3.Header.Set("If-None-Match", etag)
+	resp3 := httptest.NewRecorder()
+	router.ServeHTTP(resp3, req3)
+	require.Equal(t, http.StatusOK, resp3.Code)
+	newETag := resp3.Header().Get("ETag")
+	require.NotEmpty(t, newETag)
+	assert.NotEqual(t, etag, newETag)
 }
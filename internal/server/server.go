@@ -6,25 +6,87 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 
+	"github.com/GRACENOBLE/tundra/internal/audit"
+	"github.com/GRACENOBLE/tundra/internal/auth"
+	"github.com/GRACENOBLE/tundra/internal/cache"
 	"github.com/GRACENOBLE/tundra/internal/cloudinary"
 	"github.com/GRACENOBLE/tundra/internal/database"
+	"github.com/GRACENOBLE/tundra/internal/jobs"
+	"github.com/GRACENOBLE/tundra/internal/mailer"
+	"github.com/GRACENOBLE/tundra/internal/orders"
+	"github.com/GRACENOBLE/tundra/internal/outbox"
+	"github.com/GRACENOBLE/tundra/internal/payment"
+	"github.com/GRACENOBLE/tundra/internal/ratelimit"
+	"github.com/GRACENOBLE/tundra/internal/tracing"
 )
 
+// productImageWorkerCount is how many goroutines process productImageJobType jobs
+// concurrently; image upload/delete is I/O-bound against Cloudinary, so a handful of workers
+// keeps the queue draining without needing a dynamic pool.
+const productImageWorkerCount = 4
+
+// productCacheL1Bytes bounds the in-process L1 tier of productCache.
+const productCacheL1Bytes = 64 << 20 // 64MB
+
 type Server struct {
 	port int
 
-	db         *gorm.DB
-	redis      *redis.Client
-	cloudinary *cloudinary.Client
+	db              *gorm.DB
+	redis           *redis.Client
+	cloudinary      *cloudinary.Client
+	refreshTokens   auth.RefreshStore
+	rbac            *auth.RBACStore
+	appRoles        *auth.AppRoleStore
+	mailer          mailer.Mailer
+	productCache    *cache.Layered
+	productTags     *cache.TagIndex
+	cachePolicies   *cache.PolicyRegistry
+	jobQueue        jobs.Queue
+	paymentProvider payment.Provider
+	orders          *orders.Service
+
+	// productListGroup coalesces concurrent cache-miss queries in listProductsHandler onto
+	// a single database round trip per cache key.
+	productListGroup singleflight.Group
+	// singleflightShared counts requests that were served by an in-flight productListGroup
+	// call they didn't originate, i.e. stampedes avoided.
+	singleflightShared atomic.Uint64
 }
 
-func NewServer() *http.Server {
+// SingleflightSharedTotal reports how many requests have been served by sharing another
+// in-flight listProductsHandler query instead of running their own, for operators to gauge
+// cache-stampede protection effectiveness.
+func (s *Server) SingleflightSharedTotal() uint64 {
+	return s.singleflightShared.Load()
+}
+
+// DB returns the *gorm.DB this Server queries. Intended for cmd/api to hand to
+// grpcserver.NewGRPCServer once it registers real services, so the REST and gRPC APIs share one
+// connection pool; unused until then (see cmd/api/main.go).
+func (s *Server) DB() *gorm.DB {
+	return s.db
+}
+
+// Orders returns the *orders.Service this Server's createOrderHandler delegates to. Intended for
+// cmd/api to hand to grpcserver.NewGRPCServer once it registers real services, so CreateOrder
+// behaves identically over gRPC; unused until then (see cmd/api/main.go).
+func (s *Server) Orders() *orders.Service {
+	return s.orders
+}
+
+// NewServer builds the application's *Server (the dependencies every handler needs) plus the
+// *http.Server that serves it. Returned separately so cmd/api can also hand *Server's db and
+// orders.Service to grpcserver.NewGRPCServer, which runs the gRPC API on its own port
+// alongside this one.
+func NewServer() (*Server, *http.Server) {
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
 
 	// Initialize Redis client
@@ -46,6 +108,13 @@ func NewServer() *http.Server {
 		redisClient = nil
 	}
 
+	// Initialize OpenTelemetry tracing so every request, GORM query, and Cloudinary upload
+	// along its path shows up as spans in a single distributed trace. Disabled (no-op) when
+	// OTEL_EXPORTER_OTLP_ENDPOINT isn't set.
+	if _, err := tracing.Init(ctx); err != nil {
+		fmt.Printf("Warning: tracing initialization failed: %v. Tracing will be disabled.\n", err)
+	}
+
 	// Initialize Cloudinary client
 	cloudinaryClient, err := cloudinary.NewClient()
 	if err != nil {
@@ -53,13 +122,166 @@ func NewServer() *http.Server {
 		cloudinaryClient = nil
 	}
 
+	// Prefer a Redis-backed chunked-upload session store so a resumable upload can continue
+	// against any replica, not just the one that received InitUpload; falls back to the
+	// in-memory default (set by cloudinary.NewClient) otherwise.
+	if cloudinaryClient != nil && redisClient != nil {
+		cloudinaryClient.SetUploadSessionStore(cloudinary.NewRedisUploadSessionStore(redisClient))
+	}
+
+	db := database.New().GetDB()
+
+	// Attach GORM query spans to whatever request span is already on a query's context, so a
+	// slow SELECT ... FOR UPDATE or stock-deduction UPDATE shows up under the request that
+	// triggered it.
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		fmt.Printf("Warning: failed to register tracing GORM plugin: %v. SQL spans will be disabled.\n", err)
+	}
+
+	// Wire the access-token revocation store so revoked/logged-out tokens are rejected
+	// immediately instead of waiting out their natural expiry. Prefer Redis when it's
+	// available since it shares revocations across every API instance with no DB round
+	// trip contention; fall back to Postgres (still shared across instances) otherwise.
+	if redisClient != nil {
+		auth.SetDenylistClient(redisClient)
+	} else {
+		auth.SetRevocationStore(auth.NewPostgresRevocationStore(db))
+	}
+
+	// Wire Redis-backed TOTP replay protection so a 2FA code can't be reused within its
+	// validity window across instances; falls back to a process-local guard otherwise.
+	if redisClient != nil {
+		auth.SetTOTPReplayClient(redisClient)
+	}
+
+	// Wire Redis-backed rate limiting so GlobalLimiter/AuthLimiter/APILimiter enforce their
+	// limits across every API replica instead of per-pod; falls back to the in-memory
+	// limiter otherwise.
+	if redisClient != nil {
+		ratelimit.SetRedisClient(redisClient)
+	}
+
+	// Register the password provider plus any configured OAuth2/OIDC providers
+	// (Google, GitHub, a local dev OIDC issuer) for /auth/{provider}/login and /callback.
+	auth.LoadProvidersFromEnv(db)
+
+	// Accept bearer tokens minted by external OIDC providers (Google, GitHub, Dex,
+	// Keycloak, Auth0, ...) alongside tundra's own, if OIDC_ISSUERS is configured.
+	auth.ConfigureOIDCVerifiersFromEnv()
+
+	// Wire the RBAC store so RequirePermission/RequireAnyRole can resolve a user's
+	// effective roles and permissions from the roles/permissions tables.
+	rbacStore := auth.NewRBACStore(db)
+	auth.SetRBACStore(rbacStore)
+
+	// Wire the domain-membership store so DomainMiddleware/DomainRoleMiddleware can
+	// resolve a user's role within a tenant domain from the domain_members table.
+	auth.SetDomainStore(auth.NewDomainStore(db))
+
+	// AppRoleStore backs POST /auth/approle/login and the admin rotate-secret endpoint -
+	// machine-to-machine auth for CI jobs, worker services, and partner integrations.
+	appRoleStore := auth.NewAppRoleStore(db)
+
+	// Prefer a Redis-backed refresh store when Redis is available, for the same reason as
+	// the revocation store above; fall back to the Postgres-backed store, which additionally
+	// detects reuse of an already-rotated token, otherwise.
+	var refreshStore auth.RefreshStore
+	if redisClient != nil {
+		refreshStore = auth.NewRedisRefreshTokenStore(redisClient)
+	} else {
+		refreshStore = auth.NewRefreshTokenStore(db)
+	}
+
+	// Wire the audit trail: always record to Postgres so GET /admin/audit can query it, and
+	// additionally append to a JSONL file when AUDIT_LOG_PATH is set, so events can be
+	// tailed into a SIEM without going through the API.
+	emitters := []audit.Emitter{audit.NewGORMEmitter(db)}
+	if logPath := os.Getenv("AUDIT_LOG_PATH"); logPath != "" {
+		jsonlEmitter, err := audit.NewJSONLEmitter(logPath)
+		if err != nil {
+			fmt.Printf("Warning: failed to open audit log %s: %v. File logging will be disabled.\n", logPath, err)
+		} else {
+			emitters = append(emitters, jsonlEmitter)
+		}
+	}
+	audit.SetEmitters(emitters...)
+
+	// Front the product listing cache with an in-process Ristretto L1, so hot pages skip
+	// the Redis round trip and JSON deserialization entirely. A nil redisClient degrades
+	// this to an L1-only cache, same as every other Redis-backed store above.
+	productCache, err := cache.NewLayered(redisClient, productCacheL1Bytes)
+	if err != nil {
+		fmt.Printf("Warning: product cache initialization failed: %v. Caching will be disabled.\n", err)
+		productCache = nil
+	}
+
+	// Tracks which cached product listing pages reference a given product/category/search
+	// term, so a single product's update or deletion invalidates only the pages it could
+	// have affected instead of the whole products:* namespace.
+	productTags := cache.NewTagIndex(redisClient)
+
+	// Per-route cache policies (TTL, on/off, vary-by params, stale-while-revalidate
+	// window), overridable via env vars so ops can retune or disable caching without a
+	// redeploy.
+	cachePolicies := cache.LoadPolicyRegistryFromEnv()
+
+	// Prefer a Redis-backed job queue so enqueued image jobs survive this instance restarting
+	// and are visible to every worker across every replica; fall back to an in-memory queue,
+	// the same dual-backend pattern as the refresh/revocation stores above.
+	var jobQueue jobs.Queue
+	if redisClient != nil {
+		jobQueue = jobs.NewRedisQueue(redisClient)
+	} else {
+		jobQueue = jobs.NewMemoryQueue()
+	}
+
+	// Prefer a real Stripe-backed payment provider when STRIPE_SECRET_KEY is configured;
+	// fall back to an in-memory fake, the same dual-backend pattern as the revocation/
+	// refresh/rate-limit stores above, so checkout/webhook still run end-to-end in
+	// development without a Stripe account.
+	var paymentProviderImpl payment.Provider
+	if stripeProvider, ok := payment.NewStripeProviderFromEnv(); ok {
+		paymentProviderImpl = stripeProvider
+	} else {
+		fmt.Printf("Warning: STRIPE_SECRET_KEY not set. Using a fake payment provider; checkout/webhook endpoints won't move any real money.\n")
+		paymentProviderImpl = payment.NewFakeProvider()
+	}
+
 	NewServer := &Server{
-		port:       port,
-		db:         database.New().GetDB(),
-		redis:      redisClient,
-		cloudinary: cloudinaryClient,
+		port:            port,
+		db:              db,
+		redis:           redisClient,
+		cloudinary:      cloudinaryClient,
+		refreshTokens:   refreshStore,
+		rbac:            rbacStore,
+		appRoles:        appRoleStore,
+		mailer:          mailer.NewFromEnv(),
+		productCache:    productCache,
+		productTags:     productTags,
+		cachePolicies:   cachePolicies,
+		jobQueue:        jobQueue,
+		paymentProvider: paymentProviderImpl,
+		orders:          orders.NewService(db),
 	}
 
+	// Reap expired idempotency_keys rows so a retried POST /orders eventually starts clean
+	// again instead of the table growing forever.
+	NewServer.startIdempotencyKeyCleanup(time.Hour)
+
+	// Reclaim stock reserved by orders abandoned before checkout completed, so an unpaid
+	// cart doesn't hold inventory hostage forever.
+	NewServer.startStockReservationCleanup(time.Minute)
+
+	// Relay outbox events (order.created, order.paid, order.cancelled, stock.low) written
+	// alongside order state changes to whichever EventPublisher NATS_URL/KAFKA_BROKERS
+	// configure, falling back to an in-process publisher otherwise.
+	outbox.NewRelay(db, outbox.NewPublisherFromEnv()).Start(5 * time.Second)
+
+	// Start the product-image worker pool so uploadProductImageHandler/createProductHandler
+	// can hand off Cloudinary upload/delete to a background job instead of blocking the
+	// request on it.
+	NewServer.startProductImageWorkers(productImageWorkerCount)
+
 	// Declare Server config
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", NewServer.port),
@@ -69,5 +291,5 @@ func NewServer() *http.Server {
 		WriteTimeout: 30 * time.Second,
 	}
 
-	return server
+	return NewServer, server
 }
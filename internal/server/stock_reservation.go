@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GRACENOBLE/tundra/internal/apierr"
+	"github.com/GRACENOBLE/tundra/internal/audit"
+	"github.com/GRACENOBLE/tundra/internal/database/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// startStockReservationCleanup periodically reclaims stock reserved by orders whose
+// reservation has expired while the order is still pending or awaiting_payment, restoring
+// the quantity to each product and marking the order expired. Runs for the lifetime of the
+// process; stop via the returned function (used by tests to avoid leaking goroutines).
+func (s *Server) startStockReservationCleanup(interval time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.releaseExpiredReservations()
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// releaseExpiredReservations finds every order still pending/awaiting_payment with an expired
+// stock reservation and releases each one in its own transaction, so one order failing to
+// release doesn't block the rest of the scan.
+func (s *Server) releaseExpiredReservations() {
+	var orderIDs []uuid.UUID
+	err := s.db.Model(&models.StockReservation{}).
+		Joins("JOIN orders ON orders.id = stock_reservations.order_id").
+		Where("stock_reservations.expires_at < ? AND orders.status IN ?", time.Now(), []models.OrderStatus{models.OrderStatusPending, models.OrderStatusAwaitingPayment}).
+		Distinct().
+		Pluck("stock_reservations.order_id", &orderIDs).Error
+	if err != nil {
+		fmt.Printf("Warning: failed to scan for expired stock reservations: %v\n", err)
+		return
+	}
+
+	for _, orderID := range orderIDs {
+		if err := s.releaseOrderReservation(orderID); err != nil {
+			fmt.Printf("Warning: failed to release expired stock reservation for order %s: %v\n", orderID, err)
+		}
+	}
+}
+
+// releaseOrderReservation restores the stock order reserved and marks it expired, all under
+// the same row-locking pattern as restoreOrderStock/cancelOrderHandler so it can't race a
+// concurrent checkout/cancel/webhook for the same order.
+func (s *Server) releaseOrderReservation(orderID uuid.UUID) error {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderID).First(&order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Re-check under the lock: the order may have moved on (checkout completing, a user
+	// cancelling it) between the scan above and acquiring this lock.
+	if !isValidOrderTransition(order.Status, models.OrderStatusExpired) {
+		tx.Rollback()
+		return nil
+	}
+
+	var reservations []models.StockReservation
+	if err := tx.Where("order_id = ?", orderID).Find(&reservations).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, reservation := range reservations {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", reservation.ProductID).First(&product).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		product.Stock += int64(reservation.Quantity)
+		if err := tx.Save(&product).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Where("order_id = ?", orderID).Delete(&models.StockReservation{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	order.Status = models.OrderStatusExpired
+	if err := tx.Save(&order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.invalidateProductCache()
+
+	audit.Emit(context.Background(), audit.Event{
+		Type:     audit.EventAdminAction,
+		Metadata: map[string]any{"action": "stock_reservation_expired", "order_id": orderID},
+	})
+
+	return nil
+}
+
+// @Summary Get an order's stock reservation
+// @Description Report how much longer an order's reserved stock is held before startStockReservationCleanup reclaims it, so a checkout UI can show a countdown.
+// @Tags Orders
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Success 200 {object} object{expires_at=string,seconds_remaining=int} "Reservation expiry"
+// @Failure 401 {object} object{error=object{code=string,message=string,request_id=string}} "User not authenticated"
+// @Failure 403 {object} object{error=object{code=string,message=string,request_id=string}} "Order belongs to a different user"
+// @Failure 404 {object} object{error=object{code=string,message=string,request_id=string}} "Order not found, or it has no active stock reservation"
+// @Router /orders/{id}/reservation [get]
+func (s *Server) getOrderReservationHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
+		return
+	}
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.Error(apierr.Unauthorized("authentication_required", "User not authenticated"))
+		return
+	}
+
+	var order models.Order
+	if err := s.db.Where("id = ?", c.Param("id")).First(&order).Error; err != nil {
+		c.Error(apierr.NotFound("order_not_found", "Order not found"))
+		return
+	}
+	if order.UserID != userUUID {
+		c.Error(apierr.Forbidden("cross_user_order", "Order belongs to a different user"))
+		return
+	}
+
+	var reservation models.StockReservation
+	if err := s.db.Where("order_id = ?", order.ID).First(&reservation).Error; err != nil {
+		c.Error(apierr.NotFound("reservation_not_found", "This order has no active stock reservation"))
+		return
+	}
+
+	remaining := time.Until(reservation.ExpiresAt)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"expires_at":        reservation.ExpiresAt,
+		"seconds_remaining": int(remaining.Seconds()),
+	})
+}
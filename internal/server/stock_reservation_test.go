@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tundra/internal/auth"
+	"tundra/internal/database/models"
+	"tundra/internal/orders"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStockReservationRouter(t *testing.T) (*Server, *gin.Engine) {
+	server, router := setupTestServer(t)
+	router.POST("/orders", auth.AuthMiddleware(), server.createOrderHandler)
+	router.GET("/orders/:id/reservation", auth.AuthMiddleware(), server.getOrderReservationHandler)
+	return server, router
+}
+
+func createTestOrderViaHandler(t *testing.T, server *Server, router *gin.Engine, token string, productID string, quantity int) models.Order {
+	body, err := json.Marshal([]map[string]any{{"productId": productID, "quantity": quantity}})
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusCreated, resp.Code)
+
+	var order models.Order
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &order))
+	return order
+}
+
+func TestCreateOrderHandler_CreatesStockReservation(t *testing.T) {
+	server, router := setupStockReservationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "reservationuser", "reservation@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	product := models.Product{Name: "Widget", Description: "A widget", Price: 10, Stock: 5, Category: "Misc"}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	order := createTestOrderViaHandler(t, server, router, token, product.ID.String(), 2)
+
+	var reservation models.StockReservation
+	require.NoError(t, server.db.Where("order_id = ?", order.ID).First(&reservation).Error)
+	assert.Equal(t, product.ID, reservation.ProductID)
+	assert.Equal(t, 2, reservation.Quantity)
+	assert.WithinDuration(t, time.Now().Add(orders.ReservationTTL), reservation.ExpiresAt, time.Minute)
+}
+
+func TestGetOrderReservationHandler_ReportsRemainingTime(t *testing.T) {
+	server, router := setupStockReservationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "reservationviewer", "reservationviewer@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	product := models.Product{Name: "Widget", Description: "A widget", Price: 10, Stock: 5, Category: "Misc"}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	order := createTestOrderViaHandler(t, server, router, token, product.ID.String(), 1)
+
+	req, _ := http.NewRequest("GET", "/orders/"+order.ID.String()+"/reservation", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var body struct {
+		SecondsRemaining int `json:"seconds_remaining"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Greater(t, body.SecondsRemaining, 0)
+	assert.LessOrEqual(t, body.SecondsRemaining, int(orders.ReservationTTL.Seconds()))
+}
+
+func TestReleaseExpiredReservations_RestoresStockAndExpiresOrder(t *testing.T) {
+	server, router := setupStockReservationRouter(t)
+	defer cleanupTestDatabase(t)
+
+	user := createTestUser(t, server.db, "reservationexpireuser", "reservationexpire@test.com", "Password123!")
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	require.NoError(t, err)
+
+	product := models.Product{Name: "Widget", Description: "A widget", Price: 10, Stock: 5, Category: "Misc"}
+	require.NoError(t, server.db.Create(&product).Error)
+
+	order := createTestOrderViaHandler(t, server, router, token, product.ID.String(), 3)
+
+	require.NoError(t, server.db.Model(&models.StockReservation{}).Where("order_id = ?", order.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	server.releaseExpiredReservations()
+
+	var updatedOrder models.Order
+	require.NoError(t, server.db.First(&updatedOrder, order.ID).Error)
+	assert.Equal(t, models.OrderStatusExpired, updatedOrder.Status)
+
+	var restoredProduct models.Product
+	require.NoError(t, server.db.First(&restoredProduct, product.ID).Error)
+	assert.Equal(t, int64(5), restoredProduct.Stock)
+
+	var remainingReservations int64
+	require.NoError(t, server.db.Model(&models.StockReservation{}).Where("order_id = ?", order.ID).Count(&remainingReservations).Error)
+	assert.Zero(t, remainingReservations)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/GRACENOBLE/tundra/internal/cloudinary"
+
+	"github.com/gin-gonic/gin"
+)
+
+// initUploadRequest is the POST /uploads body describing the file a client is about to
+// stream up in chunks.
+type initUploadRequest struct {
+	Filename  string `json:"filename" binding:"required"`
+	Folder    string `json:"folder"`
+	TotalSize int64  `json:"totalSize" binding:"required"`
+}
+
+// initUploadHandler starts a resumable, chunked upload session and returns its ID along
+// with a Location header the client uses for subsequent PATCH/PUT calls.
+func (s *Server) initUploadHandler(c *gin.Context) {
+	if s.cloudinary == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Image upload service is not available"})
+		return
+	}
+
+	var req initUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uploadID, err := s.cloudinary.InitUpload(req.Filename, req.Folder, req.TotalSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", "/uploads/"+uploadID)
+	c.JSON(http.StatusCreated, gin.H{"uploadID": uploadID})
+}
+
+// uploadChunkHandler streams one sequential byte range into an in-progress upload. The
+// client declares where the chunk starts via the Upload-Offset header (tus.io convention);
+// an offset that doesn't match what the server has already received is rejected with 416 so
+// the client can re-sync instead of silently corrupting the upload.
+func (s *Server) uploadChunkHandler(c *gin.Context) {
+	if s.cloudinary == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Image upload service is not available"})
+		return
+	}
+
+	uploadID := c.Param("id")
+	offset, err := parsePositiveInt(c.GetHeader("Upload-Offset"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Offset header is required and must be a non-negative integer"})
+		return
+	}
+
+	newOffset, err := s.cloudinary.UploadChunk(uploadID, int64(offset), c.Request.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, cloudinary.ErrOutOfOrderChunk):
+			c.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"error": err.Error()})
+		case errors.Is(err, cloudinary.ErrUploadSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Range", "bytes=0-"+strconv.FormatInt(newOffset-1, 10))
+	c.Header("Upload-Offset", strconv.FormatInt(newOffset, 10))
+	c.Status(http.StatusNoContent)
+}
+
+// finalizeUploadHandler commits a fully-received chunked upload to Cloudinary and returns
+// its secure URL, once all declared bytes have arrived.
+func (s *Server) finalizeUploadHandler(c *gin.Context) {
+	if s.cloudinary == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Image upload service is not available"})
+		return
+	}
+
+	uploadID := c.Param("id")
+	secureURL, err := s.cloudinary.FinalizeUpload(uploadID)
+	if err != nil {
+		if errors.Is(err, cloudinary.ErrUploadSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"secureURL": secureURL})
+}
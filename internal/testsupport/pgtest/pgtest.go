@@ -0,0 +1,184 @@
+// Package pgtest is the one place in this repo that knows how to stand up a disposable
+// Postgres for integration tests. Before this package existed, internal/database,
+// internal/server, and (soon) internal/auth each hard-coded their own
+// testcontainers.postgres.Run call with its own throwaway credentials, so a package that
+// wanted a real database for a test had to copy-paste the setup. StartPostgres is the
+// single call every one of those packages should use instead.
+package pgtest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// reuseContainerName is the fixed name the shared container is started (or reused) under.
+// Combined with TESTCONTAINERS_RYUK_DISABLED=true (so the reaper doesn't tear the container
+// down when the first package's test binary exits), every package's `go test` invocation
+// within a single `go test ./...` run resolves to the same container instead of paying for
+// a fresh one each time.
+const reuseContainerName = "tundra-pgtest-postgres"
+
+// Instance is a running Postgres a test can connect to.
+type Instance struct {
+	// DSN is a libpq connection string pointing at the container.
+	DSN string
+	// DB is a *gorm.DB already opened against DSN.
+	DB *gorm.DB
+}
+
+// Reset truncates every table in the public schema (except schema_migrations, so
+// WithMigrations doesn't need to re-run) and restarts their identity sequences, so
+// subtests within a shared Instance don't see each other's rows.
+func (i *Instance) Reset(t *testing.T) {
+	t.Helper()
+
+	var tables []string
+	if err := i.DB.Raw(`
+		SELECT tablename FROM pg_tables
+		WHERE schemaname = 'public' AND tablename != 'schema_migrations'
+	`).Scan(&tables).Error; err != nil {
+		t.Fatalf("pgtest: failed to list tables: %v", err)
+	}
+	if len(tables) == 0 {
+		return
+	}
+
+	quoted := make([]string, len(tables))
+	for i, name := range tables {
+		quoted[i] = `"` + name + `"`
+	}
+	stmt := fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE", strings.Join(quoted, ", "))
+	if err := i.DB.Exec(stmt).Error; err != nil {
+		t.Fatalf("pgtest: failed to truncate tables: %v", err)
+	}
+}
+
+// Option configures StartPostgres beyond handing back a bare container.
+type Option func(*config)
+
+type config struct {
+	migrations fs.FS
+}
+
+// WithMigrations applies every *.up.sql migration in migrationsFS (e.g.
+// os.DirFS("../../migrations")) before StartPostgres returns. Safe to pass on every call -
+// golang-migrate no-ops once a migration's version has already been applied, so repeated
+// callers sharing the same container just re-verify the schema is current.
+func WithMigrations(migrationsFS fs.FS) Option {
+	return func(c *config) { c.migrations = migrationsFS }
+}
+
+var (
+	mu       sync.Mutex
+	shared   *Instance
+	startErr error
+)
+
+// StartPostgres returns a running Postgres, starting one (or reusing the shared
+// reuseContainerName container left behind by an earlier package in this `go test ./...`
+// run) the first time it's called. Every later call, in this package or any other, gets
+// back the same *Instance - so the whole run only ever pays for one container. Call
+// Reset(t) between subtests that shouldn't see each other's data.
+func StartPostgres(t *testing.T, opts ...Option) *Instance {
+	t.Helper()
+
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	mu.Lock()
+	if shared == nil && startErr == nil {
+		shared, startErr = start()
+	}
+	inst, err := shared, startErr
+	mu.Unlock()
+
+	if err != nil {
+		t.Fatalf("pgtest: failed to start postgres: %v", err)
+	}
+
+	if cfg.migrations != nil {
+		if err := applyMigrations(inst.DSN, cfg.migrations); err != nil {
+			t.Fatalf("pgtest: failed to apply migrations: %v", err)
+		}
+	}
+
+	return inst
+}
+
+func start() (*Instance, error) {
+	ctx := context.Background()
+
+	container, err := tcpostgres.Run(ctx,
+		"postgres:latest",
+		tcpostgres.WithDatabase("pgtest"),
+		tcpostgres.WithUsername("pgtest"),
+		tcpostgres.WithPassword("pgtest"),
+		testcontainers.WithReuseByName(reuseContainerName),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(30*time.Second)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(gormpostgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Instance{DSN: dsn, DB: db}, nil
+}
+
+func applyMigrations(dsn string, migrationsFS fs.FS) error {
+	src, err := iofs.New(migrationsFS, ".")
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, dsn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// DSNComponents splits a libpq URL DSN into the pieces database.New's BLUEPRINT_DB_*
+// globals expect, for packages (like internal/database itself) that build their own
+// connection string instead of taking a DSN directly.
+func DSNComponents(dsn string) (host, port, user, password, database, sslmode string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", "", "", "", "", err
+	}
+	pw, _ := u.User.Password()
+	return u.Hostname(), u.Port(), u.User.Username(), pw, strings.TrimPrefix(u.Path, "/"), u.Query().Get("sslmode"), nil
+}
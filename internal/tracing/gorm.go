@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey is the key GormPlugin stores the in-flight span under in *gorm.DB's
+// InstanceSet, so the "after" callback of each operation can find and end the span the
+// matching "before" callback started.
+const gormSpanKey = "tracing:span"
+
+// GormPlugin is a gorm.Plugin that creates a child span for every SQL statement GORM
+// executes, tagged with db.statement, so a slow SELECT ... FOR UPDATE or stock-deduction
+// UPDATE shows up under the request span that triggered it.
+type GormPlugin struct{}
+
+// Name implements gorm.Plugin.
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize implements gorm.Plugin, registering before/after callbacks around every
+// operation GORM exposes a callback chain for.
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := []struct {
+		name string
+		reg  func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", db.Callback().Create().Before("gorm:create").Register},
+		{"query", db.Callback().Query().Before("gorm:query").Register},
+		{"update", db.Callback().Update().Before("gorm:update").Register},
+		{"delete", db.Callback().Delete().Before("gorm:delete").Register},
+		{"row", db.Callback().Row().Before("gorm:row").Register},
+		{"raw", db.Callback().Raw().Before("gorm:raw").Register},
+	}
+	for _, cb := range callbacks {
+		if err := cb.reg("tracing:before_"+cb.name, p.before); err != nil {
+			return err
+		}
+	}
+
+	afters := []struct {
+		name string
+		reg  func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", db.Callback().Create().After("gorm:create").Register},
+		{"query", db.Callback().Query().After("gorm:query").Register},
+		{"update", db.Callback().Update().After("gorm:update").Register},
+		{"delete", db.Callback().Delete().After("gorm:delete").Register},
+		{"row", db.Callback().Row().After("gorm:row").Register},
+		{"raw", db.Callback().Raw().After("gorm:raw").Register},
+	}
+	for _, cb := range afters {
+		if err := cb.reg("tracing:after_"+cb.name, p.after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p GormPlugin) before(tx *gorm.DB) {
+	ctx, span := Tracer().Start(tx.Statement.Context, "gorm."+tx.Statement.Table)
+	tx.Statement.Context = ctx
+	tx.InstanceSet(gormSpanKey, span)
+}
+
+func (p GormPlugin) after(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Error != nil {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
+
+// WithContext is a convenience for code that holds a *gorm.DB obtained without the
+// request's context (e.g. s.db rather than a pre-scoped tx) and wants its queries attached
+// to the caller's span.
+func WithContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span for every request and tags it with http.method,
+// http.route, http.status_code, and (once AuthMiddleware has run) user.id, so the span
+// becomes the root of the per-request trace everything downstream - GORM queries, a
+// Cloudinary upload - attaches its own child spans to.
+func Middleware() gin.HandlerFunc {
+	tracer := Tracer()
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		ctx, span := tracer.Start(c.Request.Context(), route, trace.WithAttributes(
+			semconv.HTTPMethod(c.Request.Method),
+			semconv.HTTPRoute(route),
+		))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if userID, exists := c.Get("userID"); exists {
+			if id, ok := userID.(interface{ String() string }); ok {
+				span.SetAttributes(attribute.String("user.id", id.String()))
+			}
+		}
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, "server error")
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
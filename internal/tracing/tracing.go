@@ -0,0 +1,73 @@
+// Package tracing wires up OpenTelemetry distributed tracing for the API: a single trace
+// per HTTP request that follows it through JWT validation, GORM queries, and Cloudinary
+// uploads, so operators can see exactly which step was slow.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultServiceName is used when OTEL_SERVICE_NAME isn't set.
+const defaultServiceName = "tundra"
+
+// tracerName identifies spans this package's own helpers (Middleware, GormPlugin) start;
+// other packages that start their own spans (e.g. cloudinary) use their own tracer name.
+const tracerName = "github.com/GRACENOBLE/tundra/internal/tracing"
+
+// Init configures the global TracerProvider from OTEL_EXPORTER_OTLP_ENDPOINT and
+// OTEL_SERVICE_NAME. If OTEL_EXPORTER_OTLP_ENDPOINT is unset, tracing is left disabled (the
+// global no-op provider stays in place) rather than failing startup. Call the returned
+// shutdown func during graceful shutdown to flush any spans still buffered for export.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		fmt.Println("Warning: OTEL_EXPORTER_OTLP_ENDPOINT not set. Tracing will be disabled.")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+// Tracer returns this package's tracer, for code that wants to start a span without
+// depending on the global otel.Tracer lookup directly.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}